@@ -17,10 +17,13 @@ make build
 cd ${RPM_SOURCE_DIR}
 mkdir -p %{buildroot}%{_sysconfdir}/systemd/system
 install -m 750 %{name}.service %{buildroot}%{_sysconfdir}/systemd/system
+mkdir -p %{buildroot}%{_bindir}
+install -m 755 otelcol-dev/snmpmib %{buildroot}%{_bindir}/snmpmib
 
 
 %files
 %defattr (-,root,root)
 %config /containers/%{name}/%{name}.conf
 %{_sysconfdir}/systemd/system/%{name}.service
+%{_bindir}/snmpmib
 
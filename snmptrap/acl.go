@@ -0,0 +1,98 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package snmptrapreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver"
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// sourceACL decides which addresses traps are accepted from
+type sourceACL struct {
+	allow []netip.Prefix
+	deny  []netip.Prefix
+}
+
+// newSourceACL creates the source ACL of a receiver. It returns nil when neither
+// list is configured, in which case traps are accepted from any address.
+func newSourceACL(cfg *Config) (*sourceACL, error) {
+	if len(cfg.Allow) == 0 && len(cfg.Deny) == 0 {
+		return nil, nil
+	}
+
+	acl := &sourceACL{}
+	for _, source := range cfg.Allow {
+		prefix, err := parsePrefix(source)
+		if err != nil {
+			return nil, err
+		}
+		acl.allow = append(acl.allow, prefix)
+	}
+	for _, source := range cfg.Deny {
+		prefix, err := parsePrefix(source)
+		if err != nil {
+			return nil, err
+		}
+		acl.deny = append(acl.deny, prefix)
+	}
+
+	return acl, nil
+}
+
+// permits reports whether traps are accepted from ip. An address in the deny list is
+// never accepted, and when there is an allow list the address must be in it.
+func (acl *sourceACL) permits(ip net.IP) bool {
+	if acl == nil {
+		return true
+	}
+
+	addr, ok := netip.AddrFromSlice(ip)
+	if !ok {
+		return false
+	}
+	addr = addr.Unmap()
+	if prefixesContain(acl.deny, addr) {
+		return false
+	}
+	return len(acl.allow) == 0 || prefixesContain(acl.allow, addr)
+}
+
+// permitsAgentAddress reports whether the agent-addr of a v1 trap is accepted. Agents
+// often leave it unset, in which case only the packet's source address is checked.
+func (acl *sourceACL) permitsAgentAddress(packet *gosnmp.SnmpPacket) bool {
+	if packet.Version != gosnmp.Version1 {
+		return true
+	}
+	ip := net.ParseIP(packet.AgentAddress)
+	if ip == nil || ip.IsUnspecified() {
+		return true
+	}
+	return acl.permits(ip)
+}
+
+// parsePrefix parses a CIDR block, or a single IP address as a block holding only that address
+func parsePrefix(s string) (netip.Prefix, error) {
+	if prefix, err := netip.ParsePrefix(s); err == nil {
+		return prefix.Masked(), nil
+	}
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf(errMsgInvalidSource, s)
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// prefixesContain reports whether any of prefixes contains addr
+func prefixesContain(prefixes []netip.Prefix, addr netip.Addr) bool {
+	for _, prefix := range prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,158 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package snmptrapreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver"
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+)
+
+func TestSourceACLPermits(t *testing.T) {
+	type testCase struct {
+		name     string
+		allow    []string
+		deny     []string
+		source   string
+		expected bool
+	}
+
+	testCases := []testCase{
+		{name: "NoLists", source: "192.0.2.1", expected: true},
+		{name: "Allowed", allow: []string{"192.0.2.0/24"}, source: "192.0.2.1", expected: true},
+		{name: "NotAllowed", allow: []string{"192.0.2.0/24"}, source: "198.51.100.1", expected: false},
+		{name: "Denied", deny: []string{"192.0.2.1"}, source: "192.0.2.1", expected: false},
+		{name: "NotDenied", deny: []string{"192.0.2.1"}, source: "192.0.2.2", expected: true},
+		{name: "DenyWinsOverAllow", allow: []string{"192.0.2.0/24"}, deny: []string{"192.0.2.128/25"}, source: "192.0.2.200", expected: false},
+		{name: "MappedSource", allow: []string{"192.0.2.0/24"}, source: "::ffff:192.0.2.1", expected: true},
+		{name: "IPv6", allow: []string{"2001:db8::/32"}, source: "2001:db8::1", expected: true},
+		{name: "IPv6NotAllowed", allow: []string{"192.0.2.0/24"}, source: "2001:db8::1", expected: false},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			acl, err := newSourceACL(&Config{Allow: test.allow, Deny: test.deny})
+			require.NoError(t, err)
+			require.Equal(t, test.expected, acl.permits(net.ParseIP(test.source)))
+		})
+	}
+}
+
+func TestSourceACLPermitsAgentAddress(t *testing.T) {
+	acl, err := newSourceACL(&Config{Allow: []string{"192.0.2.0/24"}})
+	require.NoError(t, err)
+
+	v1 := func(agentAddress string) *gosnmp.SnmpPacket {
+		return &gosnmp.SnmpPacket{Version: gosnmp.Version1, SnmpTrap: gosnmp.SnmpTrap{AgentAddress: agentAddress}}
+	}
+	require.True(t, acl.permitsAgentAddress(v1("192.0.2.1")))
+	require.False(t, acl.permitsAgentAddress(v1("198.51.100.1")))
+	// Unset agent addresses are left to the check of the source address
+	require.True(t, acl.permitsAgentAddress(v1("0.0.0.0")))
+	require.True(t, acl.permitsAgentAddress(v1("")))
+	require.True(t, acl.permitsAgentAddress(&gosnmp.SnmpPacket{Version: gosnmp.Version2c}))
+}
+
+func TestParsePrefix(t *testing.T) {
+	prefix, err := parsePrefix("10.1.2.3/8")
+	require.NoError(t, err)
+	require.Equal(t, netip.MustParsePrefix("10.0.0.0/8"), prefix)
+
+	prefix, err = parsePrefix("2001:db8::1")
+	require.NoError(t, err)
+	require.Equal(t, netip.MustParsePrefix("2001:db8::1/128"), prefix)
+
+	prefix, err = parsePrefix("::ffff:192.0.2.1")
+	require.NoError(t, err)
+	require.Equal(t, netip.MustParsePrefix("192.0.2.1/32"), prefix)
+
+	_, err = parsePrefix("localhost")
+	require.EqualError(t, err, "invalid source 'localhost': must be an IP address or a CIDR block")
+}
+
+func TestReceiveTrapSourceACL(t *testing.T) {
+	type testCase struct {
+		name             string
+		allow            []string
+		deny             []string
+		agentAddress     string
+		expectedLogged   bool
+		expectedDenied   int64
+		expectedRejected int64
+	}
+
+	testCases := []testCase{
+		{name: "Allowed", allow: []string{"127.0.0.0/8"}, agentAddress: "127.0.0.2", expectedLogged: true},
+		{name: "SourceDenied", deny: []string{"127.0.0.1"}, agentAddress: "127.0.0.2", expectedDenied: 1},
+		{name: "SourceNotAllowed", allow: []string{"192.0.2.0/24"}, agentAddress: "192.0.2.1", expectedDenied: 1},
+		{name: "AgentAddressDenied", deny: []string{"192.0.2.0/24"}, agentAddress: "192.0.2.1", expectedRejected: 1},
+		{name: "AgentAddressNotAllowed", allow: []string{"127.0.0.0/8"}, agentAddress: "192.0.2.1", expectedRejected: 1},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.ListenAddress = "udp://127.0.0.1:0"
+			cfg.Version = "v1"
+			cfg.Allow = test.allow
+			cfg.Deny = test.deny
+			require.NoError(t, cfg.Validate())
+
+			reader := sdkmetric.NewManualReader()
+			settings := receivertest.NewNopCreateSettings()
+			settings.MeterProvider = sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+			sink := new(consumertest.LogsSink)
+			rcvr, err := newSnmptrapReceiver(settings, cfg, sink)
+			require.NoError(t, err)
+			require.NoError(t, rcvr.Start(context.Background(), componenttest.NewNopHost()))
+			defer func() {
+				require.NoError(t, rcvr.Shutdown(context.Background()))
+			}()
+
+			_, port := splitAddr(rcvr.listeners[0].localAddr())
+			client := &gosnmp.GoSNMP{
+				Target:    "127.0.0.1",
+				Port:      uint16(port),
+				Transport: "udp",
+				Community: "public",
+				Version:   gosnmp.Version1,
+				Timeout:   time.Second,
+				MaxOids:   gosnmp.MaxOids,
+			}
+			require.NoError(t, client.Connect())
+			defer client.Conn.Close()
+
+			_, err = client.SendTrap(gosnmp.SnmpTrap{
+				Variables:    []gosnmp.SnmpPDU{{Name: ".1.3.6.1.2.1.1.5.0", Type: gosnmp.OctetString, Value: []byte("host")}},
+				Enterprise:   ".1.3.6.1.4.1.8072",
+				AgentAddress: test.agentAddress,
+				GenericTrap:  6,
+				SpecificTrap: 1,
+			})
+			require.NoError(t, err)
+
+			if test.expectedLogged {
+				require.Eventually(t, func() bool {
+					return sink.LogRecordCount() == 1
+				}, 5*time.Second, 10*time.Millisecond)
+				return
+			}
+			require.Eventually(t, func() bool {
+				return counterValue(t, reader, metricDeniedMessages, "") == test.expectedDenied &&
+					counterValue(t, reader, metricRejectedNotifications, reasonAgentAddress) == test.expectedRejected
+			}, 5*time.Second, 10*time.Millisecond)
+			require.Zero(t, sink.LogRecordCount())
+		})
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package snmptrapreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver"
+
+import (
+	"errors"
+	"net"
+	"net/netip"
+
+	"github.com/gosnmp/gosnmp"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+// Addresses the agent which originated a notification is resolved from
+const (
+	// agentAddressAgentAddr is the agent-addr field of v1 traps
+	agentAddressAgentAddr = "agent_addr"
+	// agentAddressSnmpTrapAddress is the snmpTrapAddress.0 varbind, which proxies add to the
+	// notifications they forward
+	agentAddressSnmpTrapAddress = "snmp_trap_address"
+	// agentAddressSource is the address of the sender of the packet
+	agentAddressSource = "source"
+)
+
+const (
+	// attributeSNMPv1AgentAddress holds the agent-addr of a v1 trap, unless it is unspecified
+	attributeSNMPv1AgentAddress = "snmp.v1.agent_address"
+	// attributeSNMPTrapAddress holds the snmpTrapAddress.0 varbind of a notification
+	attributeSNMPTrapAddress = "snmp.trap_address"
+	// attributeSNMPSourceAddress holds the address of the sender of the packet
+	attributeSNMPSourceAddress = "snmp.source_address"
+	// attributeSNMPAgentAddress is the resource attribute of the agent which originated a
+	// notification, so that logs can be grouped by device rather than by relay
+	attributeSNMPAgentAddress = "snmp.agent.address"
+	// attributeSNMPAgentAddressFrom tells which of the addresses the agent was resolved from
+	attributeSNMPAgentAddressFrom = "snmp.agent.address.from"
+)
+
+var (
+	errMsgBadAgentAddressOrder = `invalid address '%s': must be either agent_addr, snmp_trap_address, or source`
+
+	errDuplicateAgentAddressOrder = errors.New("address is given more than once")
+)
+
+// defaultAgentAddressOrder is the order the agent is resolved in when agent_address_order is empty
+var defaultAgentAddressOrder = []string{agentAddressAgentAddr, agentAddressSnmpTrapAddress, agentAddressSource}
+
+// agentAddresses are the addresses the agent which originated a notification may be resolved
+// from. Those a notification doesn't have, and unspecified ones such as the 0.0.0.0 agent-addr of
+// traps sent before the agent had an address, are invalid.
+type agentAddresses struct {
+	agentAddr       netip.Addr
+	snmpTrapAddress netip.Addr
+	source          netip.Addr
+}
+
+// newAgentAddresses collects the addresses of a notification as received, before it is normalized
+func newAgentAddresses(packet *gosnmp.SnmpPacket, peer net.Addr) agentAddresses {
+	var addresses agentAddresses
+	if packet.Version == gosnmp.Version1 {
+		addresses.agentAddr = parseAgentAddress(packet.AgentAddress)
+	}
+	for _, variable := range packet.Variables {
+		if variable.Name == oidSnmpTrapAddress {
+			text, _ := variable.Value.(string)
+			addresses.snmpTrapAddress = parseAgentAddress(text)
+			break
+		}
+	}
+	if ip, _ := splitAddr(peer); ip != nil {
+		addresses.source, _ = netip.AddrFromSlice(ip)
+		addresses.source = addresses.source.Unmap()
+	}
+	return addresses
+}
+
+// parseAgentAddress parses an address, which is invalid when it is unspecified
+func parseAgentAddress(text string) netip.Addr {
+	addr, err := netip.ParseAddr(text)
+	if err != nil || addr.IsUnspecified() {
+		return netip.Addr{}
+	}
+	return addr.Unmap()
+}
+
+// resolve returns the first valid address in the given order, along with the name of where it
+// comes from. The address is invalid when there is none.
+func (addresses agentAddresses) resolve(order []string) (netip.Addr, string) {
+	if len(order) == 0 {
+		order = defaultAgentAddressOrder
+	}
+	for _, from := range order {
+		var addr netip.Addr
+		switch from {
+		case agentAddressAgentAddr:
+			addr = addresses.agentAddr
+		case agentAddressSnmpTrapAddress:
+			addr = addresses.snmpTrapAddress
+		case agentAddressSource:
+			addr = addresses.source
+		}
+		if addr.IsValid() {
+			return addr, from
+		}
+	}
+	return netip.Addr{}, ""
+}
+
+// putAgentAddresses records the addresses a notification has, whether or not it was normalized,
+// and the agent they resolve to as a resource attribute
+func putAgentAddresses(resource pcommon.Map, attributes pcommon.Map, addresses agentAddresses, order []string) {
+	if addresses.agentAddr.IsValid() {
+		attributes.PutStr(attributeSNMPv1AgentAddress, addresses.agentAddr.String())
+	}
+	if addresses.snmpTrapAddress.IsValid() {
+		attributes.PutStr(attributeSNMPTrapAddress, addresses.snmpTrapAddress.String())
+	}
+	if addresses.source.IsValid() {
+		attributes.PutStr(attributeSNMPSourceAddress, addresses.source.String())
+	}
+	if agent, from := addresses.resolve(order); agent.IsValid() {
+		resource.PutStr(attributeSNMPAgentAddress, agent.String())
+		attributes.PutStr(attributeSNMPAgentAddressFrom, from)
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package snmptrapreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver"
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+)
+
+func TestResolveAgentAddress(t *testing.T) {
+	peer := &net.UDPAddr{IP: net.ParseIP("::ffff:198.51.100.1"), Port: 1620}
+	relayed := &gosnmp.SnmpPacket{
+		Version:  gosnmp.Version1,
+		SnmpTrap: gosnmp.SnmpTrap{AgentAddress: "192.0.2.10"},
+		Variables: []gosnmp.SnmpPDU{
+			{Name: oidSnmpTrapAddress, Type: gosnmp.IPAddress, Value: "10.1.2.3"},
+		},
+	}
+
+	type testCase struct {
+		name         string
+		packet       *gosnmp.SnmpPacket
+		order        []string
+		expected     string
+		expectedFrom string
+		// expectedAddresses are the addresses recorded as attributes
+		expectedAddresses map[string]any
+	}
+
+	testCases := []testCase{
+		{
+			name:         "DefaultOrder",
+			packet:       relayed,
+			expected:     "192.0.2.10",
+			expectedFrom: agentAddressAgentAddr,
+			expectedAddresses: map[string]any{
+				attributeSNMPv1AgentAddress: "192.0.2.10",
+				attributeSNMPTrapAddress:    "10.1.2.3",
+				attributeSNMPSourceAddress:  "198.51.100.1",
+			},
+		},
+		{name: "SnmpTrapAddressFirst", packet: relayed, order: []string{agentAddressSnmpTrapAddress, agentAddressAgentAddr}, expected: "10.1.2.3", expectedFrom: agentAddressSnmpTrapAddress},
+		{name: "SourceFirst", packet: relayed, order: []string{agentAddressSource}, expected: "198.51.100.1", expectedFrom: agentAddressSource},
+		{
+			name:         "UnsetAgentAddr",
+			packet:       &gosnmp.SnmpPacket{Version: gosnmp.Version1, SnmpTrap: gosnmp.SnmpTrap{AgentAddress: "0.0.0.0"}},
+			expected:     "198.51.100.1",
+			expectedFrom: agentAddressSource,
+			expectedAddresses: map[string]any{
+				attributeSNMPSourceAddress: "198.51.100.1",
+			},
+		},
+		{
+			name: "SnmpTrapAddress",
+			packet: &gosnmp.SnmpPacket{Version: gosnmp.Version2c, Variables: []gosnmp.SnmpPDU{
+				{Name: oidSnmpTrapAddress, Type: gosnmp.IPAddress, Value: "10.1.2.3"},
+			}},
+			expected:     "10.1.2.3",
+			expectedFrom: agentAddressSnmpTrapAddress,
+		},
+		{
+			name:         "V2cAgentAddressIgnored",
+			packet:       &gosnmp.SnmpPacket{Version: gosnmp.Version2c, SnmpTrap: gosnmp.SnmpTrap{AgentAddress: "192.0.2.10"}},
+			expected:     "198.51.100.1",
+			expectedFrom: agentAddressSource,
+		},
+		{
+			name:   "NoneInOrder",
+			packet: &gosnmp.SnmpPacket{Version: gosnmp.Version2c},
+			order:  []string{agentAddressAgentAddr, agentAddressSnmpTrapAddress},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			addresses := newAgentAddresses(test.packet, peer)
+			agent, from := addresses.resolve(test.order)
+			require.Equal(t, test.expectedFrom, from)
+
+			resource, attributes := pcommon.NewMap(), pcommon.NewMap()
+			putAgentAddresses(resource, attributes, addresses, test.order)
+			if test.expectedAddresses != nil {
+				raw := attributes.AsRaw()
+				delete(raw, attributeSNMPAgentAddressFrom)
+				require.Equal(t, test.expectedAddresses, raw)
+			}
+			value, ok := resource.Get(attributeSNMPAgentAddress)
+			if test.expected == "" {
+				require.False(t, agent.IsValid())
+				require.False(t, ok)
+				return
+			}
+			require.Equal(t, test.expected, agent.String())
+			require.True(t, ok)
+			require.Equal(t, test.expected, value.Str())
+			value, ok = attributes.Get(attributeSNMPAgentAddressFrom)
+			require.True(t, ok)
+			require.Equal(t, test.expectedFrom, value.Str())
+		})
+	}
+}
+
+func TestReceiveRelayedTrap(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.ListenAddress = "udp://127.0.0.1:0"
+	cfg.AgentAddressOrder = []string{agentAddressSnmpTrapAddress, agentAddressSource}
+	// Severity rules and message templates apply to the agent rather than to the relay
+	cfg.SeverityRules = []SeverityRuleConfig{{Sources: []string{"192.0.2.10"}, Severity: "ERROR"}}
+	cfg.MessageTemplates = []MessageTemplateConfig{{TrapOID: "1.3.6.1.6.3.1.1.5.3", Template: "linkDown on {{ .Agent }} via {{ .Source }}"}}
+	require.NoError(t, cfg.Validate())
+
+	sink := new(consumertest.LogsSink)
+	rcvr, err := newSnmptrapReceiver(receivertest.NewNopCreateSettings(), cfg, sink)
+	require.NoError(t, err)
+	require.NoError(t, rcvr.Start(context.Background(), componenttest.NewNopHost()))
+	defer func() {
+		require.NoError(t, rcvr.Shutdown(context.Background()))
+	}()
+
+	_, port := splitAddr(rcvr.listeners[0].localAddr())
+	client := &gosnmp.GoSNMP{
+		Target:    "127.0.0.1",
+		Port:      uint16(port),
+		Transport: "udp",
+		Community: "public",
+		Version:   gosnmp.Version2c,
+		Timeout:   time.Second,
+		MaxOids:   gosnmp.MaxOids,
+	}
+	require.NoError(t, client.Connect())
+	defer client.Conn.Close()
+
+	_, err = client.SendTrap(gosnmp.SnmpTrap{Variables: []gosnmp.SnmpPDU{
+		{Name: oidSnmpTrapOID, Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.6.3.1.1.5.3"},
+		{Name: oidSnmpTrapAddress, Type: gosnmp.IPAddress, Value: "192.0.2.10"},
+	}})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return sink.LogRecordCount() == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	resourceLogs := sink.AllLogs()[0].ResourceLogs().At(0)
+	agent, ok := resourceLogs.Resource().Attributes().Get(attributeSNMPAgentAddress)
+	require.True(t, ok)
+	require.Equal(t, "192.0.2.10", agent.Str())
+
+	logRecord := resourceLogs.ScopeLogs().At(0).LogRecords().At(0)
+	requireAttribute(t, logRecord, attributeSNMPTrapAddress, "192.0.2.10")
+	requireAttribute(t, logRecord, attributeSNMPAgentAddressFrom, agentAddressSnmpTrapAddress)
+	requireAttribute(t, logRecord, attributeNetSockPeerAddr, "127.0.0.1")
+	requireAttribute(t, logRecord, attributeSNMPSourceAddress, "127.0.0.1")
+	require.Equal(t, plog.SeverityNumberError, logRecord.SeverityNumber())
+	require.Equal(t, "linkDown on 192.0.2.10 via 127.0.0.1", logRecord.Body().Str())
+}
@@ -12,6 +12,8 @@ import (
 	"github.com/gosnmp/gosnmp"
 	"go.opentelemetry.io/collector/receiver/scrapererror"
 	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver/internal/mib"
 )
 
 type oidDataType byte
@@ -338,11 +340,22 @@ func (c *snmpClient) convertSnmpPDUToSnmpData(pdu gosnmp.SnmpPDU) SNMPData {
 		return clientSNMPData
 
 	// String types
-	case gosnmp.IPAddress, gosnmp.ObjectIdentifier, gosnmp.OctetString:
+	case gosnmp.IPAddress, gosnmp.ObjectIdentifier:
 		clientSNMPData.valueType = stringVal
 		clientSNMPData.value = toString(pdu.Value)
 		return clientSNMPData
 
+	// Octet strings may be binary, such as MAC addresses, which are rendered as hexadecimal
+	// rather than mangled into an invalid string
+	case gosnmp.OctetString:
+		clientSNMPData.valueType = stringVal
+		if value, ok := pdu.Value.([]byte); ok {
+			clientSNMPData.value = mib.FormatOctets(value)
+		} else {
+			clientSNMPData.value = toString(pdu.Value)
+		}
+		return clientSNMPData
+
 	// Float types
 	case gosnmp.OpaqueFloat, gosnmp.OpaqueDouble:
 		value, err := c.toFloat64(pdu.Name, pdu.Value)
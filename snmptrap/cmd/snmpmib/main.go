@@ -0,0 +1,335 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Command snmpmib works with the MIB modules used by the SNMP trap receiver, with the same
+// parser as the receiver, so that its answers match what the receiver emits.
+//
+//	snmpmib compile -o FILE [-strict] [-standard=false] DIR...
+//	snmpmib translate [-m DIR]... [-c FILE] [-standard=false] OID|NAME...
+//	snmpmib notification [-m DIR]... [-c FILE] [-standard=false] OID|NAME
+//	snmpmib lint [-standard=false] DIR...
+//
+// compile parses the MIB modules of the directories and writes the compiled MIB that the
+// receiver loads with its compiled_mib setting. The standard MIB set embedded in the receiver is
+// compiled in after the directories, unless -standard=false is given. Problems with the modules
+// are reported with their file and line, and only prevent the file from being written with -strict.
+//
+// translate translates OIDs into names such as IF-MIB::ifDescr.12, and names into OIDs.
+// notification prints the definition of a NOTIFICATION-TYPE or TRAP-TYPE with its objects.
+// Both load the modules of the -m directories on top of the standard MIB set like the
+// receiver's mib_paths, or the compiled MIB given with -c like its compiled_mib.
+//
+// lint loads the modules of the directories and reports their problems with their file and
+// line, such as syntax errors, missing imports and undefined objects. It fails when there are any.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver/internal/mib"
+)
+
+const usage = `usage: snmpmib <command> [arguments]
+
+commands:
+  compile -o FILE [-strict] [-standard=false] DIR...             compile the MIB modules of directories
+  translate [-m DIR]... [-c FILE] [-standard=false] OID|NAME...  translate OIDs into names and back
+  notification [-m DIR]... [-c FILE] [-standard=false] OID|NAME  print the definition of a notification
+  lint [-standard=false] DIR...                                  report the problems of the MIB modules of directories
+`
+
+// errReported is returned by commands which already reported why they failed
+var errReported = errors.New("failed")
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run runs a command and returns the exit status of the program
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+
+	var err error
+	switch args[0] {
+	case "compile":
+		err = compile(args[1:], stderr)
+	case "translate":
+		err = translate(args[1:], stdout, stderr)
+	case "notification":
+		err = notification(args[1:], stdout, stderr)
+	case "lint":
+		err = lint(args[1:], stdout, stderr)
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(stdout, usage)
+		return 0
+	default:
+		fmt.Fprintf(stderr, "snmpmib: unknown command %q\n%s", args[0], usage)
+		return 2
+	}
+
+	if errors.Is(err, flag.ErrHelp) {
+		return 2
+	}
+	if errors.Is(err, errReported) {
+		return 1
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "snmpmib %s: %v\n", args[0], err)
+		return 1
+	}
+	return 0
+}
+
+// compile implements the compile command
+func compile(args []string, stderr io.Writer) error {
+	flags := flag.NewFlagSet("compile", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	output := flags.String("o", "", "`file` to write the compiled MIB to")
+	strict := flags.Bool("strict", false, "fail when any module can't be loaded")
+	standard := flags.Bool("standard", true, "compile the standard MIB set in after the directories")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *output == "" || flags.NArg() == 0 {
+		flags.Usage()
+		return flag.ErrHelp
+	}
+
+	mibs, err := loadDirs(flags.Args(), *standard)
+	if n := reportErrors(stderr, err); n > 0 && *strict {
+		return fmt.Errorf("%d problems with the MIB modules", n)
+	}
+
+	f, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	if err = mibs.Encode(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// translate implements the translate command. Each argument is translated on its own line, and
+// those which can't be translated are reported without stopping the others.
+func translate(args []string, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("translate", flag.ContinueOnError)
+	source := addSourceFlags(flags, stderr)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return flag.ErrHelp
+	}
+	mibs, err := source.load(stderr)
+	if err != nil {
+		return err
+	}
+
+	failed := false
+	for _, arg := range flags.Args() {
+		if isOID(arg) {
+			if name := mibs.Name(arg); name != "" {
+				fmt.Fprintln(stdout, name)
+				continue
+			}
+			fmt.Fprintf(stderr, "%s is not under any known object\n", arg)
+			failed = true
+			continue
+		}
+		oid, err := mibs.OID(arg)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			failed = true
+			continue
+		}
+		fmt.Fprintln(stdout, oid)
+	}
+	if failed {
+		return errReported
+	}
+	return nil
+}
+
+// notification implements the notification command
+func notification(args []string, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("notification", flag.ContinueOnError)
+	source := addSourceFlags(flags, stderr)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return flag.ErrHelp
+	}
+	mibs, err := source.load(stderr)
+	if err != nil {
+		return err
+	}
+
+	oid := flags.Arg(0)
+	if !isOID(oid) {
+		if oid, err = mibs.OID(oid); err != nil {
+			return err
+		}
+	}
+	node := mibs.Notification(oid)
+	if node == nil {
+		return fmt.Errorf("%s is not a notification", flags.Arg(0))
+	}
+
+	fmt.Fprintf(stdout, "%s::%s\n", node.Module, node.Name)
+	fmt.Fprintf(stdout, "  OID:     %s\n", node.OID)
+	fmt.Fprintf(stdout, "  Kind:    %s\n", node.Kind)
+	if node.Status != "" {
+		fmt.Fprintf(stdout, "  Status:  %s\n", node.Status)
+	}
+	if len(node.Objects) > 0 {
+		fmt.Fprintln(stdout, "  Objects:")
+		for _, object := range node.Objects {
+			fmt.Fprintf(stdout, "    %s::%s (%s)", object.Module, object.Name, object.OID)
+			if object.Type != nil {
+				fmt.Fprintf(stdout, " %s", describeType(object.Type))
+			}
+			fmt.Fprintln(stdout)
+		}
+	}
+	if node.Description != "" {
+		fmt.Fprintln(stdout, "  Description:")
+		for _, line := range strings.Split(node.Description, "\n") {
+			fmt.Fprintf(stdout, "    %s\n", strings.TrimSpace(line))
+		}
+	}
+	return nil
+}
+
+// lint implements the lint command
+func lint(args []string, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("lint", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	standard := flags.Bool("standard", true, "resolve the imports of the modules with the standard MIB set too")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return flag.ErrHelp
+	}
+
+	_, err := loadDirs(flags.Args(), *standard)
+	if n := reportErrors(stderr, err); n > 0 {
+		return fmt.Errorf("%d problems with the MIB modules", n)
+	}
+	fmt.Fprintln(stdout, "no problems found")
+	return nil
+}
+
+// sourceFlags tell the commands which query the MIBs where to load them from, like the
+// settings of the receiver
+type sourceFlags struct {
+	dirs     dirList
+	compiled string
+	standard bool
+}
+
+func addSourceFlags(flags *flag.FlagSet, stderr io.Writer) *sourceFlags {
+	flags.SetOutput(stderr)
+	source := &sourceFlags{}
+	flags.Var(&source.dirs, "m", "`directory` of MIB modules like mib_paths, which may be repeated")
+	flags.StringVar(&source.compiled, "c", "", "compiled MIB `file` to load instead of modules, like compiled_mib")
+	flags.BoolVar(&source.standard, "standard", true, "load the standard MIB set after the directories")
+	return source
+}
+
+// load loads the MIBs. Problems with the modules are only warned about, since the receiver
+// still uses the rest of the modules.
+func (source *sourceFlags) load(stderr io.Writer) (*mib.MIB, error) {
+	if source.compiled != "" {
+		if len(source.dirs) > 0 {
+			return nil, errors.New("-c and -m are mutually exclusive")
+		}
+		return mib.LoadCompiled(source.compiled)
+	}
+	mibs, err := loadDirs(source.dirs, source.standard)
+	if n := len(unjoin(err)); n > 0 {
+		fmt.Fprintf(stderr, "warning: %d problems with the MIB modules, see snmpmib lint\n", n)
+	}
+	return mibs, nil
+}
+
+// dirList is a flag which may be repeated
+type dirList []string
+
+func (dirs *dirList) String() string {
+	return strings.Join(*dirs, ",")
+}
+
+func (dirs *dirList) Set(dir string) error {
+	*dirs = append(*dirs, dir)
+	return nil
+}
+
+// loadDirs loads the modules of directories, and the standard MIB set after them when asked to
+func loadDirs(dirs []string, standard bool) (*mib.MIB, error) {
+	if standard {
+		return mib.LoadStandard(dirs...)
+	}
+	return mib.Load(dirs...)
+}
+
+// reportErrors writes each of the errors joined by the MIB loader on its own line, and returns
+// how many there were
+func reportErrors(w io.Writer, err error) int {
+	errs := unjoin(err)
+	for _, err := range errs {
+		fmt.Fprintln(w, err)
+	}
+	return len(errs)
+}
+
+// unjoin returns the errors joined by the MIB loader
+func unjoin(err error) []error {
+	if err == nil {
+		return nil
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		return joined.Unwrap()
+	}
+	return []error{err}
+}
+
+// isOID tells whether an argument is a dotted OID rather than a name
+func isOID(arg string) bool {
+	arg = strings.TrimPrefix(arg, ".")
+	return arg != "" && arg[0] >= '0' && arg[0] <= '9'
+}
+
+// describeType describes the syntax of an object, such as InterfaceIndex (INTEGER) or
+// INTEGER { up(1), down(2), testing(3) }
+func describeType(t *mib.Type) string {
+	description := t.Base
+	if t.Name != "" {
+		description = fmt.Sprintf("%s (%s)", t.Name, t.Base)
+	}
+	if len(t.Named) > 0 {
+		named := make([]string, len(t.Named))
+		for i, n := range t.Named {
+			named[i] = fmt.Sprintf("%s(%d)", n.Name, n.Number)
+		}
+		description += " { " + strings.Join(named, ", ") + " }"
+	}
+	if t.Hint != "" {
+		description += fmt.Sprintf(" DISPLAY-HINT %q", t.Hint)
+	}
+	return description
+}
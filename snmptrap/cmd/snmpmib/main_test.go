@@ -0,0 +1,144 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver/internal/mib"
+)
+
+var (
+	testMIBPath    = filepath.Join("..", "..", "internal", "mib", "testdata", "mibs")
+	brokenMIBPath  = filepath.Join("..", "..", "internal", "mib", "testdata", "broken")
+	brokenMIBError = filepath.Join(brokenMIBPath, "BROKEN-MIB") + ":17: unexpected 'read-only', expected '('"
+)
+
+func TestCompile(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "mibs.compiled")
+
+	var stdout, stderr bytes.Buffer
+	require.Equal(t, 0, run([]string{"compile", "-o", output, testMIBPath}, &stdout, &stderr))
+	require.Empty(t, stderr.String())
+
+	mibs, err := mib.LoadCompiled(output)
+	require.NoError(t, err)
+	require.Equal(t, "IF-MIB::linkDown", mibs.Name(".1.3.6.1.6.3.1.1.5.3"))
+	require.Equal(t, "ENTITY-MIB::entConfigChange", mibs.Name(".1.3.6.1.2.1.47.2.0.1"))
+
+	require.Equal(t, 0, run([]string{"compile", "-standard=false", "-o", output, testMIBPath}, &stdout, &stderr))
+	mibs, err = mib.LoadCompiled(output)
+	require.NoError(t, err)
+	require.Equal(t, "IF-MIB::linkDown", mibs.Name(".1.3.6.1.6.3.1.1.5.3"))
+	require.Equal(t, "SNMPv2-SMI::mib-2.47.2.0.1", mibs.Name(".1.3.6.1.2.1.47.2.0.1"))
+}
+
+func TestCompileErrors(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "mibs.compiled")
+
+	// Problems are reported with their file and line, and the rest of the modules are compiled
+	var stdout, stderr bytes.Buffer
+	require.Equal(t, 0, run([]string{"compile", "-o", output, brokenMIBPath, testMIBPath}, &stdout, &stderr))
+	require.Contains(t, stderr.String(), brokenMIBError+"\n")
+	_, err := mib.LoadCompiled(output)
+	require.NoError(t, err)
+
+	strictOutput := filepath.Join(t.TempDir(), "strict.compiled")
+	stderr.Reset()
+	require.Equal(t, 1, run([]string{"compile", "-strict", "-o", strictOutput, brokenMIBPath}, &stdout, &stderr))
+	require.Contains(t, stderr.String(), brokenMIBError+"\n")
+	require.Contains(t, stderr.String(), "snmpmib compile: 5 problems with the MIB modules\n")
+	require.NoFileExists(t, strictOutput)
+
+	type testCase struct {
+		name     string
+		args     []string
+		expected int
+	}
+
+	testCases := []testCase{
+		{name: "NoCommand", args: nil, expected: 2},
+		{name: "UnknownCommand", args: []string{"frobnicate"}, expected: 2},
+		{name: "MissingOutput", args: []string{"compile", testMIBPath}, expected: 2},
+		{name: "MissingDirectories", args: []string{"compile", "-o", output}, expected: 2},
+		{name: "BadFlag", args: []string{"compile", "-x"}, expected: 1},
+		{name: "TranslateNothing", args: []string{"translate"}, expected: 2},
+		{name: "NotificationNothing", args: []string{"notification"}, expected: 2},
+		{name: "NotificationSeveral", args: []string{"notification", "linkDown", "linkUp"}, expected: 2},
+		{name: "LintNothing", args: []string{"lint"}, expected: 2},
+		{name: "CompiledAndDirectories", args: []string{"translate", "-c", output, "-m", testMIBPath, "linkDown"}, expected: 1},
+		{name: "Help", args: []string{"help"}, expected: 0},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			require.Equal(t, test.expected, run(test.args, &stdout, &stderr))
+		})
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	require.Equal(t, 1, run([]string{
+		"translate", "-m", testMIBPath,
+		".1.3.6.1.2.1.2.2.1.2.12", "IF-MIB::ifDescr.12", "linkDown", "1.3.6.1.4.1.9.9.41.2.0.1", "exampleUndefined", ".3.1",
+	}, &stdout, &stderr))
+	require.Equal(t, "IF-MIB::ifDescr.12\n1.3.6.1.2.1.2.2.1.2.12\n1.3.6.1.6.3.1.1.5.3\nSNMPv2-SMI::enterprises.9.9.41.2.0.1\n", stdout.String())
+	require.Equal(t, "exampleUndefined is not defined\n.3.1 is not under any known object\n", stderr.String())
+
+	// Compiled MIBs are translated like the receiver's compiled_mib
+	compiled := filepath.Join(t.TempDir(), "mibs.compiled")
+	require.Equal(t, 0, run([]string{"compile", "-o", compiled, testMIBPath}, &stdout, &stderr))
+	stdout.Reset()
+	stderr.Reset()
+	require.Equal(t, 0, run([]string{"translate", "-c", compiled, "EXAMPLE-TRAP-MIB::exampleFanFailure"}, &stdout, &stderr))
+	require.Equal(t, "1.3.6.1.4.1.32473.1.1.0.1\n", stdout.String())
+
+	// Problems with the modules are warned about, and the rest of them are used
+	stdout.Reset()
+	stderr.Reset()
+	require.Equal(t, 0, run([]string{"translate", "-m", brokenMIBPath, "ifIndex"}, &stdout, &stderr))
+	require.Equal(t, "1.3.6.1.2.1.2.2.1.1\n", stdout.String())
+	require.Equal(t, "warning: 5 problems with the MIB modules, see snmpmib lint\n", stderr.String())
+}
+
+func TestNotification(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	require.Equal(t, 0, run([]string{"notification", "-m", testMIBPath, ".1.3.6.1.4.1.32473.1.1.0.1"}, &stdout, &stderr))
+	require.Equal(t, `EXAMPLE-TRAP-MIB::exampleFanFailure
+  OID:     1.3.6.1.4.1.32473.1.1.0.1
+  Kind:    TRAP-TYPE
+  Objects:
+    EXAMPLE-TRAP-MIB::exampleFanIndex (1.3.6.1.4.1.32473.2.1.1.1) INTEGER
+    EXAMPLE-TRAP-MIB::exampleFanStatus (1.3.6.1.4.1.32473.2.1.1.2) INTEGER { ok(1), failed(2), absent(-1) }
+  Description:
+    A fan failed.
+`, stdout.String())
+
+	stdout.Reset()
+	require.Equal(t, 0, run([]string{"notification", "IF-MIB::linkDown"}, &stdout, &stderr))
+	require.Contains(t, stdout.String(), "    IF-MIB::ifIndex (1.3.6.1.2.1.2.2.1.1) InterfaceIndex (INTEGER) DISPLAY-HINT \"d\"\n")
+
+	stderr.Reset()
+	require.Equal(t, 1, run([]string{"notification", "ifIndex"}, &stdout, &stderr))
+	require.Equal(t, "snmpmib notification: ifIndex is not a notification\n", stderr.String())
+}
+
+func TestLint(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	require.Equal(t, 0, run([]string{"lint", testMIBPath}, &stdout, &stderr))
+	require.Equal(t, "no problems found\n", stdout.String())
+	require.Empty(t, stderr.String())
+
+	stdout.Reset()
+	require.Equal(t, 1, run([]string{"lint", brokenMIBPath}, &stdout, &stderr))
+	require.Empty(t, stdout.String())
+	require.Contains(t, stderr.String(), brokenMIBError+"\n")
+	require.Contains(t, stderr.String(), "snmpmib lint: 5 problems with the MIB modules\n")
+}
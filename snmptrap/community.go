@@ -0,0 +1,76 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package snmptrapreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver"
+
+import (
+	"crypto/subtle"
+	"net"
+	"net/netip"
+	"strings"
+)
+
+// What happens to v1 and v2c traps with a community that isn't allowed
+const (
+	communityModeReject = "reject"
+	communityModeDrop   = "drop"
+	communityModeTag    = "tag"
+)
+
+// allowedCommunity is an entry of the community allowlist
+type allowedCommunity struct {
+	community []byte
+	// sources restricts the community to senders in these networks, when not empty
+	sources []netip.Prefix
+}
+
+// communityPolicy authorizes v1 and v2c traps by their community, the way authCommunity does in snmptrapd
+type communityPolicy struct {
+	mode        string
+	communities []allowedCommunity
+}
+
+// newCommunityPolicy creates the community policy of a receiver. It returns nil when no
+// communities are configured, in which case traps with any community are accepted.
+func newCommunityPolicy(cfg *Config) (*communityPolicy, error) {
+	if len(cfg.Communities) == 0 {
+		return nil, nil
+	}
+
+	policy := &communityPolicy{mode: strings.ToLower(cfg.CommunityMode)}
+	if policy.mode == "" {
+		policy.mode = defaultCommunityMode
+	}
+	for _, communityCfg := range cfg.Communities {
+		allowed := allowedCommunity{community: []byte(communityCfg.Community)}
+		for _, source := range communityCfg.Sources {
+			prefix, err := parsePrefix(source)
+			if err != nil {
+				return nil, err
+			}
+			allowed.sources = append(allowed.sources, prefix)
+		}
+		policy.communities = append(policy.communities, allowed)
+	}
+
+	return policy, nil
+}
+
+// authorized reports whether a trap with community sent from source is allowed
+func (p *communityPolicy) authorized(community string, source net.IP) bool {
+	if p == nil {
+		return true
+	}
+
+	addr, _ := netip.AddrFromSlice(source)
+	addr = addr.Unmap()
+	for _, allowed := range p.communities {
+		if subtle.ConstantTimeCompare(allowed.community, []byte(community)) != 1 {
+			continue
+		}
+		if len(allowed.sources) == 0 || prefixesContain(allowed.sources, addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,147 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package snmptrapreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver"
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+)
+
+func TestCommunityPolicyAuthorized(t *testing.T) {
+	policy, err := newCommunityPolicy(&Config{
+		Communities: []CommunityConfig{
+			{Community: "public"},
+			{Community: "monitoring", Sources: []string{"10.0.0.0/8", "2001:db8::/32", "192.0.2.7"}},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, communityModeReject, policy.mode)
+
+	type testCase struct {
+		name       string
+		community  string
+		source     string
+		authorized bool
+	}
+
+	testCases := []testCase{
+		{name: "AnySource", community: "public", source: "198.51.100.1", authorized: true},
+		{name: "InSourceCIDR", community: "monitoring", source: "10.1.2.3", authorized: true},
+		{name: "InSourceIPv6CIDR", community: "monitoring", source: "2001:db8::1", authorized: true},
+		{name: "SourceAddress", community: "monitoring", source: "192.0.2.7", authorized: true},
+		{name: "MappedSource", community: "monitoring", source: "::ffff:10.1.2.3", authorized: true},
+		{name: "OutsideSources", community: "monitoring", source: "192.0.2.8", authorized: false},
+		{name: "UnknownCommunity", community: "private", source: "10.1.2.3", authorized: false},
+		{name: "CommunityPrefix", community: "publ", source: "10.1.2.3", authorized: false},
+		{name: "EmptyCommunity", community: "", source: "10.1.2.3", authorized: false},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.authorized, policy.authorized(test.community, net.ParseIP(test.source)))
+		})
+	}
+}
+
+func TestCommunityPolicyDisabled(t *testing.T) {
+	policy, err := newCommunityPolicy(&Config{CommunityMode: communityModeDrop})
+	require.NoError(t, err)
+	require.Nil(t, policy)
+	require.True(t, policy.authorized("anything", net.ParseIP("192.0.2.1")))
+}
+
+// Traps with a community that isn't allowed are rejected, dropped or tagged depending on the mode
+func TestReceiveTrapCommunityModes(t *testing.T) {
+	type testCase struct {
+		name             string
+		mode             string
+		community        string
+		inform           bool
+		expectedLogged   bool
+		expectedRejected int64
+	}
+
+	testCases := []testCase{
+		{name: "Allowed", mode: communityModeReject, community: "public", expectedLogged: true},
+		{name: "Rejected", mode: communityModeReject, community: "private", expectedRejected: 1},
+		{name: "Dropped", mode: communityModeDrop, community: "private", expectedRejected: 1},
+		{name: "Tagged", mode: communityModeTag, community: "private", expectedLogged: true},
+		{name: "InformRejected", mode: communityModeReject, community: "private", inform: true, expectedRejected: 1},
+		{name: "InformTagged", mode: communityModeTag, community: "private", inform: true, expectedLogged: true},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.ListenAddress = "udp://127.0.0.1:0"
+			cfg.Communities = []CommunityConfig{{Community: "public", Sources: []string{"127.0.0.0/8"}}}
+			cfg.CommunityMode = test.mode
+			require.NoError(t, cfg.Validate())
+
+			reader := sdkmetric.NewManualReader()
+			settings := receivertest.NewNopCreateSettings()
+			settings.MeterProvider = sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+			sink := new(consumertest.LogsSink)
+			rcvr, err := newSnmptrapReceiver(settings, cfg, sink)
+			require.NoError(t, err)
+			require.NoError(t, rcvr.Start(context.Background(), componenttest.NewNopHost()))
+			defer func() {
+				require.NoError(t, rcvr.Shutdown(context.Background()))
+			}()
+
+			_, port := splitAddr(rcvr.listeners[0].localAddr())
+			client := &gosnmp.GoSNMP{
+				Target:    "127.0.0.1",
+				Port:      uint16(port),
+				Transport: "udp",
+				Community: test.community,
+				Version:   gosnmp.Version2c,
+				Timeout:   200 * time.Millisecond,
+				MaxOids:   gosnmp.MaxOids,
+			}
+			require.NoError(t, client.Connect())
+			defer client.Conn.Close()
+
+			notification := testNotification
+			notification.IsInform = test.inform
+			_, err = client.SendTrap(notification)
+			if test.inform && !test.expectedLogged {
+				// Rejected informs are not acknowledged
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			if test.expectedLogged {
+				require.Eventually(t, func() bool {
+					return sink.LogRecordCount() == 1
+				}, 5*time.Second, 10*time.Millisecond)
+
+				attributes := sink.AllLogs()[0].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Attributes()
+				authorized, ok := attributes.Get(attributeSNMPCommunityAuthorized)
+				if test.community == "public" {
+					require.False(t, ok)
+				} else {
+					require.True(t, ok)
+					require.False(t, authorized.Bool())
+				}
+			} else {
+				require.Eventually(t, func() bool {
+					return counterValue(t, reader, metricRejectedNotifications, reasonCommunity) == test.expectedRejected
+				}, 5*time.Second, 10*time.Millisecond)
+				require.Zero(t, sink.LogRecordCount())
+			}
+		})
+	}
+}
@@ -10,6 +10,8 @@ import (
 	"time"
 
 	"go.opentelemetry.io/collector/config/configopaque"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver/internal/snmptt"
 )
 
 // Config Defaults
@@ -21,12 +23,19 @@ const (
 	defaultSecurityLevel      = "no_auth_no_priv"
 	defaultAuthType           = "MD5"
 	defaultPrivacyType        = "DES"
+	defaultInformDeduplicationWindow = 30 * time.Second
+	defaultMIBReloadInterval = time.Minute
+	defaultTCPIdleTimeout = 2 * time.Minute
+	defaultTCPMaxConnections = 1024
+	defaultTCPMaxConnectionsPerPeer = 16
+	defaultCommunityMode      = communityModeReject
 )
 
 var (
 	// Config error messages
 	errMsgInvalidListenAddressWError                     = `invalid endpoint '%s': must be in '[scheme]://[host]:[port]' format: %w`
 	errMsgInvalidListenAddress                           = `invalid endpoint '%s': must be in '[scheme]://[host]:[port]' format`
+	errMsgInvalidSource = `invalid source '%s': must be an IP address or a CIDR block`
 
 	// Config errors
 	errEmptyListenAddress        = errors.New("endpoint must be specified")
@@ -42,16 +51,32 @@ var (
 	errEmptyPrivacyType     = errors.New("privacy_type must be specified when security_level is auth_priv")
 	errBadPrivacyType       = errors.New("privacy_type must be either DES, AES, AES192, AES192C, AES256, AES256C")
 	errEmptyPrivacyPassword = errors.New("privacy_password must be specified when security_level is auth_priv")
+	errDuplicateListenAddress = errors.New("listen address is given more than once")
+	errBadEngineID = errors.New("engine_id must be a hex string of 5 to 32 bytes")
+	errNegativeDeduplicationWindow = errors.New("inform_deduplication_window must not be negative")
+	errDuplicateUser = errors.New("user is given more than once for the same engine_id")
+	errEmptyAllowedCommunity = errors.New("community must be specified for each entry of communities")
+	errBadCommunityMode = errors.New("community_mode must be either reject, drop, or tag")
+	errEmptyMIBPath = errors.New("mib_paths must not contain empty paths")
+	errCompiledMIBWithPaths = errors.New("compiled_mib and mib_paths are mutually exclusive")
+	errNegativeMIBReloadInterval = errors.New("mib_reload_interval must not be negative")
+	errNegativeTCPIdleTimeout = errors.New("tcp_idle_timeout must not be negative")
+	errNegativeTCPMaxConnections = errors.New("tcp_max_connections and tcp_max_connections_per_peer must not be negative")
 )
 
 // Config defines the configuration for the various elements of the receiver.
 type Config struct {
 	// ListenAddress is the host (IP or hostname) + port to listen on. Must be formatted as [udp|tcp|][4|6|]://{host}:{port}.
 	// Default: udp://localhost:162
-	// If no scheme is given, udp4 is assumed.
-	// If no port is given, 162 is assumed.
+	// The udp and tcp schemes bound to [::] accept both IPv4 and IPv6 peers, while the 4 and 6
+	// suffixes restrict the socket to a single address family.
+	// Over tcp, messages are read back to back from the stream as described by RFC 3430.
 	ListenAddress string `mapstructure:"listen_address"`
 
+	// ListenAddresses binds several sockets for this receiver, each of which may override the
+	// version and credentials given here. When set, ListenAddress is not used.
+	ListenAddresses []ListenAddressConfig `mapstructure:"listen_addresses"`
+
 	// Version is the version of SNMP to use for this connection.
 	// Valid options: v1, v2c, v3.
 	// Default: v2c
@@ -92,12 +117,266 @@ type Config struct {
 	// Only valid for version “v3” and if "auth_priv" is selected for SecurityLevel
 	PrivacyPassword configopaque.String `mapstructure:"privacy_password"`
 
+	// NormalizeV1Traps translates v1 traps into SNMPv2-Trap-PDUs as described by RFC 3584 section 3.1,
+	// so that traps have the same shape whatever the version of the sender. The v1 enterprise,
+	// agent-addr, generic-trap, specific-trap and time-stamp are kept as snmp.v1.* attributes.
+	// Default: false
+	NormalizeV1Traps bool `mapstructure:"normalize_v1_traps"`
+
+	// Allow lists the IP addresses or CIDR blocks traps are accepted from. The source address of
+	// each packet is checked before it is decoded, and the agent-addr of v1 traps once decoded.
+	// Default: traps are accepted from any address not in Deny
+	Allow []string `mapstructure:"allow"`
+
+	// Deny lists the IP addresses or CIDR blocks traps are never accepted from, even when they are in Allow.
+	Deny []string `mapstructure:"deny"`
+
+	// Communities is the allowlist of community strings accepted from v1 and v2c senders, each
+	// optionally restricted to source networks. When empty, traps with any community are accepted.
+	Communities []CommunityConfig `mapstructure:"communities"`
+
+	// CommunityMode is what happens to v1 and v2c traps with a community that isn't allowed by Communities.
+	// Valid options: "reject" drops them and logs a warning, "drop" drops them silently, and "tag"
+	// passes them on with the snmp.community.authorized attribute set to false.
+	// Default: reject
+	CommunityMode string `mapstructure:"community_mode"`
+
+	// Users is the table of SNMPv3 users accepted by v3 listeners, alongside User when it is set.
+	// Only valid for version "v3"
+	Users []UserConfig `mapstructure:"users"`
+
 	// CloseTimeout is the max wait time for the socket to gracefully signal its closure.
 	CloseTimeout time.Duration `mapstructure:"listener_close_timeout"`
 
+	// TCPIdleTimeout is how long a peer connected over tcp may take to send its next message,
+	// whole, before its connection is closed.
+	// Default: 2m. A timeout of 0 disables it.
+	TCPIdleTimeout time.Duration `mapstructure:"tcp_idle_timeout"`
+
+	// TCPMaxConnections caps the tcp connections held open by the listeners of the receiver, and
+	// TCPMaxConnectionsPerPeer those of each peer address. Connections over the limits are
+	// closed as soon as they are accepted.
+	// Default: 1024 and 16. A limit of 0 disables it.
+	TCPMaxConnections        int `mapstructure:"tcp_max_connections"`
+	TCPMaxConnectionsPerPeer int `mapstructure:"tcp_max_connections_per_peer"`
+
+	// EngineID is the hex encoded engine ID of the receiver. The receiver is the authoritative
+	// engine for the SNMPv3 informs sent to it, so senders localize their keys with this ID.
+	// Default: a random engine ID generated on start, which senders discover before sending informs
+	EngineID string `mapstructure:"engine_id"`
+
+	// InformDeduplicationWindow is how long informs are remembered, so that retransmissions with
+	// the same request ID from the same source are acknowledged again but not logged twice.
+	// Default: 30s. A window of 0 disables deduplication.
+	InformDeduplicationWindow time.Duration `mapstructure:"inform_deduplication_window"`
+
+	// MIBPaths are the directories holding the SMIv1 and SMIv2 MIB modules used to name OIDs.
+	// A module found in several directories is taken from the first one, and the modules of the
+	// directories replace the standard ones with the same name.
+	// Default: only the standard MIBs are used
+	MIBPaths []string `mapstructure:"mib_paths"`
+
+	// StandardMIBs loads the standard MIB set embedded in the receiver, such as SNMPv2-MIB, IF-MIB,
+	// ENTITY-MIB and the generic traps of RFC 1215, after the modules of MIBPaths. It is ignored
+	// with CompiledMIB, which includes the standard MIBs when they were compiled in.
+	// Default: true
+	StandardMIBs bool `mapstructure:"standard_mibs"`
+
+	// CompiledMIB is a file compiled from MIB modules by `snmpmib compile`, which is loaded
+	// instead of parsing the modules of MIBPaths
+	// Default: OIDs are not named
+	CompiledMIB string `mapstructure:"compiled_mib"`
+
+	// MIBReloadInterval is how often the files of MIBPaths or CompiledMIB are checked for changes.
+	// The MIBs are loaded again in the background when they change, and replace the current ones
+	// unless they have new problems.
+	// Default: 1m. An interval of 0 disables reloading.
+	MIBReloadInterval time.Duration `mapstructure:"mib_reload_interval"`
+
+	// EnterpriseNumbers is a copy of the IANA Private Enterprise Numbers registry, such as
+	// https://www.iana.org/assignments/enterprise-numbers.txt once downloaded, which replaces the
+	// abridged copy embedded in the receiver. It names the vendor of each notification.
+	// Default: the embedded registry is used
+	EnterpriseNumbers string `mapstructure:"enterprise_numbers"`
+
+	// SeverityRules set the severity of the log records of the notifications they match. The
+	// first matching rule is used, and the default rules giving the generic traps a severity,
+	// such as WARN for linkDown, are applied after them.
+	// Default: only the default rules are applied
+	SeverityRules []SeverityRuleConfig `mapstructure:"severity_rules"`
+
+	// MessageTemplates render a message for humans into the body of the log records of the
+	// notifications with their snmpTrapOID. The map of the PDU which is otherwise the body is
+	// moved to the snmp.pdu attribute.
+	// Default: the body is the map of the PDU
+	MessageTemplates []MessageTemplateConfig `mapstructure:"message_templates"`
+
+	// SNMPTTFiles are SNMPTT configuration files, snmptt.conf, whose EVENT definitions give the
+	// notifications they match a category, a severity and the message of their FORMAT line.
+	// The NODES and MATCH lines restrict the notifications an event matches, and its REGEX lines
+	// rewrite its message. Message templates take precedence over FORMAT lines.
+	// Default: no SNMPTT configuration is used
+	SNMPTTFiles []string `mapstructure:"snmptt_files"`
+
+	// SnmptrapdConf is a Net-SNMP snmptrapd.conf file, whose authCommunity lines are added to
+	// Communities, whose users authorized by authUser lines are added to Users, and whose engineID
+	// sets EngineID. disableAuthorization yes accepts any community, as Communities does when it is
+	// empty. The file is read when the configuration is validated, and its settings which conflict
+	// with those given here are errors.
+	// Default: no snmptrapd.conf file is used
+	SnmptrapdConf string `mapstructure:"snmptrapd_conf"`
+
+	// AgentAddressOrder is the order in which the agent which originated a notification is
+	// resolved from its addresses: "agent_addr" is the agent-addr of v1 traps, "snmp_trap_address"
+	// the snmpTrapAddress.0 varbind of notifications forwarded by a proxy, and "source" the sender
+	// of the packet. The first one a notification has is the snmp.agent.address resource attribute.
+	// Default: agent_addr, snmp_trap_address, source
+	AgentAddressOrder []string `mapstructure:"agent_address_order"`
+
 }
 
-// FIXME: require a representation of a PDU
+// ListenAddressConfig is a single socket of a receiver with several listen addresses.
+// Any version or credential field left empty is taken from the receiver's Config.
+type ListenAddressConfig struct {
+	// Address is the address to listen on, in the same format as Config.ListenAddress
+	Address string `mapstructure:"address"`
+
+	Version         string              `mapstructure:"version"`
+	Community       string              `mapstructure:"community"`
+	User            string              `mapstructure:"user"`
+	SecurityLevel   string              `mapstructure:"security_level"`
+	AuthType        string              `mapstructure:"auth_type"`
+	AuthPassword    configopaque.String `mapstructure:"auth_password"`
+	PrivacyType     string              `mapstructure:"privacy_type"`
+	PrivacyPassword configopaque.String `mapstructure:"privacy_password"`
+}
+
+// CommunityConfig is an entry of the community allowlist
+type CommunityConfig struct {
+	Community string `mapstructure:"community"`
+
+	// Sources are the IP addresses or CIDR blocks the community is accepted from.
+	// Default: the community is accepted from any source
+	Sources []string `mapstructure:"sources"`
+}
+
+// UserConfig is an SNMPv3 user of the users table. Security settings left empty take
+// the same defaults as those of the receiver's Config.
+type UserConfig struct {
+	User string `mapstructure:"user"`
+
+	// EngineID restricts the user to messages of the authoritative engine with this hex
+	// encoded ID, so that the same user name can have other credentials on other devices.
+	// The sender is the authoritative engine of traps, and the receiver that of informs.
+	// Default: the user is accepted from any engine
+	EngineID string `mapstructure:"engine_id"`
+
+	SecurityLevel   string              `mapstructure:"security_level"`
+	AuthType        string              `mapstructure:"auth_type"`
+	AuthPassword    configopaque.String `mapstructure:"auth_password"`
+	PrivacyType     string              `mapstructure:"privacy_type"`
+	PrivacyPassword configopaque.String `mapstructure:"privacy_password"`
+}
+
+// SeverityRuleConfig is an entry of severity_rules. A notification matches the rule when it
+// matches all of the criteria which are set.
+type SeverityRuleConfig struct {
+	// TrapOID is the snmpTrapOID of the notifications the rule matches
+	TrapOID string `mapstructure:"trap_oid"`
+	// TrapOIDPrefix matches the snmpTrapOIDs which are the given OID or are under it
+	TrapOIDPrefix string `mapstructure:"trap_oid_prefix"`
+	// TrapOIDGlob matches the snmpTrapOIDs matching a pattern, in which * stands for any
+	// characters including dots, and ? for a single character
+	TrapOIDGlob string `mapstructure:"trap_oid_glob"`
+
+	// Sources are the IP addresses or CIDR blocks of the agents the rule matches, resolved in the
+	// order of AgentAddressOrder
+	Sources []string `mapstructure:"sources"`
+	// Communities are the communities of the v1 and v2c notifications the rule matches
+	Communities []string `mapstructure:"communities"`
+	// Varbinds must all hold for the rule to match
+	Varbinds []VarbindPredicateConfig `mapstructure:"varbinds"`
+
+	// Severity is the severity number set by the rule, by its short name such as WARN or ERROR2
+	Severity string `mapstructure:"severity"`
+	// SeverityText is the severity text set by the rule
+	// Default: the name of Severity, in upper case
+	SeverityText string `mapstructure:"severity_text"`
+}
+
+// VarbindPredicateConfig holds when a notification carries a varbind of the OID, or of an
+// instance of it, whose value satisfies the operator
+type VarbindPredicateConfig struct {
+	OID string `mapstructure:"oid"`
+	// Operator compares the value of the varbind with Value.
+	// Valid options: "equals", "not_equals", "matches" for a regular expression, and
+	// "greater_than" and "less_than" for integers
+	// Default: equals
+	Operator string `mapstructure:"operator"`
+	Value    string `mapstructure:"value"`
+}
+
+// MessageTemplateConfig is an entry of message_templates
+type MessageTemplateConfig struct {
+	// TrapOID is the snmpTrapOID of the notifications the template renders the message of
+	TrapOID string `mapstructure:"trap_oid"`
+	// Template is a Go text/template, executed with the fields and varbinds of the notification
+	Template string `mapstructure:"template"`
+}
+
+// config returns the user's settings as a v3 Config, with the defaults applied
+func (user UserConfig) config() *Config {
+	cfg := &Config{
+		Version:         "v3",
+		User:            user.User,
+		SecurityLevel:   defaultSecurityLevel,
+		AuthType:        defaultAuthType,
+		AuthPassword:    user.AuthPassword,
+		PrivacyType:     defaultPrivacyType,
+		PrivacyPassword: user.PrivacyPassword,
+	}
+	overrideString(&cfg.SecurityLevel, user.SecurityLevel)
+	overrideString(&cfg.AuthType, user.AuthType)
+	overrideString(&cfg.PrivacyType, user.PrivacyType)
+	return cfg
+}
+
+// listenerConfigs returns one config per socket to bind, with the per-address
+// overrides of ListenAddresses applied on top of the receiver's settings
+func (cfg *Config) listenerConfigs() []*Config {
+	if len(cfg.ListenAddresses) == 0 {
+		return []*Config{cfg}
+	}
+
+	configs := make([]*Config, 0, len(cfg.ListenAddresses))
+	for _, address := range cfg.ListenAddresses {
+		listenerCfg := *cfg
+		listenerCfg.ListenAddresses = nil
+		listenerCfg.ListenAddress = address.Address
+		overrideString(&listenerCfg.Version, address.Version)
+		overrideString(&listenerCfg.Community, address.Community)
+		overrideString(&listenerCfg.User, address.User)
+		overrideString(&listenerCfg.SecurityLevel, address.SecurityLevel)
+		overrideString(&listenerCfg.AuthType, address.AuthType)
+		overrideString(&listenerCfg.PrivacyType, address.PrivacyType)
+		if address.AuthPassword != "" {
+			listenerCfg.AuthPassword = address.AuthPassword
+		}
+		if address.PrivacyPassword != "" {
+			listenerCfg.PrivacyPassword = address.PrivacyPassword
+		}
+		configs = append(configs, &listenerCfg)
+	}
+
+	return configs
+}
+
+// overrideString replaces value with override unless the override is empty
+func overrideString(value *string, override string) {
+	if override != "" {
+		*value = override
+	}
+}
 
 // ResourceAttributeConfig contains config info about all of the resource attributes that will be used by this receiver.
 type ResourceAttributeConfig struct {
@@ -151,15 +430,209 @@ type Attribute struct {
 func (cfg *Config) Validate() error {
 	var combinedErr error
 
+	if _, err := parseEngineID(cfg.EngineID); err != nil {
+		combinedErr = errors.Join(combinedErr, err)
+	}
+	if cfg.InformDeduplicationWindow < 0 {
+		combinedErr = errors.Join(combinedErr, errNegativeDeduplicationWindow)
+	}
+
+	combinedErr = errors.Join(combinedErr, validateSources("allow", cfg.Allow))
+	combinedErr = errors.Join(combinedErr, validateSources("deny", cfg.Deny))
+	combinedErr = errors.Join(combinedErr, validateCommunities(cfg))
+	combinedErr = errors.Join(combinedErr, validateUsers(cfg.Users))
+	combinedErr = errors.Join(combinedErr, validateSeverityRules(cfg.SeverityRules))
+	combinedErr = errors.Join(combinedErr, validateMessageTemplates(cfg.MessageTemplates))
+	for i, path := range cfg.MIBPaths {
+		if path == "" {
+			combinedErr = errors.Join(combinedErr, fmt.Errorf("mib_paths[%d]: %w", i, errEmptyMIBPath))
+		}
+	}
+	combinedErr = errors.Join(combinedErr, validateSNMPTTFiles(cfg.SNMPTTFiles))
+	combinedErr = errors.Join(combinedErr, validateAgentAddressOrder(cfg.AgentAddressOrder))
+	if _, err := cfg.withSnmptrapdConf(); err != nil {
+		combinedErr = errors.Join(combinedErr, fmt.Errorf("snmptrapd_conf: %w", err))
+	}
+	if cfg.CompiledMIB != "" && len(cfg.MIBPaths) > 0 {
+		combinedErr = errors.Join(combinedErr, errCompiledMIBWithPaths)
+	}
+	if cfg.MIBReloadInterval < 0 {
+		combinedErr = errors.Join(combinedErr, errNegativeMIBReloadInterval)
+	}
+	if cfg.TCPIdleTimeout < 0 {
+		combinedErr = errors.Join(combinedErr, errNegativeTCPIdleTimeout)
+	}
+	if cfg.TCPMaxConnections < 0 || cfg.TCPMaxConnectionsPerPeer < 0 {
+		combinedErr = errors.Join(combinedErr, errNegativeTCPMaxConnections)
+	}
+
+	if len(cfg.ListenAddresses) == 0 {
+		return errors.Join(combinedErr, validateListener(cfg))
+	}
+
+	seen := map[string]bool{}
+	for i, listenerCfg := range cfg.listenerConfigs() {
+		if err := validateListener(listenerCfg); err != nil {
+			combinedErr = errors.Join(combinedErr, fmt.Errorf("listen_addresses[%d]: %w", i, err))
+		}
+		if seen[listenerCfg.ListenAddress] {
+			combinedErr = errors.Join(combinedErr, fmt.Errorf("listen_addresses[%d]: %w", i, errDuplicateListenAddress))
+		}
+		seen[listenerCfg.ListenAddress] = true
+	}
+
+	return combinedErr
+}
+
+// validateListener validates the address, version and credentials of a single socket
+func validateListener(cfg *Config) error {
+	var combinedErr error
+
 	combinedErr = errors.Join(combinedErr, validateListenAddress(cfg))
 	combinedErr = errors.Join(combinedErr, validateVersion(cfg))
-	if strings.ToUpper(cfg.Version) == "V3" {
+	// The users table may replace the listener's own user
+	if strings.ToUpper(cfg.Version) == "V3" && (cfg.User != "" || len(cfg.Users) == 0) {
 		combinedErr = errors.Join(combinedErr, validateSecurity(cfg))
 	}
 
 	return combinedErr
 }
 
+// validateSources validates a list of IP addresses and CIDR blocks
+func validateSources(name string, sources []string) error {
+	var combinedErr error
+
+	for i, source := range sources {
+		if _, err := parsePrefix(source); err != nil {
+			combinedErr = errors.Join(combinedErr, fmt.Errorf("%s[%d]: %w", name, i, err))
+		}
+	}
+
+	return combinedErr
+}
+
+// validateCommunities validates the community allowlist and what is done with other communities
+func validateCommunities(cfg *Config) error {
+	var combinedErr error
+
+	switch strings.ToLower(cfg.CommunityMode) {
+	case "", communityModeReject, communityModeDrop, communityModeTag: // ok
+	default:
+		combinedErr = errors.Join(combinedErr, errBadCommunityMode)
+	}
+
+	for i, community := range cfg.Communities {
+		if community.Community == "" {
+			combinedErr = errors.Join(combinedErr, fmt.Errorf("communities[%d]: %w", i, errEmptyAllowedCommunity))
+		}
+		for _, source := range community.Sources {
+			if _, err := parsePrefix(source); err != nil {
+				combinedErr = errors.Join(combinedErr, fmt.Errorf("communities[%d]: %w", i, err))
+			}
+		}
+	}
+
+	return combinedErr
+}
+
+// validateUsers validates each entry of the users table
+func validateUsers(users []UserConfig) error {
+	var combinedErr error
+
+	type userID struct {
+		user     string
+		engineID string
+	}
+	seen := map[userID]bool{}
+	for i, user := range users {
+		if err := validateSecurity(user.config()); err != nil {
+			combinedErr = errors.Join(combinedErr, fmt.Errorf("users[%d]: %w", i, err))
+		}
+		engineID, err := parseEngineID(user.EngineID)
+		if err != nil {
+			combinedErr = errors.Join(combinedErr, fmt.Errorf("users[%d]: %w", i, err))
+			continue
+		}
+		id := userID{user: user.User, engineID: engineID}
+		if seen[id] {
+			combinedErr = errors.Join(combinedErr, fmt.Errorf("users[%d]: %w", i, errDuplicateUser))
+		}
+		seen[id] = true
+	}
+
+	return combinedErr
+}
+
+// validateSeverityRules validates each entry of severity_rules
+func validateSeverityRules(rules []SeverityRuleConfig) error {
+	var combinedErr error
+
+	for i, rule := range rules {
+		if _, err := newSeverityRule(rule); err != nil {
+			combinedErr = errors.Join(combinedErr, fmt.Errorf("severity_rules[%d]: %w", i, err))
+		}
+	}
+
+	return combinedErr
+}
+
+// validateMessageTemplates validates each entry of message_templates
+func validateMessageTemplates(templates []MessageTemplateConfig) error {
+	var combinedErr error
+
+	seen := map[string]bool{}
+	for i, messageTemplate := range templates {
+		if _, err := parseMessageTemplate(messageTemplate); err != nil {
+			combinedErr = errors.Join(combinedErr, fmt.Errorf("message_templates[%d]: %w", i, err))
+		}
+		oid := strings.TrimPrefix(messageTemplate.TrapOID, ".")
+		if oid != "" && seen[oid] {
+			combinedErr = errors.Join(combinedErr, fmt.Errorf("message_templates[%d]: %w", i, errDuplicateTemplateOID))
+		}
+		seen[oid] = true
+	}
+
+	return combinedErr
+}
+
+// validateSNMPTTFiles loads each of the snmptt_files, so that their problems are reported
+func validateSNMPTTFiles(files []string) error {
+	var combinedErr error
+
+	for i, file := range files {
+		if file == "" {
+			combinedErr = errors.Join(combinedErr, fmt.Errorf("snmptt_files[%d]: %w", i, errEmptySNMPTTFile))
+			continue
+		}
+		if _, err := snmptt.Load(file); err != nil {
+			combinedErr = errors.Join(combinedErr, fmt.Errorf("snmptt_files[%d]: %w", i, err))
+		}
+	}
+
+	return combinedErr
+}
+
+// validateAgentAddressOrder validates agent_address_order
+func validateAgentAddressOrder(order []string) error {
+	var combinedErr error
+
+	seen := map[string]bool{}
+	for i, from := range order {
+		switch from {
+		case agentAddressAgentAddr, agentAddressSnmpTrapAddress, agentAddressSource:
+		default:
+			combinedErr = errors.Join(combinedErr, fmt.Errorf("agent_address_order[%d]: "+errMsgBadAgentAddressOrder, i, from))
+			continue
+		}
+		if seen[from] {
+			combinedErr = errors.Join(combinedErr, fmt.Errorf("agent_address_order[%d]: %w", i, errDuplicateAgentAddressOrder))
+		}
+		seen[from] = true
+	}
+
+	return combinedErr
+}
+
 // validateListenAddress validates the ListenAddress
 func validateListenAddress(cfg *Config) error {
 	if cfg.ListenAddress == "" {
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -101,6 +102,195 @@ func TestLoadConfigConnectionConfigs(t *testing.T) {
 	expectedConfigV3NoPrivacyPassword.SecurityLevel = "auth_priv"
 	expectedConfigV3NoPrivacyPassword.AuthPassword = "p"
 
+	expectedConfigListenAddresses := factory.CreateDefaultConfig().(*Config)
+	expectedConfigListenAddresses.ListenAddresses = []ListenAddressConfig{
+		{Address: "udp://192.0.2.1:162"},
+		{Address: "tcp://192.0.2.1:10162", Version: "v1", Community: "legacy"},
+		{Address: "udp6://[2001:db8::1]:162", Version: "v3", User: "u", SecurityLevel: "auth_priv", AuthPassword: "p", PrivacyPassword: "pp"},
+	}
+
+	expectedConfigListenAddressesBadOverride := factory.CreateDefaultConfig().(*Config)
+	expectedConfigListenAddressesBadOverride.ListenAddresses = []ListenAddressConfig{
+		{Address: "udp://192.0.2.1:162"},
+		{Address: "udp://192.0.2.2:162", Version: "v3"},
+	}
+
+	expectedConfigListenAddressesDuplicate := factory.CreateDefaultConfig().(*Config)
+	expectedConfigListenAddressesDuplicate.ListenAddresses = []ListenAddressConfig{
+		{Address: "udp://192.0.2.1:162"},
+		{Address: "udp://192.0.2.1:162", Community: "other"},
+	}
+
+	expectedConfigListenAddressesNoAddress := factory.CreateDefaultConfig().(*Config)
+	expectedConfigListenAddressesNoAddress.ListenAddresses = []ListenAddressConfig{
+		{Community: "other"},
+	}
+
+	expectedConfigBadEngineID := factory.CreateDefaultConfig().(*Config)
+	expectedConfigBadEngineID.EngineID = "80001f88"
+
+	expectedConfigInformSettings := factory.CreateDefaultConfig().(*Config)
+	expectedConfigInformSettings.EngineID = "80001f8880e9630000d61ff449"
+	expectedConfigInformSettings.InformDeduplicationWindow = 0
+
+	expectedConfigUsersGood := factory.CreateDefaultConfig().(*Config)
+	expectedConfigUsersGood.Version = "v3"
+	expectedConfigUsersGood.Users = []UserConfig{
+		{
+			User:            "vendor-a",
+			EngineID:        "8000000001020304",
+			SecurityLevel:   "auth_priv",
+			AuthType:        "SHA256",
+			AuthPassword:    "authpassword",
+			PrivacyType:     "AES",
+			PrivacyPassword: "privpassword",
+		},
+		{User: "vendor-a", SecurityLevel: "auth_no_priv", AuthPassword: "authpassword"},
+	}
+
+	expectedConfigUsersBadEntry := factory.CreateDefaultConfig().(*Config)
+	expectedConfigUsersBadEntry.Version = "v3"
+	expectedConfigUsersBadEntry.Users = []UserConfig{
+		{User: "vendor-a", SecurityLevel: "auth_priv", AuthPassword: "authpassword"},
+	}
+
+	expectedConfigUsersBadEngineID := factory.CreateDefaultConfig().(*Config)
+	expectedConfigUsersBadEngineID.Version = "v3"
+	expectedConfigUsersBadEngineID.Users = []UserConfig{
+		{User: "vendor-a", EngineID: "80"},
+	}
+
+	expectedConfigUsersDuplicate := factory.CreateDefaultConfig().(*Config)
+	expectedConfigUsersDuplicate.Version = "v3"
+	expectedConfigUsersDuplicate.Users = []UserConfig{
+		{User: "vendor-a", EngineID: "8000000001020304"},
+		{User: "vendor-a", EngineID: "8000000001020304", SecurityLevel: "auth_no_priv", AuthPassword: "authpassword"},
+	}
+
+	expectedConfigCommunitiesGood := factory.CreateDefaultConfig().(*Config)
+	expectedConfigCommunitiesGood.CommunityMode = "tag"
+	expectedConfigCommunitiesGood.Communities = []CommunityConfig{
+		{Community: "public"},
+		{Community: "monitoring", Sources: []string{"10.0.0.0/8", "2001:db8::1"}},
+	}
+
+	expectedConfigCommunitiesBad := factory.CreateDefaultConfig().(*Config)
+	expectedConfigCommunitiesBad.CommunityMode = "ignore"
+	expectedConfigCommunitiesBad.Communities = []CommunityConfig{
+		{Community: "monitoring", Sources: []string{"10.0.0.0/33"}},
+		{Sources: []string{"10.0.0.0/8"}},
+	}
+
+	expectedConfigACLGood := factory.CreateDefaultConfig().(*Config)
+	expectedConfigACLGood.Allow = []string{"10.0.0.0/8", "2001:db8::/32"}
+	expectedConfigACLGood.Deny = []string{"10.0.0.1"}
+
+	expectedConfigACLBad := factory.CreateDefaultConfig().(*Config)
+	expectedConfigACLBad.Allow = []string{"10.0.0.0/8", "example.com"}
+	expectedConfigACLBad.Deny = []string{"10.0.0.1/40"}
+
+	expectedConfigNormalizeV1Traps := factory.CreateDefaultConfig().(*Config)
+	expectedConfigNormalizeV1Traps.Version = "v1"
+	expectedConfigNormalizeV1Traps.NormalizeV1Traps = true
+
+	expectedConfigMIBPathsGood := factory.CreateDefaultConfig().(*Config)
+	expectedConfigMIBPathsGood.MIBPaths = []string{"/usr/share/snmp/mibs", "/etc/otelcol/mibs"}
+
+	expectedConfigMIBPathsBad := factory.CreateDefaultConfig().(*Config)
+	expectedConfigMIBPathsBad.MIBPaths = []string{"/usr/share/snmp/mibs", ""}
+
+	expectedConfigMIBReloadIntervalBad := factory.CreateDefaultConfig().(*Config)
+	expectedConfigMIBReloadIntervalBad.MIBPaths = []string{"/usr/share/snmp/mibs"}
+	expectedConfigMIBReloadIntervalBad.MIBReloadInterval = -time.Second
+
+	expectedConfigTCPLimitsGood := factory.CreateDefaultConfig().(*Config)
+	expectedConfigTCPLimitsGood.ListenAddress = "tcp://localhost:162"
+	expectedConfigTCPLimitsGood.TCPIdleTimeout = 30 * time.Second
+	expectedConfigTCPLimitsGood.TCPMaxConnections = 100
+	expectedConfigTCPLimitsGood.TCPMaxConnectionsPerPeer = 0
+
+	expectedConfigTCPLimitsBad := factory.CreateDefaultConfig().(*Config)
+	expectedConfigTCPLimitsBad.ListenAddress = "tcp://localhost:162"
+	expectedConfigTCPLimitsBad.TCPIdleTimeout = -time.Second
+	expectedConfigTCPLimitsBad.TCPMaxConnectionsPerPeer = -1
+
+	expectedConfigCompiledMIBGood := factory.CreateDefaultConfig().(*Config)
+	expectedConfigCompiledMIBGood.CompiledMIB = "/var/lib/otelcol/mibs.compiled"
+
+	expectedConfigCompiledMIBBad := factory.CreateDefaultConfig().(*Config)
+	expectedConfigCompiledMIBBad.MIBPaths = []string{"/usr/share/snmp/mibs"}
+	expectedConfigCompiledMIBBad.CompiledMIB = "/var/lib/otelcol/mibs.compiled"
+
+	expectedConfigStandardMIBsOff := factory.CreateDefaultConfig().(*Config)
+	expectedConfigStandardMIBsOff.MIBPaths = []string{"/usr/share/snmp/mibs"}
+	expectedConfigStandardMIBsOff.StandardMIBs = false
+
+	expectedConfigEnterpriseNumbers := factory.CreateDefaultConfig().(*Config)
+	expectedConfigEnterpriseNumbers.EnterpriseNumbers = "/etc/otelcol/enterprise-numbers.txt"
+
+	expectedConfigSeverityRulesGood := factory.CreateDefaultConfig().(*Config)
+	expectedConfigSeverityRulesGood.SeverityRules = []SeverityRuleConfig{
+		{TrapOIDPrefix: "1.3.6.1.4.1.9", Sources: []string{"10.0.0.0/8"}, Communities: []string{"core"}, Severity: "ERROR", SeverityText: "major"},
+		{
+			TrapOID: "1.3.6.1.6.3.1.1.5.3",
+			Varbinds: []VarbindPredicateConfig{{OID: "1.3.6.1.2.1.2.2.1.7", Value: "down"}},
+			Severity: "INFO",
+		},
+		{TrapOIDGlob: "1.3.6.1.4.1.*.0.1", Severity: "WARN"},
+	}
+
+	expectedConfigMessageTemplatesGood := factory.CreateDefaultConfig().(*Config)
+	expectedConfigMessageTemplatesGood.MessageTemplates = []MessageTemplateConfig{
+		{TrapOID: "1.3.6.1.6.3.1.1.5.3", Template: `Interface {{ .Varbind "ifDescr" }} went down on {{ .Source }}`},
+	}
+
+	expectedConfigMessageTemplatesBad := factory.CreateDefaultConfig().(*Config)
+	expectedConfigMessageTemplatesBad.MessageTemplates = []MessageTemplateConfig{
+		{TrapOID: "1.3.6.1.6.3.1.1.5.3", Template: `Interface {{ .Varbind "ifDescr" } went down`},
+		{TrapOID: ".1.3.6.1.6.3.1.1.5.3", Template: `Link down on {{ .Source }}`},
+	}
+
+	expectedConfigSNMPTTFilesGood := factory.CreateDefaultConfig().(*Config)
+	expectedConfigSNMPTTFilesGood.SNMPTTFiles = []string{"testdata/snmptt.conf"}
+
+	expectedConfigSNMPTTFilesBad := factory.CreateDefaultConfig().(*Config)
+	expectedConfigSNMPTTFilesBad.SNMPTTFiles = []string{"testdata/snmptt_bad.conf", ""}
+
+	expectedConfigSnmptrapdConfGood := factory.CreateDefaultConfig().(*Config)
+	expectedConfigSnmptrapdConfGood.SnmptrapdConf = "testdata/snmptrapd.conf"
+	expectedConfigSnmptrapdConfGood.Communities = []CommunityConfig{{Community: "public", Sources: []string{"10.0.0.0/8"}}}
+
+	expectedConfigSnmptrapdConfBad := factory.CreateDefaultConfig().(*Config)
+	expectedConfigSnmptrapdConfBad.SnmptrapdConf = "testdata/snmptrapd.conf"
+	expectedConfigSnmptrapdConfBad.EngineID = "8000000001020304"
+	expectedConfigSnmptrapdConfBad.Users = []UserConfig{
+		{
+			User:            "alice",
+			EngineID:        "8000000001020304",
+			SecurityLevel:   "auth_priv",
+			AuthType:        "SHA",
+			AuthPassword:    "another-secret",
+			PrivacyType:     "AES",
+			PrivacyPassword: "alice-secret",
+		},
+	}
+
+	expectedConfigAgentAddressOrderGood := factory.CreateDefaultConfig().(*Config)
+	expectedConfigAgentAddressOrderGood.AgentAddressOrder = []string{"snmp_trap_address", "agent_addr", "source"}
+
+	expectedConfigAgentAddressOrderBad := factory.CreateDefaultConfig().(*Config)
+	expectedConfigAgentAddressOrderBad.AgentAddressOrder = []string{"snmp_trap_address", "peer", "snmp_trap_address"}
+
+	expectedConfigSeverityRulesBad := factory.CreateDefaultConfig().(*Config)
+	expectedConfigSeverityRulesBad.SeverityRules = []SeverityRuleConfig{
+		{TrapOID: "1.3.6.1.4.1.9.9.41.2.0.1", Severity: "WARNING"},
+		{
+			TrapOID: "1.3.6.1.6.3.1.1.5.3",
+			Varbinds: []VarbindPredicateConfig{{OID: "1.3.6.1.2.1.2.2.1.8", Operator: "greater_than", Value: "down"}},
+			Severity: "INFO",
+		},
+	}
+
 	testCases := []testCase{
 		{
 			name:        "NoListenAddressUsesDefault",
@@ -216,6 +406,252 @@ func TestLoadConfigConnectionConfigs(t *testing.T) {
 			expectedCfg: expectedConfigV3Simple,
 			expectedErr: "",
 		},
+		{
+			name:        "GoodListenAddressesNoErrors",
+			nameVal:     "listen_addresses_good",
+			expectedCfg: expectedConfigListenAddresses,
+			expectedErr: "",
+		},
+		{
+			name:        "ListenAddressesBadOverrideErrors",
+			nameVal:     "listen_addresses_bad_override",
+			expectedCfg: expectedConfigListenAddressesBadOverride,
+			expectedErr: "listen_addresses[1]: " + errEmptyUser.Error(),
+		},
+		{
+			name:        "ListenAddressesDuplicateErrors",
+			nameVal:     "listen_addresses_duplicate",
+			expectedCfg: expectedConfigListenAddressesDuplicate,
+			expectedErr: "listen_addresses[1]: " + errDuplicateListenAddress.Error(),
+		},
+		{
+			name:        "UsersNoErrors",
+			nameVal:     "users_good",
+			expectedCfg: expectedConfigUsersGood,
+			expectedErr: "",
+		},
+		{
+			name:        "UsersBadEntryErrors",
+			nameVal:     "users_bad_entry",
+			expectedCfg: expectedConfigUsersBadEntry,
+			expectedErr: "users[0]: " + errEmptyPrivacyPassword.Error(),
+		},
+		{
+			name:        "UsersBadEngineIDErrors",
+			nameVal:     "users_bad_engine_id",
+			expectedCfg: expectedConfigUsersBadEngineID,
+			expectedErr: "users[0]: " + errBadEngineID.Error(),
+		},
+		{
+			name:        "UsersDuplicateErrors",
+			nameVal:     "users_duplicate",
+			expectedCfg: expectedConfigUsersDuplicate,
+			expectedErr: "users[1]: " + errDuplicateUser.Error(),
+		},
+		{
+			name:        "NormalizeV1TrapsNoErrors",
+			nameVal:     "normalize_v1_traps",
+			expectedCfg: expectedConfigNormalizeV1Traps,
+			expectedErr: "",
+		},
+		{
+			name:        "MIBPathsNoErrors",
+			nameVal:     "mib_paths_good",
+			expectedCfg: expectedConfigMIBPathsGood,
+			expectedErr: "",
+		},
+		{
+			name:        "MIBPathsEmptyPathErrors",
+			nameVal:     "mib_paths_bad",
+			expectedCfg: expectedConfigMIBPathsBad,
+			expectedErr: "mib_paths[1]: " + errEmptyMIBPath.Error(),
+		},
+		{
+			name:        "MIBReloadIntervalNegativeErrors",
+			nameVal:     "mib_reload_interval_bad",
+			expectedCfg: expectedConfigMIBReloadIntervalBad,
+			expectedErr: errNegativeMIBReloadInterval.Error(),
+		},
+		{
+			name:        "TCPLimitsNoErrors",
+			nameVal:     "tcp_limits_good",
+			expectedCfg: expectedConfigTCPLimitsGood,
+			expectedErr: "",
+		},
+		{
+			name:        "TCPLimitsNegativeErrors",
+			nameVal:     "tcp_limits_bad",
+			expectedCfg: expectedConfigTCPLimitsBad,
+			expectedErr: errNegativeTCPIdleTimeout.Error() + "\n" + errNegativeTCPMaxConnections.Error(),
+		},
+		{
+			name:        "CompiledMIBNoErrors",
+			nameVal:     "compiled_mib_good",
+			expectedCfg: expectedConfigCompiledMIBGood,
+			expectedErr: "",
+		},
+		{
+			name:        "CompiledMIBWithPathsErrors",
+			nameVal:     "compiled_mib_bad",
+			expectedCfg: expectedConfigCompiledMIBBad,
+			expectedErr: errCompiledMIBWithPaths.Error(),
+		},
+		{
+			name:        "StandardMIBsOffNoErrors",
+			nameVal:     "standard_mibs_off",
+			expectedCfg: expectedConfigStandardMIBsOff,
+			expectedErr: "",
+		},
+		{
+			name:        "EnterpriseNumbersNoErrors",
+			nameVal:     "enterprise_numbers",
+			expectedCfg: expectedConfigEnterpriseNumbers,
+			expectedErr: "",
+		},
+		{
+			name:        "SeverityRulesNoErrors",
+			nameVal:     "severity_rules_good",
+			expectedCfg: expectedConfigSeverityRulesGood,
+			expectedErr: "",
+		},
+		{
+			name:        "SeverityRulesBadSeverityErrors",
+			nameVal:     "severity_rules_bad",
+			expectedCfg: expectedConfigSeverityRulesBad,
+			expectedErr: "severity_rules[0]: invalid severity 'WARNING'",
+		},
+		{
+			name:        "SeverityRulesBadVarbindErrors",
+			nameVal:     "severity_rules_bad",
+			expectedCfg: expectedConfigSeverityRulesBad,
+			expectedErr: "severity_rules[1]: varbinds[0]: invalid value 'down' for greater_than: must be an integer",
+		},
+		{
+			name:        "MessageTemplatesNoErrors",
+			nameVal:     "message_templates_good",
+			expectedCfg: expectedConfigMessageTemplatesGood,
+			expectedErr: "",
+		},
+		{
+			name:        "MessageTemplatesBadSyntaxErrors",
+			nameVal:     "message_templates_bad",
+			expectedCfg: expectedConfigMessageTemplatesBad,
+			expectedErr: "message_templates[0]: invalid template: template: 1.3.6.1.6.3.1.1.5.3:1: unexpected \"}\" in operand",
+		},
+		{
+			name:        "MessageTemplatesDuplicateErrors",
+			nameVal:     "message_templates_bad",
+			expectedCfg: expectedConfigMessageTemplatesBad,
+			expectedErr: "message_templates[1]: trap_oid is given more than once",
+		},
+		{
+			name:        "SNMPTTFilesNoErrors",
+			nameVal:     "snmptt_files_good",
+			expectedCfg: expectedConfigSNMPTTFilesGood,
+			expectedErr: "",
+		},
+		{
+			name:        "SNMPTTFilesBadMatchErrors",
+			nameVal:     "snmptt_files_bad",
+			expectedCfg: expectedConfigSNMPTTFilesBad,
+			expectedErr: `snmptt_files[0]: testdata/snmptt_bad.conf:3: invalid MATCH "$3 != 1": must be $x: expression`,
+		},
+		{
+			name:        "SNMPTTFilesEmptyPathErrors",
+			nameVal:     "snmptt_files_bad",
+			expectedCfg: expectedConfigSNMPTTFilesBad,
+			expectedErr: "snmptt_files[1]: snmptt_files must not contain empty paths",
+		},
+		{
+			name:        "SnmptrapdConfNoErrors",
+			nameVal:     "snmptrapd_conf_good",
+			expectedCfg: expectedConfigSnmptrapdConfGood,
+			expectedErr: "",
+		},
+		{
+			name:        "SnmptrapdConfUserConflictErrors",
+			nameVal:     "snmptrapd_conf_bad",
+			expectedCfg: expectedConfigSnmptrapdConfBad,
+			expectedErr: "snmptrapd_conf: user 'alice' has other settings in users",
+		},
+		{
+			name:        "AgentAddressOrderNoErrors",
+			nameVal:     "agent_address_order_good",
+			expectedCfg: expectedConfigAgentAddressOrderGood,
+			expectedErr: "",
+		},
+		{
+			name:        "AgentAddressOrderBadAddressErrors",
+			nameVal:     "agent_address_order_bad",
+			expectedCfg: expectedConfigAgentAddressOrderBad,
+			expectedErr: "agent_address_order[1]: invalid address 'peer': must be either agent_addr, snmp_trap_address, or source",
+		},
+		{
+			name:        "AgentAddressOrderDuplicateErrors",
+			nameVal:     "agent_address_order_bad",
+			expectedCfg: expectedConfigAgentAddressOrderBad,
+			expectedErr: "agent_address_order[2]: address is given more than once",
+		},
+		{
+			name:        "ACLNoErrors",
+			nameVal:     "acl_good",
+			expectedCfg: expectedConfigACLGood,
+			expectedErr: "",
+		},
+		{
+			name:        "ACLBadAllowErrors",
+			nameVal:     "acl_bad",
+			expectedCfg: expectedConfigACLBad,
+			expectedErr: "allow[1]: invalid source 'example.com'",
+		},
+		{
+			name:        "ACLBadDenyErrors",
+			nameVal:     "acl_bad",
+			expectedCfg: expectedConfigACLBad,
+			expectedErr: "deny[0]: invalid source '10.0.0.1/40'",
+		},
+		{
+			name:        "CommunitiesNoErrors",
+			nameVal:     "communities_good",
+			expectedCfg: expectedConfigCommunitiesGood,
+			expectedErr: "",
+		},
+		{
+			name:        "CommunitiesBadModeErrors",
+			nameVal:     "communities_bad",
+			expectedCfg: expectedConfigCommunitiesBad,
+			expectedErr: errBadCommunityMode.Error(),
+		},
+		{
+			name:        "CommunitiesBadSourceErrors",
+			nameVal:     "communities_bad",
+			expectedCfg: expectedConfigCommunitiesBad,
+			expectedErr: "communities[0]: invalid source '10.0.0.0/33'",
+		},
+		{
+			name:        "CommunitiesNoCommunityErrors",
+			nameVal:     "communities_bad",
+			expectedCfg: expectedConfigCommunitiesBad,
+			expectedErr: "communities[1]: " + errEmptyAllowedCommunity.Error(),
+		},
+		{
+			name:        "BadEngineIDErrors",
+			nameVal:     "bad_engine_id",
+			expectedCfg: expectedConfigBadEngineID,
+			expectedErr: errBadEngineID.Error(),
+		},
+		{
+			name:        "InformSettingsNoErrors",
+			nameVal:     "inform_settings",
+			expectedCfg: expectedConfigInformSettings,
+			expectedErr: "",
+		},
+		{
+			name:        "ListenAddressesNoAddressErrors",
+			nameVal:     "listen_addresses_no_address",
+			expectedCfg: expectedConfigListenAddressesNoAddress,
+			expectedErr: "listen_addresses[0]: " + errEmptyListenAddress.Error(),
+		},
 	}
 
 	for _, test := range testCases {
@@ -236,6 +672,45 @@ func TestLoadConfigConnectionConfigs(t *testing.T) {
 	}
 }
 
+func TestListenerConfigs(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Version = "v3"
+	cfg.User = "u"
+	cfg.SecurityLevel = "auth_no_priv"
+	cfg.AuthPassword = "p"
+
+	require.Equal(t, []*Config{cfg}, cfg.listenerConfigs())
+
+	cfg.ListenAddresses = []ListenAddressConfig{
+		{Address: "udp://192.0.2.1:162"},
+		{Address: "tcp://192.0.2.1:10162", User: "other", AuthType: "SHA", AuthPassword: "secret"},
+		{Address: "udp://192.0.2.2:162", Version: "v2c", Community: "legacy"},
+	}
+
+	configs := cfg.listenerConfigs()
+	require.Len(t, configs, 3)
+	for i, listenerCfg := range configs {
+		require.Equal(t, cfg.ListenAddresses[i].Address, listenerCfg.ListenAddress)
+		require.Nil(t, listenerCfg.ListenAddresses)
+	}
+
+	require.Equal(t, "v3", configs[0].Version)
+	require.Equal(t, "u", configs[0].User)
+	require.Equal(t, "MD5", configs[0].AuthType)
+
+	require.Equal(t, "v3", configs[1].Version)
+	require.Equal(t, "other", configs[1].User)
+	require.Equal(t, "SHA", configs[1].AuthType)
+	require.EqualValues(t, "secret", configs[1].AuthPassword)
+	require.Equal(t, "auth_no_priv", configs[1].SecurityLevel)
+
+	require.Equal(t, "v2c", configs[2].Version)
+	require.Equal(t, "legacy", configs[2].Community)
+
+	// The receiver's own config must not be modified
+	require.Equal(t, "u", cfg.User)
+	require.Len(t, cfg.ListenAddresses, 3)
+}
 
 func getBaseAttrConfig(attrType string) map[string]*AttributeConfig {
 	switch attrType {
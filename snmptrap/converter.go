@@ -0,0 +1,89 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package snmptrapreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver"
+
+import (
+	"net"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+// Log record attribute keys
+const (
+	attributeNetTransport    = "net.transport"
+	attributeNetSockPeerAddr = "net.sock.peer.addr"
+	attributeNetSockPeerPort = "net.sock.peer.port"
+	attributeNetSockHostAddr = "net.sock.host.addr"
+	attributeNetSockHostPort = "net.sock.host.port"
+	attributeSNMPIsInform    = "snmp.is_inform"
+
+	// attributeSNMPSysUpTime is the time-stamp of v1 traps or the sysUpTime.0 varbind of later
+	// notifications: the hundredths of a second the agent had been up when it sent them. It
+	// counts from the agent's last restart, so it can't tell when the notification was sent.
+	attributeSNMPSysUpTime = "snmp.sysuptime"
+
+	// attributeSNMPCommunityAuthorized is set to false on traps with a community that isn't allowed
+	attributeSNMPCommunityAuthorized = "snmp.community.authorized"
+)
+
+// trapToLogs converts a received trap into a plog.Logs holding a single log record.
+// The record's body holds the PDU as described by SchemaVersion.
+// The peer and the local address that received the trap are recorded the same way
+// for every transport. The time it was received is both the timestamp and the observed
+// timestamp of the record, as SNMP notifications don't tell when they were sent.
+func trapToLogs(packet *gosnmp.SnmpPacket, peer net.Addr, local net.Addr, received time.Time) plog.Logs {
+	logs := plog.NewLogs()
+	logRecord := logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+
+	logRecord.SetTimestamp(pcommon.NewTimestampFromTime(received))
+	logRecord.SetObservedTimestamp(pcommon.NewTimestampFromTime(received))
+
+	logRecord.Attributes().PutStr(attributeNetTransport, "ip_"+addrTransport(local))
+	if ip, port := splitAddr(peer); ip != nil {
+		logRecord.Attributes().PutStr(attributeNetSockPeerAddr, ip.String())
+		logRecord.Attributes().PutInt(attributeNetSockPeerPort, int64(port))
+	}
+	if ip, port := splitAddr(local); ip != nil {
+		logRecord.Attributes().PutStr(attributeNetSockHostAddr, ip.String())
+		logRecord.Attributes().PutInt(attributeNetSockHostPort, int64(port))
+	}
+
+	logRecord.Attributes().PutBool(attributeSNMPIsInform, packet.PDUType == gosnmp.InformRequest)
+	if uptime, ok := trapUptime(packet); ok {
+		logRecord.Attributes().PutInt(attributeSNMPSysUpTime, int64(uptime))
+	}
+
+	EncodeLogRecord(packet, logRecord)
+
+	return logs
+}
+
+// trapUptime returns the sysUpTime of the agent when it sent a notification, which is the
+// time-stamp of v1 traps and the sysUpTime.0 varbind of later notifications
+func trapUptime(packet *gosnmp.SnmpPacket) (uint32, bool) {
+	if packet.PDUType == gosnmp.Trap {
+		return uint32(packet.Timestamp), true
+	}
+	for _, variable := range packet.Variables {
+		if variable.Name == oidSysUpTime {
+			return uint32(gosnmp.ToBigInt(variable.Value).Uint64()), true
+		}
+	}
+	return 0, false
+}
+
+// versionToString converts a gosnmp version into the version names used in the config
+func versionToString(version gosnmp.SnmpVersion) string {
+	switch version {
+	case gosnmp.Version1:
+		return "v1"
+	case gosnmp.Version3:
+		return "v3"
+	default:
+		return "v2c"
+	}
+}
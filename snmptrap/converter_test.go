@@ -0,0 +1,148 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package snmptrapreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver"
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+func TestTrapToLogs(t *testing.T) {
+	type testCase struct {
+		name             string
+		packet           *gosnmp.SnmpPacket
+		expectedIsInform bool
+		// expectedSysUpTime is the snmp.sysuptime attribute, if any
+		expectedSysUpTime any
+		expectedBody      map[string]any
+	}
+
+	testCases := []testCase{
+		{
+			name: "V1Trap",
+			packet: &gosnmp.SnmpPacket{
+				Version:   gosnmp.Version1,
+				Community: "public",
+				PDUType:   gosnmp.Trap,
+				SnmpTrap: gosnmp.SnmpTrap{
+					Enterprise:   ".1.3.6.1.4.1.8072.2.3.1",
+					AgentAddress: "192.0.2.1",
+					GenericTrap:  6,
+					SpecificTrap: 17,
+					Timestamp:    1234,
+				},
+				Variables: []gosnmp.SnmpPDU{
+					{Name: ".1.3.6.1.2.1.2.2.1.1.3", Type: gosnmp.Integer, Value: 3},
+				},
+			},
+			expectedSysUpTime: int64(1234),
+			expectedBody: map[string]any{
+				bodySchemaVersion: int64(SchemaVersion),
+				bodyPDUType:       "Trap",
+				bodyVersion:       "v1",
+				bodyCommunity:     "public",
+				bodyEnterprise:    "1.3.6.1.4.1.8072.2.3.1",
+				bodyAgentAddress:  "192.0.2.1",
+				bodyGenericTrap:   int64(6),
+				bodySpecificTrap:  int64(17),
+				bodyTimestamp:     int64(1234),
+				bodyVarbinds: []any{
+					map[string]any{bodyVarbindOID: "1.3.6.1.2.1.2.2.1.1.3", bodyVarbindType: "Integer", bodyVarbindValue: int64(3)},
+				},
+			},
+		},
+		{
+			name: "V2cTrap",
+			packet: &gosnmp.SnmpPacket{
+				Version:   gosnmp.Version2c,
+				Community: "private",
+				PDUType:   gosnmp.SNMPv2Trap,
+				RequestID: 42,
+				Variables: []gosnmp.SnmpPDU{
+					{Name: ".1.3.6.1.2.1.1.3.0", Type: gosnmp.TimeTicks, Value: uint32(500)},
+					{Name: ".1.3.6.1.6.3.1.1.4.1.0", Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.6.3.1.1.5.3"},
+					{Name: ".1.3.6.1.2.1.2.2.1.2.3", Type: gosnmp.OctetString, Value: []byte("eth0")},
+					{Name: ".1.3.6.1.2.1.31.1.1.1.6.3", Type: gosnmp.Counter64, Value: uint64(1 << 63)},
+				},
+			},
+			expectedSysUpTime: int64(500),
+			expectedBody: map[string]any{
+				bodySchemaVersion: int64(SchemaVersion),
+				bodyPDUType:       "SNMPv2Trap",
+				bodyVersion:       "v2c",
+				bodyCommunity:     "private",
+				bodyRequestID:     int64(42),
+				bodyVarbinds: []any{
+					map[string]any{bodyVarbindOID: "1.3.6.1.2.1.1.3.0", bodyVarbindType: "TimeTicks", bodyVarbindValue: int64(500)},
+					map[string]any{bodyVarbindOID: "1.3.6.1.6.3.1.1.4.1.0", bodyVarbindType: "ObjectIdentifier", bodyVarbindValue: "1.3.6.1.6.3.1.1.5.3"},
+					map[string]any{bodyVarbindOID: "1.3.6.1.2.1.2.2.1.2.3", bodyVarbindType: "OctetString", bodyVarbindValue: []byte("eth0")},
+					map[string]any{bodyVarbindOID: "1.3.6.1.2.1.31.1.1.1.6.3", bodyVarbindType: "Counter64", bodyVarbindValue: "9223372036854775808"},
+				},
+			},
+		},
+		{
+			name: "V3Inform",
+			packet: &gosnmp.SnmpPacket{
+				Version:            gosnmp.Version3,
+				PDUType:            gosnmp.InformRequest,
+				MsgID:              3,
+				MsgFlags:           gosnmp.AuthNoPriv | gosnmp.Reportable,
+				SecurityModel:      gosnmp.UserSecurityModel,
+				RequestID:          7,
+				ContextName:        "ctx",
+				SecurityParameters: &gosnmp.UsmSecurityParameters{UserName: "otel", AuthoritativeEngineID: "\x80\x00\x1f\x88\x04"},
+			},
+			expectedIsInform: true,
+			expectedBody: map[string]any{
+				bodySchemaVersion:         int64(SchemaVersion),
+				bodyPDUType:               "InformRequest",
+				bodyVersion:               "v3",
+				bodyMsgID:                 int64(3),
+				bodyMsgFlags:              int64(5),
+				bodySecurityModel:         int64(3),
+				bodyUser:                  "otel",
+				bodyAuthoritativeEngineID: []byte{0x80, 0x00, 0x1f, 0x88, 0x04},
+				bodyEngineBoots:           int64(0),
+				bodyEngineTime:            int64(0),
+				bodyContextEngineID:       []byte(nil),
+				bodyContextName:           "ctx",
+				bodyRequestID:             int64(7),
+				bodyVarbinds:              []any{},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			received := time.Unix(1700000000, 0)
+			addr := &net.UDPAddr{IP: net.ParseIP("192.0.2.10"), Port: 4567}
+			local := &net.UDPAddr{IP: net.ParseIP("192.0.2.1"), Port: 162}
+
+			logs := trapToLogs(test.packet, addr, local, received)
+			require.Equal(t, 1, logs.LogRecordCount())
+
+			logRecord := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+			require.Equal(t, pcommon.NewTimestampFromTime(received), logRecord.Timestamp())
+			require.Equal(t, logRecord.Timestamp(), logRecord.ObservedTimestamp())
+			expectedAttributes := map[string]any{
+				attributeNetTransport:    "ip_udp",
+				attributeNetSockPeerAddr: "192.0.2.10",
+				attributeNetSockPeerPort: int64(4567),
+				attributeNetSockHostAddr: "192.0.2.1",
+				attributeNetSockHostPort: int64(162),
+				attributeSNMPIsInform:    test.expectedIsInform,
+			}
+			if test.expectedSysUpTime != nil {
+				expectedAttributes[attributeSNMPSysUpTime] = test.expectedSysUpTime
+			}
+			require.Equal(t, expectedAttributes, logRecord.Attributes().AsRaw())
+			require.Equal(t, test.expectedBody, logRecord.Body().Map().AsRaw())
+		})
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package snmptrapreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver"
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// informKey identifies the retransmissions of an inform
+type informKey struct {
+	source    string
+	version   gosnmp.SnmpVersion
+	principal string
+	requestID uint32
+}
+
+// newInformKey returns the key of an inform received from source
+func newInformKey(source string, packet *gosnmp.SnmpPacket) informKey {
+	key := informKey{source: source, version: packet.Version, principal: packet.Community, requestID: packet.RequestID}
+	if securityParameters, ok := packet.SecurityParameters.(*gosnmp.UsmSecurityParameters); ok {
+		key.principal = securityParameters.UserName
+	}
+	return key
+}
+
+// informState is what is known about an inform that was received recently
+type informState struct {
+	received     time.Time
+	acknowledged bool
+}
+
+// informDeduplicator remembers the informs received within a window, so that a sender
+// retransmitting an inform, because its response was lost or came too late, doesn't
+// cause the inform to be logged twice
+type informDeduplicator struct {
+	window time.Duration
+
+	mu        sync.Mutex
+	informs   map[informKey]*informState
+	lastPurge time.Time
+}
+
+// newInformDeduplicator creates an informDeduplicator. A window of zero disables deduplication.
+func newInformDeduplicator(window time.Duration) *informDeduplicator {
+	return &informDeduplicator{window: window, informs: map[informKey]*informState{}}
+}
+
+// begin records an inform received at now. It returns whether the inform is a
+// retransmission and, if so, whether the original was acknowledged already.
+func (d *informDeduplicator) begin(key informKey, now time.Time) (duplicate bool, acknowledged bool) {
+	if d.window <= 0 {
+		return false, false
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if now.Sub(d.lastPurge) >= d.window {
+		for k, state := range d.informs {
+			if now.Sub(state.received) >= d.window {
+				delete(d.informs, k)
+			}
+		}
+		d.lastPurge = now
+	}
+
+	if state, ok := d.informs[key]; ok && now.Sub(state.received) < d.window {
+		return true, state.acknowledged
+	}
+	d.informs[key] = &informState{received: now}
+	return false, false
+}
+
+// acknowledge marks an inform as acknowledged, so that retransmissions are acknowledged too
+func (d *informDeduplicator) acknowledge(key informKey) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if state, ok := d.informs[key]; ok {
+		state.acknowledged = true
+	}
+}
+
+// forget removes an inform that couldn't be handled, so that a retransmission is handled again
+func (d *informDeduplicator) forget(key informKey) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delete(d.informs, key)
+}
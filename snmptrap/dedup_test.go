@@ -0,0 +1,72 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package snmptrapreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver"
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInformDeduplicator(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	inform := &gosnmp.SnmpPacket{Version: gosnmp.Version2c, Community: "public", PDUType: gosnmp.InformRequest, RequestID: 7}
+	key := newInformKey("192.0.2.1", inform)
+
+	d := newInformDeduplicator(30 * time.Second)
+
+	duplicate, _ := d.begin(key, now)
+	require.False(t, duplicate)
+
+	// A retransmission while the original is being handled isn't acknowledged yet
+	duplicate, acknowledged := d.begin(key, now.Add(time.Second))
+	require.True(t, duplicate)
+	require.False(t, acknowledged)
+
+	d.acknowledge(key)
+	duplicate, acknowledged = d.begin(key, now.Add(2*time.Second))
+	require.True(t, duplicate)
+	require.True(t, acknowledged)
+
+	// The same request ID from another source or community is another inform
+	duplicate, _ = d.begin(newInformKey("192.0.2.2", inform), now)
+	require.False(t, duplicate)
+	other := *inform
+	other.Community = "private"
+	duplicate, _ = d.begin(newInformKey("192.0.2.1", &other), now)
+	require.False(t, duplicate)
+
+	// Once the window has passed, the inform is handled again and old entries are purged
+	duplicate, _ = d.begin(key, now.Add(31*time.Second))
+	require.False(t, duplicate)
+	require.Len(t, d.informs, 1)
+
+	// An inform that failed is forgotten, so that its retransmission is handled again
+	d.forget(key)
+	duplicate, _ = d.begin(key, now.Add(32*time.Second))
+	require.False(t, duplicate)
+}
+
+func TestInformDeduplicatorDisabled(t *testing.T) {
+	inform := &gosnmp.SnmpPacket{Version: gosnmp.Version2c, PDUType: gosnmp.InformRequest, RequestID: 7}
+	key := newInformKey("192.0.2.1", inform)
+
+	d := newInformDeduplicator(0)
+	for i := 0; i < 2; i++ {
+		duplicate, _ := d.begin(key, time.Now())
+		require.False(t, duplicate)
+	}
+}
+
+func TestInformKeyUsesV3User(t *testing.T) {
+	inform := &gosnmp.SnmpPacket{
+		Version:            gosnmp.Version3,
+		PDUType:            gosnmp.InformRequest,
+		RequestID:          7,
+		SecurityParameters: &gosnmp.UsmSecurityParameters{UserName: "otel"},
+	}
+	require.Equal(t, informKey{source: "192.0.2.1", version: gosnmp.Version3, principal: "otel", requestID: 7}, newInformKey("192.0.2.1", inform))
+}
@@ -0,0 +1,416 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package snmptrapreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver"
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"sync/atomic"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+const (
+	// USM statistics sent back in Report PDUs, from SNMP-USER-BASED-SM-MIB
+	oidUsmStatsNotInTimeWindows = ".1.3.6.1.6.3.15.1.1.2.0"
+	oidUsmStatsUnknownEngineIDs = ".1.3.6.1.6.3.15.1.1.4.0"
+
+	// timeWindow is how many seconds the engine time of a message may be off, RFC 3414 section 2.2.3
+	timeWindow = 150
+
+	minEngineIDLength = 5
+	maxEngineIDLength = 32
+
+	berInteger     = 0x02
+	berOctetString = 0x04
+	berSequence    = 0x30
+)
+
+var (
+	errNotV3                    = errors.New("not an SNMPv3 message")
+	errV3NotConfigured          = errors.New("SNMPv3 message received on a listener not configured for v3")
+	errUnknownSecurityModel     = errors.New("unsupported SNMPv3 security model")
+	errUnknownEngineID          = errors.New("message is not addressed to the engine ID of the receiver")
+	errNotInTimeWindow          = errors.New("inform is outside of the time window of the receiver")
+	errUnknownUserName          = errors.New("unknown SNMPv3 user")
+	errUnsupportedSecurityLevel = errors.New("SNMPv3 message is below the configured security level")
+	errWrongDigest              = errors.New("SNMPv3 message has an authentication digest of the wrong length")
+)
+
+// digestLengths is the length of msgAuthenticationParameters for each authentication protocol
+var digestLengths = map[gosnmp.SnmpV3AuthProtocol]int{
+	gosnmp.MD5:    12,
+	gosnmp.SHA:    12,
+	gosnmp.SHA224: 16,
+	gosnmp.SHA256: 24,
+	gosnmp.SHA384: 32,
+	gosnmp.SHA512: 48,
+}
+
+// snmpEngine is the local SNMPv3 engine of the receiver. Senders of traps are the
+// authoritative engine for their own messages, but for informs the receiver is the
+// authoritative engine (RFC 3414 section 1.5.1), so senders first discover its engine
+// ID, boots and time and then localize their keys with its engine ID.
+type snmpEngine struct {
+	id string
+	// boots is taken from the start time, so that it increases across restarts
+	// without having to persist it
+	boots uint32
+	start time.Time
+
+	unknownEngineIDs atomic.Uint32
+	notInTimeWindows atomic.Uint32
+}
+
+// newSNMPEngine creates the local engine with the given hex encoded engine ID, or a
+// random one when it is empty
+func newSNMPEngine(engineID string) (*snmpEngine, error) {
+	id, err := parseEngineID(engineID)
+	if err != nil {
+		return nil, err
+	}
+	if id == "" {
+		// RFC 3411 format with the enterprise number left at zero, since the receiver
+		// has none of its own, followed by random octets (format 5)
+		random := make([]byte, 8)
+		if _, err = rand.Read(random); err != nil {
+			return nil, fmt.Errorf("failed to generate an engine ID: %w", err)
+		}
+		id = string(append([]byte{0x80, 0x00, 0x00, 0x00, 0x05}, random...))
+	}
+
+	start := time.Now()
+	boots := uint32(math.MaxInt32 - 1)
+	if unix := start.Unix(); unix > 0 && unix < math.MaxInt32 {
+		boots = uint32(unix)
+	}
+
+	return &snmpEngine{id: id, boots: boots, start: start}, nil
+}
+
+// parseEngineID decodes a hex encoded engine ID, which may be empty
+func parseEngineID(engineID string) (string, error) {
+	id, err := hex.DecodeString(engineID)
+	if err != nil || (len(id) > 0 && (len(id) < minEngineIDLength || len(id) > maxEngineIDLength)) {
+		return "", errBadEngineID
+	}
+	return string(id), nil
+}
+
+// time returns snmpEngineTime, the number of seconds since boots was last changed
+func (e *snmpEngine) time() uint32 {
+	return uint32(time.Since(e.start) / time.Second)
+}
+
+// inTimeWindow reports whether the boots and time of a message are close enough to those of the engine
+func (e *snmpEngine) inTimeWindow(boots uint32, engineTime uint32) bool {
+	if boots != e.boots || boots == math.MaxInt32 {
+		return false
+	}
+	diff := int64(engineTime) - int64(e.time())
+	return diff >= -timeWindow && diff <= timeWindow
+}
+
+// unmarshal decodes a message, applying the checks of RFC 3414 section 3.2 to SNMPv3
+// messages with the users of the listener. Discovery requests, and informs not addressed to
+// the engine or outside of its time window, are answered with a Report PDU through reply so
+// that the sender can synchronise with the engine and send them again.
+func (e *snmpEngine) unmarshal(unmarshaller *gosnmp.GoSNMP, users *usmUsers, message []byte, reply func([]byte) error) (*gosnmp.SnmpPacket, error) {
+	header, err := parseV3Header(message)
+	if errors.Is(err, errNotV3) {
+		return unmarshaller.UnmarshalTrap(message, false)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if unmarshaller.Version != gosnmp.Version3 || users == nil {
+		return nil, errV3NotConfigured
+	}
+	if header.securityModel != gosnmp.UserSecurityModel {
+		return nil, errUnknownSecurityModel
+	}
+
+	// Requests to discover the engine are reportable and carry no engine ID. Other messages
+	// with a foreign engine ID may be traps, which some agents wrongly flag as reportable,
+	// sent by their authoritative engine: they are decoded with the keys of that engine, and
+	// only the informs among them are reported below.
+	if header.msgFlags&gosnmp.Reportable != 0 && header.engineID == "" {
+		var requestID uint32
+		discovery := newV3Unmarshaller(gosnmp.NoAuthNoPriv, &gosnmp.UsmSecurityParameters{UserName: header.userName})
+		if packet, err := discovery.UnmarshalTrap(message, true); err == nil {
+			requestID = packet.RequestID
+		}
+		return nil, e.reportUnknownEngineID(reply, header, requestID)
+	}
+
+	user := users.lookup(header.userName, header.engineID)
+	if user == nil {
+		return nil, errUnknownUserName
+	}
+	if err = user.checkSecurity(header); err != nil {
+		return nil, err
+	}
+	securityParameters, err := users.localize(user, header.engineID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The packet's own flags must be used, they were checked against the user's above
+	packet, err := newV3Unmarshaller(user.msgFlags, securityParameters).UnmarshalTrap(message, true)
+	if err != nil {
+		return nil, err
+	}
+
+	// The receiver is the authoritative engine of informs. Informs are answered even
+	// when the sender didn't set the reportable flag, as gosnmp clears it.
+	if packet.PDUType == gosnmp.InformRequest {
+		if header.engineID != e.id {
+			return nil, e.reportUnknownEngineID(reply, header, packet.RequestID)
+		}
+		if header.msgFlags&gosnmp.AuthNoPriv != 0 && !e.inTimeWindow(header.engineBoots, header.engineTime) {
+			securityParameters, ok := packet.SecurityParameters.Copy().(*gosnmp.UsmSecurityParameters)
+			if !ok {
+				return nil, errUnknownSecurityModel
+			}
+			count := e.notInTimeWindows.Add(1)
+			return nil, errors.Join(errNotInTimeWindow, e.report(reply, header, packet.RequestID, oidUsmStatsNotInTimeWindows, count, securityParameters, gosnmp.AuthNoPriv))
+		}
+	}
+
+	return packet, nil
+}
+
+// newV3Unmarshaller creates the gosnmp instance decoding an SNMPv3 message with the given security parameters
+func newV3Unmarshaller(msgFlags gosnmp.SnmpV3MsgFlags, securityParameters *gosnmp.UsmSecurityParameters) *gosnmp.GoSNMP {
+	return &gosnmp.GoSNMP{
+		Version:            gosnmp.Version3,
+		SecurityModel:      gosnmp.UserSecurityModel,
+		MsgFlags:           msgFlags,
+		SecurityParameters: securityParameters,
+		MaxOids:            gosnmp.Default.MaxOids,
+	}
+}
+
+// reportUnknownEngineID tells the sender of a message the engine ID, boots and time of the engine
+func (e *snmpEngine) reportUnknownEngineID(reply func([]byte) error, header *v3Header, requestID uint32) error {
+	securityParameters := &gosnmp.UsmSecurityParameters{
+		UserName:               header.userName,
+		AuthenticationProtocol: gosnmp.NoAuth,
+		PrivacyProtocol:        gosnmp.NoPriv,
+	}
+	count := e.unknownEngineIDs.Add(1)
+	return errors.Join(errUnknownEngineID, e.report(reply, header, requestID, oidUsmStatsUnknownEngineIDs, count, securityParameters, gosnmp.NoAuthNoPriv))
+}
+
+// report sends a Report PDU holding a single USM statistic back to the sender of a message
+func (e *snmpEngine) report(reply func([]byte) error, header *v3Header, requestID uint32, oid string, count uint32, securityParameters *gosnmp.UsmSecurityParameters, msgFlags gosnmp.SnmpV3MsgFlags) error {
+	securityParameters.AuthoritativeEngineID = e.id
+	securityParameters.AuthoritativeEngineBoots = e.boots
+	securityParameters.AuthoritativeEngineTime = e.time()
+	securityParameters.AuthenticationParameters = ""
+	securityParameters.PrivacyParameters = nil
+
+	packet := &gosnmp.SnmpPacket{
+		Version:            gosnmp.Version3,
+		MsgFlags:           msgFlags,
+		SecurityModel:      gosnmp.UserSecurityModel,
+		SecurityParameters: securityParameters,
+		MsgID:              header.msgID,
+		ContextEngineID:    e.id,
+		PDUType:            gosnmp.Report,
+		RequestID:          requestID,
+		Variables:          []gosnmp.SnmpPDU{{Name: oid, Type: gosnmp.Counter32, Value: count}},
+	}
+
+	message, err := packet.MarshalMsg()
+	if err != nil {
+		return fmt.Errorf("failed to marshal report: %w", err)
+	}
+	return reply(message)
+}
+
+// informResponse builds the GetResponse PDU that acknowledges an inform. For SNMPv3 it
+// is secured with the keys the inform was received with and the engine's boots and time.
+func (e *snmpEngine) informResponse(packet *gosnmp.SnmpPacket) ([]byte, error) {
+	response := *packet
+	response.PDUType = gosnmp.GetResponse
+	response.Error = gosnmp.NoError
+	response.ErrorIndex = 0
+
+	if response.Version == gosnmp.Version3 {
+		securityParameters, ok := packet.SecurityParameters.Copy().(*gosnmp.UsmSecurityParameters)
+		if !ok {
+			return nil, errUnknownSecurityModel
+		}
+		securityParameters.AuthoritativeEngineBoots = e.boots
+		securityParameters.AuthoritativeEngineTime = e.time()
+		securityParameters.AuthenticationParameters = ""
+		response.MsgFlags = packet.MsgFlags &^ gosnmp.Reportable
+		if response.MsgFlags&gosnmp.AuthPriv == gosnmp.AuthPriv {
+			if err := e.setPrivacySalt(securityParameters); err != nil {
+				return nil, err
+			}
+		}
+		response.SecurityParameters = securityParameters
+	}
+
+	return response.MarshalMsg()
+}
+
+// setPrivacySalt sets a fresh salt for encrypting a message, as described by RFC 3414
+// section 8.1.1.1 for DES and RFC 3826 section 3.1.2.1 for AES
+func (e *snmpEngine) setPrivacySalt(securityParameters *gosnmp.UsmSecurityParameters) error {
+	salt := make([]byte, 8)
+	if _, err := rand.Read(salt); err != nil {
+		return fmt.Errorf("failed to generate a privacy salt: %w", err)
+	}
+	if securityParameters.PrivacyProtocol == gosnmp.DES {
+		binary.BigEndian.PutUint32(salt, e.boots)
+	}
+	securityParameters.PrivacyParameters = salt
+	return nil
+}
+
+// v3Header holds the fields of an SNMPv3 message that come before the scoped PDU
+type v3Header struct {
+	msgID          uint32
+	msgFlags       gosnmp.SnmpV3MsgFlags
+	securityModel  gosnmp.SnmpV3SecurityModel
+	engineID       string
+	engineBoots    uint32
+	engineTime     uint32
+	userName       string
+	authParameters []byte
+}
+
+// parseV3Header reads the header of an SNMPv3 message. errNotV3 is returned for
+// messages of other versions.
+func parseV3Header(message []byte) (*v3Header, error) {
+	reader := berReader(message)
+	body, err := reader.read(berSequence)
+	if err != nil {
+		return nil, err
+	}
+
+	reader = body
+	version, err := reader.readUint32()
+	if err != nil {
+		return nil, err
+	}
+	if gosnmp.SnmpVersion(version) != gosnmp.Version3 {
+		return nil, errNotV3
+	}
+
+	header := &v3Header{}
+	globalData, err := reader.read(berSequence)
+	if err != nil {
+		return nil, err
+	}
+	if header.msgID, err = globalData.readUint32(); err != nil {
+		return nil, err
+	}
+	if _, err = globalData.readUint32(); err != nil { // msgMaxSize
+		return nil, err
+	}
+	flags, err := globalData.read(berOctetString)
+	if err != nil {
+		return nil, err
+	}
+	if len(flags) != 1 {
+		return nil, errors.New("msgFlags must be a single octet")
+	}
+	header.msgFlags = gosnmp.SnmpV3MsgFlags(flags[0])
+	securityModel, err := globalData.readUint32()
+	if err != nil {
+		return nil, err
+	}
+	header.securityModel = gosnmp.SnmpV3SecurityModel(securityModel)
+	if header.securityModel != gosnmp.UserSecurityModel {
+		return header, nil
+	}
+
+	securityParameters, err := reader.read(berOctetString)
+	if err != nil {
+		return nil, err
+	}
+	usm, err := securityParameters.read(berSequence)
+	if err != nil {
+		return nil, err
+	}
+	engineID, err := usm.read(berOctetString)
+	if err != nil {
+		return nil, err
+	}
+	header.engineID = string(engineID)
+	if header.engineBoots, err = usm.readUint32(); err != nil {
+		return nil, err
+	}
+	if header.engineTime, err = usm.readUint32(); err != nil {
+		return nil, err
+	}
+	userName, err := usm.read(berOctetString)
+	if err != nil {
+		return nil, err
+	}
+	header.userName = string(userName)
+	if header.authParameters, err = usm.read(berOctetString); err != nil {
+		return nil, err
+	}
+
+	return header, nil
+}
+
+// berReader reads definite length BER encoded fields one after the other
+type berReader []byte
+
+// read returns the contents of the next field, which must have the given tag
+func (r *berReader) read(tag byte) (berReader, error) {
+	data := *r
+	if len(data) < 2 {
+		return nil, errors.New("truncated BER field")
+	}
+	if data[0] != tag {
+		return nil, fmt.Errorf("expected BER tag 0x%02x, got 0x%02x", tag, data[0])
+	}
+
+	length, offset := int(data[1]), 2
+	if data[1]&0x80 != 0 {
+		octets := int(data[1] & 0x7f)
+		if octets == 0 || octets > 4 || len(data) < 2+octets {
+			return nil, errors.New("bad BER length")
+		}
+		length = 0
+		for _, b := range data[2 : 2+octets] {
+			length = length<<8 | int(b)
+		}
+		offset += octets
+	}
+	if length < 0 || len(data)-offset < length {
+		return nil, errors.New("truncated BER field")
+	}
+
+	*r = data[offset+length:]
+	return data[offset : offset+length], nil
+}
+
+// readUint32 reads a non-negative INTEGER that fits in 32 bits
+func (r *berReader) readUint32() (uint32, error) {
+	value, err := r.read(berInteger)
+	if err != nil {
+		return 0, err
+	}
+	if len(value) == 0 || len(value) > 5 || value[0]&0x80 != 0 || (len(value) == 5 && value[0] != 0) {
+		return 0, errors.New("BER integer out of range")
+	}
+	var n uint32
+	for _, b := range value {
+		n = n<<8 | uint32(b)
+	}
+	return n, nil
+}
@@ -0,0 +1,434 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package snmptrapreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver"
+
+import (
+	"context"
+	"encoding/hex"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+)
+
+const testEngineID = "80001f8880e9630000d61ff449"
+
+// startV3Receiver starts a receiver on a free UDP port for the given v3 user
+func startV3Receiver(t *testing.T, securityLevel, authType, privacyType string) (*snmptrapReceiver, *consumertest.LogsSink) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.ListenAddress = "udp://127.0.0.1:0"
+	cfg.Version = "v3"
+	cfg.User = "otel"
+	cfg.SecurityLevel = securityLevel
+	cfg.AuthType = authType
+	cfg.AuthPassword = "authpassword"
+	cfg.PrivacyType = privacyType
+	cfg.PrivacyPassword = "privpassword"
+	cfg.EngineID = testEngineID
+	require.NoError(t, cfg.Validate())
+
+	sink := new(consumertest.LogsSink)
+	rcvr, err := newSnmptrapReceiver(receivertest.NewNopCreateSettings(), cfg, sink)
+	require.NoError(t, err)
+	require.NoError(t, rcvr.Start(context.Background(), componenttest.NewNopHost()))
+	t.Cleanup(func() {
+		require.NoError(t, rcvr.Shutdown(context.Background()))
+	})
+	return rcvr, sink
+}
+
+// newV3Client creates a gosnmp client sending to the receiver as the given user
+func newV3Client(t *testing.T, rcvr *snmptrapReceiver, msgFlags gosnmp.SnmpV3MsgFlags, securityParameters *gosnmp.UsmSecurityParameters) *gosnmp.GoSNMP {
+	_, port := splitAddr(rcvr.listeners[0].localAddr())
+	client := &gosnmp.GoSNMP{
+		Target:             "127.0.0.1",
+		Port:               uint16(port),
+		Transport:          "udp",
+		Version:            gosnmp.Version3,
+		SecurityModel:      gosnmp.UserSecurityModel,
+		MsgFlags:           msgFlags,
+		SecurityParameters: securityParameters,
+		Timeout:            time.Second,
+		Retries:            1,
+		MaxOids:            gosnmp.MaxOids,
+	}
+	require.NoError(t, client.Connect())
+	t.Cleanup(func() {
+		client.Conn.Close()
+	})
+	return client
+}
+
+var testNotification = gosnmp.SnmpTrap{
+	Variables: []gosnmp.SnmpPDU{
+		{Name: ".1.3.6.1.2.1.1.3.0", Type: gosnmp.TimeTicks, Value: uint32(100)},
+		{Name: ".1.3.6.1.6.3.1.1.4.1.0", Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.6.3.1.1.5.3"},
+	},
+}
+
+func TestReceiveV3Notifications(t *testing.T) {
+	type testCase struct {
+		name          string
+		securityLevel string
+		authType      string
+		privacyType   string
+		msgFlags      gosnmp.SnmpV3MsgFlags
+		authProtocol  gosnmp.SnmpV3AuthProtocol
+		privProtocol  gosnmp.SnmpV3PrivProtocol
+		inform        bool
+	}
+
+	testCases := []testCase{
+		{name: "InformNoAuthNoPriv", securityLevel: "no_auth_no_priv", msgFlags: gosnmp.NoAuthNoPriv, authProtocol: gosnmp.NoAuth, privProtocol: gosnmp.NoPriv, inform: true},
+		{name: "InformAuthNoPrivMD5", securityLevel: "auth_no_priv", authType: "MD5", msgFlags: gosnmp.AuthNoPriv, authProtocol: gosnmp.MD5, privProtocol: gosnmp.NoPriv, inform: true},
+		{name: "InformAuthNoPrivSHA256", securityLevel: "auth_no_priv", authType: "SHA256", msgFlags: gosnmp.AuthNoPriv, authProtocol: gosnmp.SHA256, privProtocol: gosnmp.NoPriv, inform: true},
+		{name: "InformAuthPrivDES", securityLevel: "auth_priv", authType: "SHA", privacyType: "DES", msgFlags: gosnmp.AuthPriv, authProtocol: gosnmp.SHA, privProtocol: gosnmp.DES, inform: true},
+		{name: "InformAuthPrivAES", securityLevel: "auth_priv", authType: "SHA", privacyType: "AES", msgFlags: gosnmp.AuthPriv, authProtocol: gosnmp.SHA, privProtocol: gosnmp.AES, inform: true},
+		{name: "InformAuthPrivAES256C", securityLevel: "auth_priv", authType: "SHA512", privacyType: "AES256C", msgFlags: gosnmp.AuthPriv, authProtocol: gosnmp.SHA512, privProtocol: gosnmp.AES256C, inform: true},
+		{name: "TrapAuthPrivAES", securityLevel: "auth_priv", authType: "SHA", privacyType: "AES", msgFlags: gosnmp.AuthPriv, authProtocol: gosnmp.SHA, privProtocol: gosnmp.AES},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			rcvr, sink := startV3Receiver(t, test.securityLevel, test.authType, test.privacyType)
+
+			securityParameters := &gosnmp.UsmSecurityParameters{
+				UserName:                 "otel",
+				AuthenticationProtocol:   test.authProtocol,
+				AuthenticationPassphrase: "authpassword",
+				PrivacyProtocol:          test.privProtocol,
+				PrivacyPassphrase:        "privpassword",
+			}
+			expectedEngineID := testEngineID
+			if !test.inform {
+				// The sender is the authoritative engine of a trap
+				expectedEngineID = "8000000001020304"
+				engineID, err := hex.DecodeString(expectedEngineID)
+				require.NoError(t, err)
+				securityParameters.AuthoritativeEngineID = string(engineID)
+			}
+			client := newV3Client(t, rcvr, test.msgFlags, securityParameters)
+
+			notification := testNotification
+			notification.IsInform = test.inform
+			result, err := client.SendTrap(notification)
+			require.NoError(t, err)
+			if test.inform {
+				require.Equal(t, gosnmp.GetResponse, result.PDUType)
+			}
+
+			require.Eventually(t, func() bool {
+				return sink.LogRecordCount() == 1
+			}, 5*time.Second, 10*time.Millisecond)
+
+			logRecord := sink.AllLogs()[0].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+			isInform, ok := logRecord.Attributes().Get(attributeSNMPIsInform)
+			require.True(t, ok)
+			require.Equal(t, test.inform, isInform.Bool())
+			engineID, ok := logRecord.Body().Map().Get(bodyAuthoritativeEngineID)
+			require.True(t, ok)
+			require.Equal(t, expectedEngineID, hex.EncodeToString(engineID.Bytes().AsRaw()))
+		})
+	}
+}
+
+// An inform with outdated engine boots and time gets a report, after which the sender retries
+func TestV3InformOutsideTimeWindow(t *testing.T) {
+	rcvr, sink := startV3Receiver(t, "auth_no_priv", "SHA", "")
+
+	engineID, err := hex.DecodeString(testEngineID)
+	require.NoError(t, err)
+	client := newV3Client(t, rcvr, gosnmp.AuthNoPriv, &gosnmp.UsmSecurityParameters{
+		UserName:                 "otel",
+		AuthenticationProtocol:   gosnmp.SHA,
+		AuthenticationPassphrase: "authpassword",
+		PrivacyProtocol:          gosnmp.NoPriv,
+		AuthoritativeEngineID:    string(engineID),
+		AuthoritativeEngineBoots: 1,
+		AuthoritativeEngineTime:  1,
+	})
+
+	notification := testNotification
+	notification.IsInform = true
+	result, err := client.SendTrap(notification)
+	require.NoError(t, err)
+	require.Equal(t, gosnmp.GetResponse, result.PDUType)
+	require.EqualValues(t, 1, rcvr.engine.notInTimeWindows.Load())
+
+	require.Eventually(t, func() bool {
+		return sink.LogRecordCount() == 1
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+// Traps wrongly flagged as reportable are from their authoritative sender, so they must be
+// received rather than answered with a report of an unknown engine ID
+func TestV3ReportableTrap(t *testing.T) {
+	type testCase struct {
+		name          string
+		securityLevel string
+		msgFlags      gosnmp.SnmpV3MsgFlags
+		authProtocol  gosnmp.SnmpV3AuthProtocol
+		privProtocol  gosnmp.SnmpV3PrivProtocol
+	}
+
+	testCases := []testCase{
+		{name: "NoAuthNoPriv", securityLevel: "no_auth_no_priv", msgFlags: gosnmp.NoAuthNoPriv, authProtocol: gosnmp.NoAuth, privProtocol: gosnmp.NoPriv},
+		{name: "AuthPriv", securityLevel: "auth_priv", msgFlags: gosnmp.AuthPriv, authProtocol: gosnmp.SHA, privProtocol: gosnmp.AES},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			rcvr, sink := startV3Receiver(t, test.securityLevel, "SHA", "AES")
+
+			securityParameters := &gosnmp.UsmSecurityParameters{
+				UserName:                 "otel",
+				AuthoritativeEngineID:    "\x80\x00\x00\x00\x01\x02\x03\x04",
+				AuthenticationProtocol:   test.authProtocol,
+				AuthenticationPassphrase: "authpassword",
+				PrivacyProtocol:          test.privProtocol,
+				PrivacyPassphrase:        "privpassword",
+			}
+			require.NoError(t, securityParameters.InitSecurityKeys())
+			packet := &gosnmp.SnmpPacket{
+				Version:            gosnmp.Version3,
+				MsgFlags:           test.msgFlags | gosnmp.Reportable,
+				SecurityModel:      gosnmp.UserSecurityModel,
+				SecurityParameters: securityParameters,
+				PDUType:            gosnmp.SNMPv2Trap,
+				MsgID:              1,
+				RequestID:          1,
+				Variables:          testNotification.Variables,
+			}
+			require.NoError(t, securityParameters.InitPacket(packet))
+			message, err := packet.MarshalMsg()
+			require.NoError(t, err)
+
+			conn, err := net.Dial("udp", rcvr.listeners[0].localAddr().String())
+			require.NoError(t, err)
+			defer conn.Close()
+			_, err = conn.Write(message)
+			require.NoError(t, err)
+
+			require.Eventually(t, func() bool {
+				return sink.LogRecordCount() == 1
+			}, 5*time.Second, 10*time.Millisecond)
+			require.Zero(t, rcvr.engine.unknownEngineIDs.Load())
+		})
+	}
+}
+
+// Messages that don't meet the configured security must be dropped
+func TestV3InsecureMessagesDropped(t *testing.T) {
+	type testCase struct {
+		name     string
+		msgFlags gosnmp.SnmpV3MsgFlags
+		user     string
+		auth     gosnmp.SnmpV3AuthProtocol
+		priv     gosnmp.SnmpV3PrivProtocol
+	}
+
+	testCases := []testCase{
+		{name: "BelowSecurityLevel", msgFlags: gosnmp.AuthNoPriv, user: "otel", auth: gosnmp.SHA, priv: gosnmp.NoPriv},
+		{name: "NoAuthentication", msgFlags: gosnmp.NoAuthNoPriv, user: "otel", auth: gosnmp.NoAuth, priv: gosnmp.NoPriv},
+		{name: "UnknownUser", msgFlags: gosnmp.AuthPriv, user: "intruder", auth: gosnmp.SHA, priv: gosnmp.AES},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			rcvr, sink := startV3Receiver(t, "auth_priv", "SHA", "AES")
+
+			client := newV3Client(t, rcvr, test.msgFlags, &gosnmp.UsmSecurityParameters{
+				UserName:                 test.user,
+				AuthenticationProtocol:   test.auth,
+				AuthenticationPassphrase: "authpassword",
+				PrivacyProtocol:          test.priv,
+				PrivacyPassphrase:        "privpassword",
+				AuthoritativeEngineID:    "\x80\x00\x00\x00\x01\x02\x03\x04",
+			})
+			client.Timeout = 200 * time.Millisecond
+			client.Retries = 0
+
+			_, err := client.SendTrap(testNotification)
+			require.NoError(t, err)
+
+			notification := testNotification
+			notification.IsInform = true
+			_, err = client.SendTrap(notification)
+			require.Error(t, err)
+
+			time.Sleep(100 * time.Millisecond)
+			require.Zero(t, sink.LogRecordCount())
+		})
+	}
+}
+
+func TestCheckSecurity(t *testing.T) {
+	users, err := newUSMUsers(&Config{
+		Version:       "v3",
+		User:          "otel",
+		SecurityLevel: "auth_no_priv",
+		AuthType:      "SHA256",
+		AuthPassword:  "authpassword",
+	})
+	require.NoError(t, err)
+	user := users.lookup("otel", "")
+	require.NotNil(t, user)
+
+	header := &v3Header{userName: "otel", msgFlags: gosnmp.AuthNoPriv, authParameters: make([]byte, 24)}
+	require.NoError(t, user.checkSecurity(header))
+
+	header.msgFlags = gosnmp.AuthPriv
+	require.NoError(t, user.checkSecurity(header))
+
+	header.authParameters = header.authParameters[:1]
+	require.ErrorIs(t, user.checkSecurity(header), errWrongDigest)
+
+	header.msgFlags = gosnmp.NoAuthNoPriv
+	require.ErrorIs(t, user.checkSecurity(header), errUnsupportedSecurityLevel)
+
+	require.Nil(t, users.lookup("other", ""))
+}
+
+func TestParseV3Header(t *testing.T) {
+	packet := &gosnmp.SnmpPacket{
+		Version:       gosnmp.Version3,
+		MsgFlags:      gosnmp.NoAuthNoPriv | gosnmp.Reportable,
+		SecurityModel: gosnmp.UserSecurityModel,
+		SecurityParameters: &gosnmp.UsmSecurityParameters{
+			UserName:                 "otel",
+			AuthoritativeEngineID:    "\x80\x00\x00\x00\x05engine",
+			AuthoritativeEngineBoots: 1700000000,
+			AuthoritativeEngineTime:  300,
+			AuthenticationProtocol:   gosnmp.NoAuth,
+			PrivacyProtocol:          gosnmp.NoPriv,
+		},
+		MsgID:     0x7fffffff,
+		PDUType:   gosnmp.InformRequest,
+		RequestID: 12,
+		Variables: testNotification.Variables,
+	}
+	message, err := packet.MarshalMsg()
+	require.NoError(t, err)
+
+	header, err := parseV3Header(message)
+	require.NoError(t, err)
+	require.Equal(t, &v3Header{
+		msgID:          0x7fffffff,
+		msgFlags:       gosnmp.NoAuthNoPriv | gosnmp.Reportable,
+		securityModel:  gosnmp.UserSecurityModel,
+		engineID:       "\x80\x00\x00\x00\x05engine",
+		engineBoots:    1700000000,
+		engineTime:     300,
+		userName:       "otel",
+		authParameters: []byte{},
+	}, header)
+
+	for i := range message {
+		_, err = parseV3Header(message[:i])
+		require.Error(t, err)
+	}
+
+	packet.Version = gosnmp.Version2c
+	message, err = packet.MarshalMsg()
+	require.NoError(t, err)
+	_, err = parseV3Header(message)
+	require.ErrorIs(t, err, errNotV3)
+}
+
+func TestEngineTimeWindow(t *testing.T) {
+	engine, err := newSNMPEngine("")
+	require.NoError(t, err)
+	require.Len(t, engine.id, 13)
+	require.NotZero(t, engine.boots)
+
+	require.True(t, engine.inTimeWindow(engine.boots, engine.time()))
+	require.True(t, engine.inTimeWindow(engine.boots, engine.time()+timeWindow))
+	require.False(t, engine.inTimeWindow(engine.boots, engine.time()+timeWindow+2))
+	require.False(t, engine.inTimeWindow(engine.boots-1, engine.time()))
+
+	_, err = newSNMPEngine("8000")
+	require.ErrorIs(t, err, errBadEngineID)
+	_, err = newSNMPEngine("not hex")
+	require.ErrorIs(t, err, errBadEngineID)
+}
+
+// An inform whose response was lost is acknowledged again when retransmitted, but logged once
+func TestInformRetransmissionsCollapsed(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.ListenAddress = "udp://127.0.0.1:0"
+
+	sink := new(consumertest.LogsSink)
+	rcvr, err := newSnmptrapReceiver(receivertest.NewNopCreateSettings(), cfg, sink)
+	require.NoError(t, err)
+	require.NoError(t, rcvr.Start(context.Background(), componenttest.NewNopHost()))
+	defer func() {
+		require.NoError(t, rcvr.Shutdown(context.Background()))
+	}()
+
+	inform := &gosnmp.SnmpPacket{
+		Version:   gosnmp.Version2c,
+		Community: "public",
+		PDUType:   gosnmp.InformRequest,
+		RequestID: 1234,
+		Variables: testNotification.Variables,
+	}
+	message, err := inform.MarshalMsg()
+	require.NoError(t, err)
+
+	conn, err := net.Dial("udp", rcvr.listeners[0].localAddr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	for i := 0; i < 3; i++ {
+		_, err = conn.Write(message)
+		require.NoError(t, err)
+
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+		buf := make([]byte, maxUDPMessageSize)
+		n, err := conn.Read(buf)
+		require.NoError(t, err)
+		response, err := (&gosnmp.GoSNMP{Version: gosnmp.Version2c}).SnmpDecodePacket(buf[:n])
+		require.NoError(t, err)
+		require.Equal(t, gosnmp.GetResponse, response.PDUType)
+		require.EqualValues(t, 1234, response.RequestID)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	require.Equal(t, 1, sink.LogRecordCount())
+}
+
+// An inform that can't be consumed must not be acknowledged, so that the sender retries it
+func TestInformNotAcknowledgedOnConsumerError(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.ListenAddress = "udp://127.0.0.1:0"
+
+	rcvr, err := newSnmptrapReceiver(receivertest.NewNopCreateSettings(), cfg, consumertest.NewErr(errEmptyVersion))
+	require.NoError(t, err)
+	require.NoError(t, rcvr.Start(context.Background(), componenttest.NewNopHost()))
+	defer func() {
+		require.NoError(t, rcvr.Shutdown(context.Background()))
+	}()
+
+	_, port := splitAddr(rcvr.listeners[0].localAddr())
+	client := &gosnmp.GoSNMP{
+		Target:    "127.0.0.1",
+		Port:      uint16(port),
+		Community: "public",
+		Version:   gosnmp.Version2c,
+		Timeout:   200 * time.Millisecond,
+		MaxOids:   gosnmp.MaxOids,
+	}
+	require.NoError(t, client.Connect())
+	defer client.Conn.Close()
+
+	notification := testNotification
+	notification.IsInform = true
+	_, err = client.SendTrap(notification)
+	require.Error(t, err)
+}
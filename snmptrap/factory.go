@@ -36,27 +36,47 @@ func createDefaultConfig() component.Config {
 		SecurityLevel: defaultSecurityLevel,
 		AuthType:      defaultAuthType,
 		PrivacyType:   defaultPrivacyType,
+		InformDeduplicationWindow: defaultInformDeduplicationWindow,
+		CommunityMode: defaultCommunityMode,
+		MIBReloadInterval: defaultMIBReloadInterval,
+		TCPIdleTimeout: defaultTCPIdleTimeout,
+		TCPMaxConnections: defaultTCPMaxConnections,
+		TCPMaxConnectionsPerPeer: defaultTCPMaxConnectionsPerPeer,
+		StandardMIBs: true,
 	}
 }
 
 // addMissingConfigDefaults adds any missing config parameters that have defaults
 func addMissingConfigDefaults(cfg *Config) error {
+	cfg.ListenAddress = addListenAddressDefaults(cfg.ListenAddress)
+	for i := range cfg.ListenAddresses {
+		// An empty address is reported by validation rather than defaulted
+		if cfg.ListenAddresses[i].Address != "" {
+			cfg.ListenAddresses[i].Address = addListenAddressDefaults(cfg.ListenAddresses[i].Address)
+		}
+	}
+
+	return component.ValidateConfig(cfg)
+}
+
+// addListenAddressDefaults adds the default scheme and port to a listen address missing them
+func addListenAddressDefaults(listenAddress string) string {
 	// Add the schema prefix to the listen address if it doesn't contain one
-	if !strings.Contains(cfg.ListenAddress, "://") {
-		cfg.ListenAddress = "udp://" + cfg.ListenAddress
+	if !strings.Contains(listenAddress, "://") {
+		listenAddress = "udp://" + listenAddress
 	}
 
 	// Add default port to listen address if it doesn't contain one
-	u, err := url.Parse(cfg.ListenAddress)
+	u, err := url.Parse(listenAddress)
 	if err == nil && u.Port() == "" {
 		portSuffix := "162"
-		if cfg.ListenAddress[len(cfg.ListenAddress)-1:] != ":" {
+		if listenAddress[len(listenAddress)-1:] != ":" {
 			portSuffix = ":" + portSuffix
 		}
-		cfg.ListenAddress += portSuffix
+		listenAddress += portSuffix
 	}
 
-	return component.ValidateConfig(cfg)
+	return listenAddress
 }
 
 // createLogsReceiver creates a logs receiver based on provided config.
@@ -76,6 +96,5 @@ func createLogsReceiver(
 		return nil, fmt.Errorf("failed to validate added config defaults: %w", err)
 	}
 
-	// FIXME: add in sane code here
-	return nil, nil
+	return newSnmptrapReceiver(params, snmpConfig, consumer)
 }
@@ -0,0 +1,115 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package snmptrapreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver"
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gosnmp/gosnmp"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+// Attributes set on the generic traps, which are recognized without any MIB
+const (
+	attributeEventName     = "event.name"
+	attributeIfIndex       = "snmp.if.index"
+	attributeIfAdminStatus = "snmp.if.admin_status"
+	attributeIfOperStatus  = "snmp.if.oper_status"
+)
+
+// Columns of the IF-MIB ifTable sent with linkDown and linkUp, RFC 2863
+const (
+	oidIfIndex       = ".1.3.6.1.2.1.2.2.1.1"
+	oidIfAdminStatus = ".1.3.6.1.2.1.2.2.1.7"
+	oidIfOperStatus  = ".1.3.6.1.2.1.2.2.1.8"
+)
+
+// genericTrapNames are the names of the generic traps of RFC 1157, in the order of their
+// generic-trap numbers. Their snmpTrapOID is snmpTraps followed by the number plus one (RFC 3418).
+var genericTrapNames = []string{
+	"coldStart",
+	"warmStart",
+	"linkDown",
+	"linkUp",
+	"authenticationFailure",
+	"egpNeighborLoss",
+}
+
+// ifAdminStatusNames and ifOperStatusNames are the enumerations of ifAdminStatus and ifOperStatus
+var (
+	ifAdminStatusNames = map[int64]string{1: "up", 2: "down", 3: "testing"}
+	ifOperStatusNames  = map[int64]string{1: "up", 2: "down", 3: "testing", 4: "unknown", 5: "dormant", 6: "notPresent", 7: "lowerLayerDown"}
+)
+
+// genericTrap returns the generic-trap number of a notification whatever its version,
+// or -1 when it isn't one of the generic traps
+func genericTrap(packet *gosnmp.SnmpPacket) int {
+	suffix, ok := strings.CutPrefix(trapOID(packet), oidSnmpTraps+".")
+	if !ok {
+		return -1
+	}
+	number, err := strconv.Atoi(suffix)
+	if err != nil || number < 1 || number > len(genericTrapNames) {
+		return -1
+	}
+	return number - 1
+}
+
+// putGenericTrapAttributes names the generic traps with event.name. The interface of
+// linkDown and linkUp is recorded with the ifIndex, ifAdminStatus and ifOperStatus varbinds.
+func putGenericTrapAttributes(attributes pcommon.Map, packet *gosnmp.SnmpPacket) {
+	generic := genericTrap(packet)
+	if generic < 0 {
+		return
+	}
+	name := genericTrapNames[generic]
+	attributes.PutStr(attributeEventName, name)
+
+	if name != "linkDown" && name != "linkUp" {
+		return
+	}
+	for _, variable := range packet.Variables {
+		column, index, ok := splitIfTableOID(variable.Name)
+		if !ok || variable.Type != gosnmp.Integer {
+			continue
+		}
+		value := gosnmp.ToBigInt(variable.Value).Int64()
+		switch column {
+		case oidIfIndex:
+			attributes.PutInt(attributeIfIndex, value)
+		case oidIfAdminStatus:
+			attributes.PutInt(attributeIfIndex, index)
+			putEnum(attributes, attributeIfAdminStatus, ifAdminStatusNames, value)
+		case oidIfOperStatus:
+			attributes.PutInt(attributeIfIndex, index)
+			putEnum(attributes, attributeIfOperStatus, ifOperStatusNames, value)
+		}
+	}
+}
+
+// splitIfTableOID splits the OID of an ifIndex, ifAdminStatus or ifOperStatus instance into its column and ifIndex
+func splitIfTableOID(oid string) (column string, index int64, ok bool) {
+	for _, column := range []string{oidIfIndex, oidIfAdminStatus, oidIfOperStatus} {
+		suffix, found := strings.CutPrefix(oid, column+".")
+		if !found {
+			continue
+		}
+		index, err := strconv.ParseInt(suffix, 10, 32)
+		if err != nil {
+			return "", 0, false
+		}
+		return column, index, true
+	}
+	return "", 0, false
+}
+
+// putEnum records an enumerated value by its name, or by its number when it has no name
+func putEnum(attributes pcommon.Map, key string, names map[int64]string, value int64) {
+	if name, ok := names[value]; ok {
+		attributes.PutStr(key, name)
+		return
+	}
+	attributes.PutStr(key, strconv.FormatInt(value, 10))
+}
@@ -0,0 +1,105 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package snmptrapreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver"
+
+import (
+	"testing"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+func TestPutGenericTrapAttributes(t *testing.T) {
+	v2 := func(trapOID string, variables ...gosnmp.SnmpPDU) *gosnmp.SnmpPacket {
+		return &gosnmp.SnmpPacket{
+			Version: gosnmp.Version2c,
+			PDUType: gosnmp.SNMPv2Trap,
+			Variables: append([]gosnmp.SnmpPDU{
+				{Name: oidSysUpTime, Type: gosnmp.TimeTicks, Value: uint32(100)},
+				{Name: oidSnmpTrapOID, Type: gosnmp.ObjectIdentifier, Value: trapOID},
+			}, variables...),
+		}
+	}
+	v1 := func(generic int, variables ...gosnmp.SnmpPDU) *gosnmp.SnmpPacket {
+		return &gosnmp.SnmpPacket{
+			Version:   gosnmp.Version1,
+			PDUType:   gosnmp.Trap,
+			Variables: variables,
+			SnmpTrap:  gosnmp.SnmpTrap{Enterprise: ".1.3.6.1.4.1.8072", GenericTrap: generic, SpecificTrap: 1},
+		}
+	}
+
+	type testCase struct {
+		name     string
+		packet   *gosnmp.SnmpPacket
+		expected map[string]any
+	}
+
+	testCases := []testCase{
+		{name: "V1ColdStart", packet: v1(0), expected: map[string]any{attributeEventName: "coldStart"}},
+		{name: "V1WarmStart", packet: v1(1), expected: map[string]any{attributeEventName: "warmStart"}},
+		{name: "V1AuthenticationFailure", packet: v1(4), expected: map[string]any{attributeEventName: "authenticationFailure"}},
+		{name: "V1EgpNeighborLoss", packet: v1(5), expected: map[string]any{attributeEventName: "egpNeighborLoss"}},
+		{name: "V1EnterpriseSpecific", packet: v1(6), expected: map[string]any{}},
+		{
+			name:     "V1LinkDown",
+			packet:   v1(2, gosnmp.SnmpPDU{Name: oidIfIndex + ".3", Type: gosnmp.Integer, Value: 3}),
+			expected: map[string]any{attributeEventName: "linkDown", attributeIfIndex: int64(3)},
+		},
+		{name: "V2ColdStart", packet: v2(".1.3.6.1.6.3.1.1.5.1"), expected: map[string]any{attributeEventName: "coldStart"}},
+		{
+			name: "V2LinkUp",
+			packet: v2(".1.3.6.1.6.3.1.1.5.4",
+				gosnmp.SnmpPDU{Name: oidIfIndex + ".12", Type: gosnmp.Integer, Value: 12},
+				gosnmp.SnmpPDU{Name: oidIfAdminStatus + ".12", Type: gosnmp.Integer, Value: 1},
+				gosnmp.SnmpPDU{Name: oidIfOperStatus + ".12", Type: gosnmp.Integer, Value: 7},
+			),
+			expected: map[string]any{
+				attributeEventName:     "linkUp",
+				attributeIfIndex:       int64(12),
+				attributeIfAdminStatus: "up",
+				attributeIfOperStatus:  "lowerLayerDown",
+			},
+		},
+		{
+			// The index is taken from the instance when the ifIndex varbind is missing
+			name: "V2LinkDownWithoutIfIndex",
+			packet: v2(".1.3.6.1.6.3.1.1.5.3",
+				gosnmp.SnmpPDU{Name: oidIfAdminStatus + ".5", Type: gosnmp.Integer, Value: 2},
+				gosnmp.SnmpPDU{Name: oidIfOperStatus + ".5", Type: gosnmp.Integer, Value: 42},
+			),
+			expected: map[string]any{
+				attributeEventName:     "linkDown",
+				attributeIfIndex:       int64(5),
+				attributeIfAdminStatus: "down",
+				attributeIfOperStatus:  "42",
+			},
+		},
+		{
+			name:     "V2OtherTrap",
+			packet:   v2(".1.3.6.1.4.1.8072.4.0.2", gosnmp.SnmpPDU{Name: oidIfIndex + ".1", Type: gosnmp.Integer, Value: 1}),
+			expected: map[string]any{},
+		},
+		{name: "V2BeyondGenericTraps", packet: v2(".1.3.6.1.6.3.1.1.5.7"), expected: map[string]any{}},
+		{name: "V2NoTrapOID", packet: &gosnmp.SnmpPacket{Version: gosnmp.Version2c, PDUType: gosnmp.SNMPv2Trap}, expected: map[string]any{}},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			attributes := pcommon.NewMap()
+			putGenericTrapAttributes(attributes, test.packet)
+			require.Equal(t, test.expected, attributes.AsRaw())
+		})
+	}
+}
+
+// A v1 generic trap and its v2 counterpart are named the same way
+func TestGenericTrapMatchesNormalizedV1Trap(t *testing.T) {
+	for generic := range genericTrapNames {
+		packet := &gosnmp.SnmpPacket{Version: gosnmp.Version1, PDUType: gosnmp.Trap, SnmpTrap: gosnmp.SnmpTrap{GenericTrap: generic}}
+		require.Equal(t, generic, genericTrap(packet))
+		require.Equal(t, generic, genericTrap(normalizeV1Trap(packet)))
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package mib // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver/internal/mib"
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// compiledMagic identifies compiled MIB files
+const compiledMagic = "otelcol-snmptrap-mib"
+
+// CompiledVersion is the version of the format of compiled MIB files. It is bumped whenever
+// the content of the files changes, and files of other versions must be compiled again.
+const CompiledVersion = 2
+
+var errNotCompiled = errors.New("not a compiled MIB file")
+
+// compiledMIB is the content of a compiled MIB file. The nodes are listed depth first in the
+// order of their OIDs, so that files compiled from the same modules are identical.
+type compiledMIB struct {
+	Magic   string
+	Version int
+	Types   []compiledType
+	Nodes   []compiledNode
+}
+
+type compiledType struct {
+	Name   string
+	Module string
+	Base   string
+	Named  []NamedNumber
+	Hint   string
+	Sizes  [][2]int64
+}
+
+type compiledNode struct {
+	OID         string
+	Name        string
+	Module      string
+	Kind        string
+	Status      string
+	Description string
+	// Type is the index of the type of the node in Types plus one, or zero when it has none
+	Type int
+	// Objects are the OIDs of the objects of a notification
+	Objects []string
+	// Index holds the OIDs of the objects of the index of a table row
+	Index   []string
+	Implied bool
+}
+
+// Encode writes the MIB in the compiled format, which Decode reads back much faster than
+// the modules it was loaded from can be parsed
+func (mib *MIB) Encode(w io.Writer) error {
+	compiled := compiledMIB{Magic: compiledMagic, Version: CompiledVersion}
+	types := map[*Type]int{}
+	mib.walk(func(node *Node) {
+		n := compiledNode{
+			OID:         node.OID,
+			Name:        node.Name,
+			Module:      node.Module,
+			Kind:        node.Kind,
+			Status:      node.Status,
+			Description: node.Description,
+		}
+		if node.Type != nil {
+			if _, ok := types[node.Type]; !ok {
+				compiled.Types = append(compiled.Types, compileType(node.Type))
+				types[node.Type] = len(compiled.Types)
+			}
+			n.Type = types[node.Type]
+		}
+		for _, object := range node.Objects {
+			n.Objects = append(n.Objects, object.OID)
+		}
+		for _, object := range node.Index {
+			n.Index = append(n.Index, object.OID)
+		}
+		n.Implied = node.Implied
+		compiled.Nodes = append(compiled.Nodes, n)
+	})
+
+	return gob.NewEncoder(w).Encode(&compiled)
+}
+
+func compileType(t *Type) compiledType {
+	compiled := compiledType{Name: t.Name, Module: t.Module, Base: t.Base, Named: t.Named, Hint: t.Hint}
+	for _, size := range t.sizes {
+		compiled.Sizes = append(compiled.Sizes, [2]int64{size.min, size.max})
+	}
+	return compiled
+}
+
+// Decode reads a MIB written by Encode
+func Decode(r io.Reader) (*MIB, error) {
+	var compiled compiledMIB
+	if err := gob.NewDecoder(r).Decode(&compiled); err != nil {
+		return nil, fmt.Errorf("%w: %w", errNotCompiled, err)
+	}
+	if compiled.Magic != compiledMagic {
+		return nil, errNotCompiled
+	}
+	if compiled.Version != CompiledVersion {
+		return nil, fmt.Errorf("compiled MIB version %d is not supported, it must be compiled again for version %d", compiled.Version, CompiledVersion)
+	}
+
+	types := make([]*Type, len(compiled.Types))
+	for i, c := range compiled.Types {
+		t := &Type{Name: c.Name, Module: c.Module, Base: c.Base, Named: c.Named, Hint: c.Hint}
+		for _, size := range c.Sizes {
+			t.sizes = append(t.sizes, sizeRange{size[0], size[1]})
+		}
+		types[i] = t
+	}
+
+	mib := &MIB{root: &Node{children: map[uint32]*Node{}}}
+	nodes := make([]*Node, len(compiled.Nodes))
+	for i, c := range compiled.Nodes {
+		oid, ok := parseOID(c.OID)
+		if !ok {
+			return nil, fmt.Errorf("%w: invalid OID %q", errNotCompiled, c.OID)
+		}
+		if c.Type < 0 || c.Type > len(types) {
+			return nil, fmt.Errorf("%w: invalid type of %s", errNotCompiled, c.Name)
+		}
+		node := &Node{Name: c.Name, Module: c.Module, Kind: c.Kind, Status: c.Status, Description: c.Description}
+		if c.Type > 0 {
+			node.Type = types[c.Type-1]
+		}
+		nodes[i] = mib.insert(oid, node)
+	}
+	for i, c := range compiled.Nodes {
+		if nodes[i] == nil {
+			continue
+		}
+		nodes[i].Objects = compiledObjects(mib, c.Objects)
+		nodes[i].Index = compiledObjects(mib, c.Index)
+		nodes[i].Implied = c.Implied
+	}
+	return mib, nil
+}
+
+// compiledObjects returns the nodes of the OIDs of compiled objects, skipping those which aren't in the tree
+func compiledObjects(mib *MIB, oids []string) []*Node {
+	var objects []*Node
+	for _, object := range oids {
+		oid, _ := parseOID(object)
+		if node := mib.node(oid); node != nil {
+			objects = append(objects, node)
+		}
+	}
+	return objects
+}
+
+// LoadCompiled reads a compiled MIB file written by Encode
+func LoadCompiled(file string) (*MIB, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read compiled MIB file: %w", err)
+	}
+	defer f.Close()
+
+	mib, err := Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	return mib, nil
+}
@@ -0,0 +1,148 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package mib // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver/internal/mib"
+
+import (
+	"bytes"
+	"encoding/gob"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	loaded, err := Load(filepath.Join("testdata", "mibs"))
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, loaded.Encode(&buf))
+	decoded, err := Decode(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	for _, oid := range []string{
+		".1.3.6.1.2.1.2.2.1.8.3",
+		".1.3.6.1.6.3.1.1.5.3",
+		".1.3.6.1.4.1.32473.1.1.0.1",
+		".1.3.6.1.4.1.32473.3.1.1.1.5.1.4.192.0.2.1.7",
+		".1.3.6.1.2.1.2.2.1",
+		".1.3.6.1.4.1.32473.3.1.2.1",
+		".1.3.6.1.4.1.32473.3.1.3.1",
+		".1.3.6.1.4.1.9.9.41",
+		".1.2.840",
+		".0.0",
+		".3.1",
+	} {
+		require.Equal(t, loaded.Name(oid), decoded.Name(oid), oid)
+
+		expected, expectedSuffix := loaded.Lookup(oid)
+		node, suffix := decoded.Lookup(oid)
+		require.Equal(t, expectedSuffix, suffix)
+		if expected == nil {
+			require.Nil(t, node)
+			continue
+		}
+		require.Equal(t, expected.OID, node.OID)
+		require.Equal(t, expected.Kind, node.Kind)
+		require.Equal(t, expected.Status, node.Status)
+		require.Equal(t, expected.Description, node.Description)
+		require.Equal(t, expected.Type, node.Type)
+		require.Equal(t, len(expected.Objects), len(node.Objects))
+		for i := range expected.Objects {
+			require.Equal(t, expected.Objects[i].OID, node.Objects[i].OID)
+		}
+		require.Equal(t, len(expected.Index), len(node.Index))
+		for i := range expected.Index {
+			require.Equal(t, expected.Index[i].OID, node.Index[i].OID)
+		}
+		require.Equal(t, expected.Implied, node.Implied)
+	}
+
+	// The nodes of the objects of notifications are those of the tree
+	linkDown := decoded.Notification(".1.3.6.1.6.3.1.1.5.3")
+	ifIndex, _ := decoded.Lookup(".1.3.6.1.2.1.2.2.1.1")
+	require.Same(t, ifIndex, linkDown.Objects[0])
+
+	// Instances are decoded with the index of their table
+	ifDescr, suffix := decoded.Lookup(".1.3.6.1.2.1.2.2.1.2.12")
+	values, ok := ifDescr.DecodeIndex(suffix)
+	require.True(t, ok)
+	require.Equal(t, []IndexValue{{Object: ifIndex, Value: int64(12)}}, values)
+
+	// Compiling the same modules gives the same file
+	var again bytes.Buffer
+	require.NoError(t, decoded.Encode(&again))
+	require.Equal(t, buf.Bytes(), again.Bytes())
+}
+
+func TestDecodeErrors(t *testing.T) {
+	encode := func(compiled compiledMIB) []byte {
+		var buf bytes.Buffer
+		require.NoError(t, gob.NewEncoder(&buf).Encode(&compiled))
+		return buf.Bytes()
+	}
+
+	type testCase struct {
+		name        string
+		data        []byte
+		expectedErr string
+	}
+
+	testCases := []testCase{
+		{
+			name:        "MIBModule",
+			data:        []byte("IF-MIB DEFINITIONS ::= BEGIN\nEND\n"),
+			expectedErr: "not a compiled MIB file",
+		},
+		{
+			name:        "OtherMagic",
+			data:        encode(compiledMIB{Magic: "other", Version: CompiledVersion}),
+			expectedErr: "not a compiled MIB file",
+		},
+		{
+			name:        "OtherVersion",
+			data:        encode(compiledMIB{Magic: compiledMagic, Version: CompiledVersion + 1}),
+			expectedErr: "compiled MIB version 3 is not supported, it must be compiled again for version 2",
+		},
+		{
+			name:        "InvalidOID",
+			data:        encode(compiledMIB{Magic: compiledMagic, Version: CompiledVersion, Nodes: []compiledNode{{OID: "1.x", Name: "bad"}}}),
+			expectedErr: `not a compiled MIB file: invalid OID "1.x"`,
+		},
+		{
+			name:        "InvalidType",
+			data:        encode(compiledMIB{Magic: compiledMagic, Version: CompiledVersion, Nodes: []compiledNode{{OID: "1.3", Name: "bad", Type: 1}}}),
+			expectedErr: "not a compiled MIB file: invalid type of bad",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Decode(bytes.NewReader(test.data))
+			require.ErrorContains(t, err, test.expectedErr)
+		})
+	}
+}
+
+func TestLoadCompiled(t *testing.T) {
+	loaded, err := Load(filepath.Join("testdata", "mibs"))
+	require.NoError(t, err)
+
+	file := filepath.Join(t.TempDir(), "mibs.compiled")
+	var buf bytes.Buffer
+	require.NoError(t, loaded.Encode(&buf))
+	require.NoError(t, os.WriteFile(file, buf.Bytes(), 0o600))
+
+	compiled, err := LoadCompiled(file)
+	require.NoError(t, err)
+	require.Equal(t, "IF-MIB::linkDown", compiled.Name(".1.3.6.1.6.3.1.1.5.3"))
+
+	_, err = LoadCompiled(filepath.Join("testdata", "mibs", "IF-MIB"))
+	require.True(t, strings.HasPrefix(err.Error(), filepath.Join("testdata", "mibs", "IF-MIB")+": not a compiled MIB file"), err.Error())
+
+	_, err = LoadCompiled(filepath.Join(t.TempDir(), "missing"))
+	require.ErrorContains(t, err, "failed to read compiled MIB file")
+}
@@ -0,0 +1,153 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package mib // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver/internal/mib"
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// octetFormat is one octet-format specification of an OCTET STRING DISPLAY-HINT
+type octetFormat struct {
+	// repeat tells whether the first octet of the value is a count of how many times the
+	// specification applies, for the '*' indicator
+	repeat     bool
+	length     int
+	format     byte
+	separator  byte
+	terminator byte
+}
+
+// parseOctetHint parses the DISPLAY-HINT of an OCTET STRING textual convention, RFC 2579
+// section 3.1
+func parseOctetHint(hint string) ([]octetFormat, error) {
+	var formats []octetFormat
+	for i := 0; i < len(hint); {
+		var f octetFormat
+		if hint[i] == '*' {
+			f.repeat = true
+			i++
+		}
+
+		start := i
+		for i < len(hint) && isDigit(hint[i]) {
+			i++
+		}
+		length, err := strconv.Atoi(hint[start:i])
+		if err != nil || length == 0 {
+			return nil, fmt.Errorf("invalid DISPLAY-HINT %q: expected an octet length at offset %d", hint, start)
+		}
+		f.length = length
+
+		if i == len(hint) || strings.IndexByte("xdoat", hint[i]) < 0 {
+			return nil, fmt.Errorf("invalid DISPLAY-HINT %q: expected one of x, d, o, a or t at offset %d", hint, i)
+		}
+		f.format = hint[i]
+		i++
+
+		// Any other character than the start of the next specification is a separator, and
+		// then a repeat terminator
+		if i < len(hint) && !isDigit(hint[i]) && hint[i] != '*' {
+			f.separator = hint[i]
+			i++
+			if f.repeat && i < len(hint) && !isDigit(hint[i]) && hint[i] != '*' {
+				f.terminator = hint[i]
+				i++
+			}
+		}
+		formats = append(formats, f)
+	}
+	if len(formats) == 0 {
+		return nil, fmt.Errorf("invalid DISPLAY-HINT %q: it is empty", hint)
+	}
+	return formats, nil
+}
+
+// FormatOctetString renders the value of an OCTET STRING with a DISPLAY-HINT, such as "1x:" for
+// a MAC address or "2d-1d-1d,1d:1d:1d.1d,1a1d:1d" for a DateAndTime. The last specification of
+// the hint is applied to the octets which remain once the others have been, and separators
+// aren't written after the last octet. An error is returned when the hint isn't valid.
+func FormatOctetString(hint string, value []byte) (string, error) {
+	formats, err := parseOctetHint(hint)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	for i := 0; len(value) > 0; i++ {
+		f := formats[min(i, len(formats)-1)]
+		repeat := 1
+		if f.repeat {
+			repeat = int(value[0])
+			value = value[1:]
+		}
+		for r := 0; r < repeat && len(value) > 0; r++ {
+			n := min(f.length, len(value))
+			writeOctets(&b, f.format, value[:n])
+			value = value[n:]
+			if len(value) == 0 {
+				break
+			}
+			if r == repeat-1 && f.terminator != 0 {
+				b.WriteByte(f.terminator)
+			} else if f.separator != 0 {
+				b.WriteByte(f.separator)
+			}
+		}
+	}
+	return b.String(), nil
+}
+
+// writeOctets writes octets in one of the display formats of a DISPLAY-HINT. The octets are
+// a single unsigned number for the numeric formats, and hexadecimal numbers are padded to two
+// digits per octet.
+func writeOctets(b *strings.Builder, format byte, octets []byte) {
+	switch format {
+	case 'a', 't':
+		b.Write(octets)
+	case 'x':
+		text := new(big.Int).SetBytes(octets).Text(16)
+		b.WriteString(strings.Repeat("0", 2*len(octets)-len(text)))
+		b.WriteString(text)
+	case 'd':
+		b.WriteString(new(big.Int).SetBytes(octets).Text(10))
+	case 'o':
+		b.WriteString(new(big.Int).SetBytes(octets).Text(8))
+	}
+}
+
+// FormatOctets renders the value of an OCTET STRING which has no DISPLAY-HINT: as is when it
+// is printable text, and as hexadecimal octets separated by spaces otherwise, such as
+// "00 1A 2B", so that binary values aren't mangled into invalid strings
+func FormatOctets(value []byte) string {
+	if isPrintable(value) {
+		return string(value)
+	}
+
+	var b strings.Builder
+	for i, octet := range value {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		fmt.Fprintf(&b, "%02X", octet)
+	}
+	return b.String()
+}
+
+// isPrintable tells whether a value is valid UTF-8 made of printable characters and spaces
+func isPrintable(value []byte) bool {
+	if !utf8.Valid(value) {
+		return false
+	}
+	for _, r := range string(value) {
+		if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,88 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package mib // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver/internal/mib"
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatOctetString(t *testing.T) {
+	type testCase struct {
+		name        string
+		hint        string
+		value       []byte
+		expected    string
+		expectedErr string
+	}
+
+	testCases := []testCase{
+		{name: "DisplayString", hint: "255a", value: []byte("eth0"), expected: "eth0"},
+		{name: "MacAddress", hint: "1x:", value: []byte{0x00, 0x1a, 0x2b, 0x3c, 0x4d, 0x5e}, expected: "00:1a:2b:3c:4d:5e"},
+		{name: "InetAddressIPv4", hint: "1d.1d.1d.1d", value: []byte{192, 0, 2, 1}, expected: "192.0.2.1"},
+		{
+			name:     "InetAddressIPv6",
+			hint:     "2x:2x:2x:2x:2x:2x:2x:2x",
+			value:    []byte{0x20, 0x01, 0x0d, 0xb8, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0x01},
+			expected: "2001:0db8:0000:0000:0000:0000:0000:0001",
+		},
+		{
+			name:     "DateAndTime",
+			hint:     "2d-1d-1d,1d:1d:1d.1d,1a1d:1d",
+			value:    []byte{0x07, 0xe8, 1, 15, 13, 30, 15, 0},
+			expected: "2024-1-15,13:30:15.0",
+		},
+		{
+			name:     "DateAndTimeWithZone",
+			hint:     "2d-1d-1d,1d:1d:1d.1d,1a1d:1d",
+			value:    []byte{0x07, 0xe8, 1, 15, 13, 30, 15, 0, '+', 2, 0},
+			expected: "2024-1-15,13:30:15.0,+2:0",
+		},
+		{name: "Octal", hint: "1o", value: []byte{8, 9}, expected: "1011"},
+		{name: "Repeat", hint: "*1d./1x", value: []byte{2, 10, 20, 0xff}, expected: "10.20/ff"},
+		{name: "UTF8", hint: "255t", value: []byte("café"), expected: "café"},
+		{name: "ShortValue", hint: "4d", value: []byte{1, 0}, expected: "256"},
+		{name: "Empty", hint: "1x:", value: []byte{}, expected: ""},
+		{name: "MissingLength", hint: "x:", expectedErr: `invalid DISPLAY-HINT "x:": expected an octet length at offset 0`},
+		{name: "UnknownFormat", hint: "1z", expectedErr: `invalid DISPLAY-HINT "1z": expected one of x, d, o, a or t at offset 1`},
+		{name: "IntegerHint", hint: "d-2", expectedErr: `invalid DISPLAY-HINT "d-2": expected an octet length at offset 0`},
+		{name: "ZeroLength", hint: "0x", expectedErr: `invalid DISPLAY-HINT "0x": expected an octet length at offset 0`},
+		{name: "EmptyHint", hint: "", expectedErr: `invalid DISPLAY-HINT "": it is empty`},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			text, err := FormatOctetString(test.hint, test.value)
+			if test.expectedErr != "" {
+				require.EqualError(t, err, test.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expected, text)
+		})
+	}
+}
+
+func TestFormatOctets(t *testing.T) {
+	type testCase struct {
+		name     string
+		value    []byte
+		expected string
+	}
+
+	testCases := []testCase{
+		{name: "Text", value: []byte("Fan 2 failed\n"), expected: "Fan 2 failed\n"},
+		{name: "UTF8", value: []byte("Salle café"), expected: "Salle café"},
+		{name: "Binary", value: []byte{0x00, 0x1a, 0x2b}, expected: "00 1A 2B"},
+		{name: "InvalidUTF8", value: []byte{'a', 0xff}, expected: "61 FF"},
+		{name: "Empty", value: []byte{}, expected: ""},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, FormatOctets(test.value))
+		})
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package mib // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver/internal/mib"
+
+import (
+	"fmt"
+)
+
+// IndexValue is a component of the instance of a column, decoded with the syntax of an object
+// of the INDEX clause of its table
+type IndexValue struct {
+	Object *Node
+	// Value is an int64 for the integer types, a dotted string for an IpAddress, a []byte for
+	// the string types and a dotted OID without a leading dot for an OBJECT IDENTIFIER
+	Value any
+}
+
+// DecodeIndex decodes the arcs following a column in the OID of one of its instances, such as
+// the 12 of ifDescr.12, with the INDEX clause of the table of the column, RFC 2578 section 7.7.
+// It returns false when the node isn't a column of an indexed table, or when the arcs don't
+// match the index.
+func (node *Node) DecodeIndex(suffix []uint32) ([]IndexValue, bool) {
+	if node.Kind != "OBJECT-TYPE" || node.parent == nil || len(node.parent.Index) == 0 {
+		return nil, false
+	}
+	row := node.parent
+
+	var values []IndexValue
+	for i, object := range row.Index {
+		implied := row.Implied && i == len(row.Index)-1
+		value, rest, ok := decodeIndexValue(object.Type, suffix, implied)
+		if !ok {
+			return nil, false
+		}
+		values = append(values, IndexValue{Object: object, Value: value})
+		suffix = rest
+	}
+	if len(suffix) > 0 {
+		return nil, false
+	}
+	return values, true
+}
+
+// decodeIndexValue decodes the value of an object of an index at the start of the arcs, and
+// returns the arcs which follow it
+func decodeIndexValue(t *Type, arcs []uint32, implied bool) (any, []uint32, bool) {
+	if t == nil {
+		return nil, nil, false
+	}
+	switch t.Base {
+	case BaseInteger, BaseGauge32, BaseCounter32, BaseTimeTicks:
+		if len(arcs) == 0 {
+			return nil, nil, false
+		}
+		return int64(arcs[0]), arcs[1:], true
+	case BaseIPAddress:
+		octets, rest, ok := indexOctets(arcs, 4)
+		if !ok {
+			return nil, nil, false
+		}
+		return fmt.Sprintf("%d.%d.%d.%d", octets[0], octets[1], octets[2], octets[3]), rest, true
+	case BaseOctetString, BaseBits, BaseOpaque:
+		// Fixed-size strings have no length, and neither has an IMPLIED string which takes the rest
+		length, ok := t.fixedSize()
+		switch {
+		case ok:
+		case implied:
+			length = len(arcs)
+		default:
+			if arcs, length, ok = indexLength(arcs); !ok {
+				return nil, nil, false
+			}
+		}
+		octets, rest, ok := indexOctets(arcs, length)
+		if !ok {
+			return nil, nil, false
+		}
+		return octets, rest, true
+	case BaseObjectIdentifier:
+		length := len(arcs)
+		if !implied {
+			var ok bool
+			if arcs, length, ok = indexLength(arcs); !ok {
+				return nil, nil, false
+			}
+		}
+		if length > len(arcs) {
+			return nil, nil, false
+		}
+		return formatOID(arcs[:length]), arcs[length:], true
+	}
+	return nil, nil, false
+}
+
+// indexLength returns the length which precedes a variable-length value of an index
+func indexLength(arcs []uint32) ([]uint32, int, bool) {
+	if len(arcs) == 0 || int64(arcs[0]) > int64(len(arcs)-1) {
+		return nil, 0, false
+	}
+	return arcs[1:], int(arcs[0]), true
+}
+
+// indexOctets returns the octets of a string of an index, each of which is an arc
+func indexOctets(arcs []uint32, length int) ([]byte, []uint32, bool) {
+	if length > len(arcs) {
+		return nil, nil, false
+	}
+	octets := make([]byte, length)
+	for i, arc := range arcs[:length] {
+		if arc > 255 {
+			return nil, nil, false
+		}
+		octets[i] = byte(arc)
+	}
+	return octets, arcs[length:], true
+}
+
+// fixedSize returns the size of a string type whose SIZE constraint allows a single size
+func (t *Type) fixedSize() (int, bool) {
+	if len(t.sizes) != 1 || t.sizes[0].min != t.sizes[0].max {
+		return 0, false
+	}
+	return int(t.sizes[0].min), true
+}
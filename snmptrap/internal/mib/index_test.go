@@ -0,0 +1,87 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package mib // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver/internal/mib"
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeIndex(t *testing.T) {
+	mib, err := LoadStandard(filepath.Join("testdata", "mibs"))
+	require.NoError(t, err)
+
+	type testCase struct {
+		name     string
+		oid      string
+		expected map[string]any
+	}
+
+	testCases := []testCase{
+		{
+			name:     "Integer",
+			oid:      ".1.3.6.1.2.1.2.2.1.2.12",
+			expected: map[string]any{"ifIndex": int64(12)},
+		},
+		{
+			name:     "IntegerAndIPAddress",
+			oid:      ".1.3.6.1.4.1.32473.3.1.5.1.3.3.10.0.0.1",
+			expected: map[string]any{"exampleNeighborIfIndex": int64(3), "exampleNeighborAddress": "10.0.0.1"},
+		},
+		{
+			name:     "VariableLengthString",
+			oid:      ".1.3.6.1.4.1.32473.3.1.1.1.5.1.4.192.0.2.1.7",
+			expected: map[string]any{"exampleAlarmAddressType": int64(1), "exampleAlarmAddress": []byte{192, 0, 2, 1}, "exampleAlarmId": int64(7)},
+		},
+		{
+			name:     "FixedLengthAndImpliedStrings",
+			oid:      ".1.3.6.1.4.1.32473.3.1.2.1.3.0.26.43.60.77.94.101.116.104.48",
+			expected: map[string]any{"exampleProfilePort": []byte{0x00, 0x1a, 0x2b, 0x3c, 0x4d, 0x5e}, "exampleProfileName": []byte("eth0")},
+		},
+		{
+			name:     "Augments",
+			oid:      ".1.3.6.1.4.1.32473.3.1.3.1.1.0.26.43.60.77.94.101.116.104.48",
+			expected: map[string]any{"exampleProfilePort": []byte{0x00, 0x1a, 0x2b, 0x3c, 0x4d, 0x5e}, "exampleProfileName": []byte("eth0")},
+		},
+		{
+			name:     "ObjectIdentifier",
+			oid:      ".1.3.6.1.4.1.32473.3.1.4.1.3.4.1.3.6.1.2",
+			expected: map[string]any{"exampleSensorType": "1.3.6.1", "exampleSensorIndex": int64(2)},
+		},
+		{name: "Scalar", oid: ".1.3.6.1.2.1.1.3.0"},
+		{name: "Table", oid: ".1.3.6.1.2.1.2.2.1"},
+		{name: "MissingComponent", oid: ".1.3.6.1.2.1.4.22.1.2.3.10.0"},
+		{name: "ExtraArcs", oid: ".1.3.6.1.2.1.2.2.1.2.12.1"},
+		{name: "LengthTooLong", oid: ".1.3.6.1.4.1.32473.3.1.1.1.5.1.9.192.0.2.1.7"},
+		{name: "OctetTooLarge", oid: ".1.3.6.1.4.1.32473.3.1.5.1.3.3.10.0.0.256"},
+		{name: "NoInstance", oid: ".1.3.6.1.2.1.2.2.1.2"},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			node, suffix := mib.Lookup(test.oid)
+			require.NotNil(t, node)
+			values, ok := node.DecodeIndex(suffix)
+			if test.expected == nil {
+				require.False(t, ok)
+				return
+			}
+			require.True(t, ok)
+			decoded := map[string]any{}
+			for _, value := range values {
+				decoded[value.Object.Name] = value.Value
+			}
+			require.Equal(t, test.expected, decoded)
+		})
+	}
+
+	// The values are in the order of the INDEX clause
+	node, suffix := mib.Lookup(".1.3.6.1.4.1.32473.3.1.5.1.3.3.10.0.0.1")
+	values, ok := node.DecodeIndex(suffix)
+	require.True(t, ok)
+	require.Equal(t, "exampleNeighborIfIndex", values[0].Object.Name)
+	require.Equal(t, "exampleNeighborAddress", values[1].Object.Name)
+}
@@ -0,0 +1,129 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package mib // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver/internal/mib"
+
+import (
+	"bytes"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenNumber
+	tokenString
+	// tokenQuoted is a binary or hexadecimal string such as '0A'H
+	tokenQuoted
+	tokenPunct
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	line int
+}
+
+// punctuation is the multi-character punctuation of the SMI, longest first
+var punctuation = []string{"::=", "..", "{", "}", "(", ")", "[", "]", ",", ";", "|"}
+
+// lex splits the text of a MIB file into tokens. Comments start with "--" and end at
+// the end of the line or at the next "--", as in ASN.1. The returned slice always ends
+// with a tokenEOF. When an error is returned, the tokens found before it are returned along with it.
+func lex(file string, data []byte) ([]token, error) {
+	var tokens []token
+	line := 1
+	for i := 0; i < len(data); {
+		c := data[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r' || c == '\f' || c == '\v':
+			i++
+		case c == '-' && i+1 < len(data) && data[i+1] == '-':
+			i += 2
+			for i < len(data) && data[i] != '\n' {
+				if data[i] == '-' && i+1 < len(data) && data[i+1] == '-' {
+					i += 2
+					break
+				}
+				i++
+			}
+		case c == '"':
+			start, startLine := i+1, line
+			var text []byte
+			for i++; ; i++ {
+				if i >= len(data) {
+					return tokens, &Error{File: file, Line: startLine, Msg: "unterminated string"}
+				}
+				if data[i] == '\n' {
+					line++
+				}
+				if data[i] != '"' {
+					continue
+				}
+				// A doubled quote stands for a quote inside the string
+				if i+1 < len(data) && data[i+1] == '"' {
+					text = append(text, data[start:i+1]...)
+					start = i + 2
+					i++
+					continue
+				}
+				text = append(text, data[start:i]...)
+				i++
+				break
+			}
+			tokens = append(tokens, token{kind: tokenString, text: string(text), line: startLine})
+		case c == '\'':
+			end := bytes.IndexByte(data[i+1:], '\'')
+			if end < 0 {
+				return tokens, &Error{File: file, Line: line, Msg: "unterminated quoted string"}
+			}
+			// The closing quote is followed by H or B
+			end += i + 1
+			if end+1 < len(data) && isLetter(data[end+1]) {
+				end++
+			}
+			tokens = append(tokens, token{kind: tokenQuoted, text: string(data[i : end+1]), line: line})
+			line += bytes.Count(data[i:end], []byte{'\n'})
+			i = end + 1
+		case isDigit(c) || (c == '-' && i+1 < len(data) && isDigit(data[i+1])):
+			start := i
+			for i++; i < len(data) && isDigit(data[i]); i++ {
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: string(data[start:i]), line: line})
+		case isLetter(c):
+			start := i
+			for i++; i < len(data); i++ {
+				if data[i] == '-' && (i+1 >= len(data) || data[i+1] == '-') {
+					break
+				}
+				if !isLetter(data[i]) && !isDigit(data[i]) && data[i] != '-' && data[i] != '_' {
+					break
+				}
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: string(data[start:i]), line: line})
+		default:
+			text := string(c)
+			for _, punct := range punctuation {
+				if bytes.HasPrefix(data[i:], []byte(punct)) {
+					text = punct
+					break
+				}
+			}
+			tokens = append(tokens, token{kind: tokenPunct, text: text, line: line})
+			i += len(text)
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, line: line}), nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
@@ -0,0 +1,612 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package mib loads SMIv1 and SMIv2 MIB modules and translates the OIDs they define into names.
+// It is written in Go, so that no external tool such as Net-SNMP is needed to load them.
+package mib // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver/internal/mib"
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// smiFS holds the modules defining the SMI itself, which nearly every module imports from
+//
+//go:embed smi
+var smiFS embed.FS
+
+// snmpOID is the enterprise of the generic traps of SMIv1, and snmpTrapsOID the OID under which
+// RFC 3584 places them
+var (
+	snmpOID      = []uint32{1, 3, 6, 1, 2, 1, 11}
+	snmpTrapsOID = []uint32{1, 3, 6, 1, 6, 3, 1, 1, 5}
+)
+
+// genericTraps is the number of generic traps, from coldStart(0) to egpNeighborLoss(5)
+const genericTraps = 6
+
+// standardFS holds the standard MIB set: abridged copies of the modules most agents send
+// notifications and objects of, such as SNMPv2-MIB, IF-MIB and ENTITY-MIB, and the generic
+// traps of RFC 1215. What each module keeps is listed in standard/README.
+//
+//go:embed standard
+var standardFS embed.FS
+
+// roots are the arcs at the top of the OID tree, which are not defined by any module
+var roots = map[string]uint32{"ccitt": 0, "iso": 1, "joint-iso-ccitt": 2}
+
+// Node is an OID defined by a MIB module
+type Node struct {
+	Name   string
+	Module string
+	// OID is the dotted OID of the node, without a leading dot
+	OID string
+	// Kind is the macro which defined the node, such as OBJECT-TYPE, or OBJECT IDENTIFIER
+	Kind        string
+	Status      string
+	Description string
+	// Type is the syntax of an OBJECT-TYPE, or nil for other kinds of nodes and for tables and rows
+	Type *Type
+	// Objects are the objects a NOTIFICATION-TYPE or TRAP-TYPE carries, in the order of its
+	// OBJECTS or VARIABLES clause
+	Objects []*Node
+	// Index holds the objects of the INDEX clause of a table row, or of the row it AUGMENTS
+	Index []*Node
+	// Implied tells whether the last object of Index is IMPLIED
+	Implied bool
+
+	parent   *Node
+	children map[uint32]*Node
+}
+
+// MIB is the tree of the OIDs defined by a set of MIB modules. It isn't modified once
+// loaded, so it can be used concurrently.
+type MIB struct {
+	root *Node
+}
+
+// Load parses the MIB files found in the given directories and builds the tree of the OIDs
+// they define, resolving the IMPORTS of each module. The core SMI modules, such as SNMPv2-SMI,
+// are always loaded after the directories.
+//
+// When several files define the same module, the first one found is used. When several modules
+// define the same OID, SMIv2 modules take precedence over SMIv1 ones, and then the module loaded
+// first does. Problems with some of the files don't prevent the rest from being loaded, so the
+// returned MIB is usable even when an error is returned.
+func Load(dirs ...string) (*MIB, error) {
+	return load(dirs, false)
+}
+
+// LoadStandard is like Load, but also loads the standard MIB set embedded in the package after
+// the directories, so that the modules of the directories replace the standard ones with the
+// same name.
+func LoadStandard(dirs ...string) (*MIB, error) {
+	return load(dirs, true)
+}
+
+func load(dirs []string, standard bool) (*MIB, error) {
+	l := &loader{modules: map[string]*module{}}
+	for _, dir := range dirs {
+		l.addFS(os.DirFS(dir), dir)
+	}
+	if standard {
+		standard, _ := fs.Sub(standardFS, "standard")
+		l.addFS(standard, "standard")
+	}
+	smi, _ := fs.Sub(smiFS, "smi")
+	l.addFS(smi, "smi")
+
+	r := &resolver{
+		modules:  l.modules,
+		oids:     map[*object][]uint32{},
+		failed:   map[*object]bool{},
+		types:    map[*typeDef]*Type{},
+		reported: map[string]bool{},
+		errs:     l.errs,
+	}
+	mib := r.resolve(l.order)
+	return mib, errors.Join(r.errs...)
+}
+
+// loader collects the modules of a set of directories
+type loader struct {
+	modules map[string]*module
+	// order is the order the modules were loaded in
+	order []*module
+	errs  []error
+}
+
+// addFS parses the files at the top of a directory. Subdirectories and hidden files are skipped.
+func (l *loader) addFS(fsys fs.FS, dir string) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		l.errs = append(l.errs, fmt.Errorf("failed to read MIB directory %s: %w", dir, err))
+		return
+	}
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		if info, err := fs.Stat(fsys, entry.Name()); err == nil && info.IsDir() {
+			continue
+		}
+		file := filepath.Join(dir, entry.Name())
+
+		data, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			l.errs = append(l.errs, fmt.Errorf("failed to read MIB file %s: %w", file, err))
+			continue
+		}
+		modules, err := parseFile(file, data)
+		if errors.Is(err, errNotMIB) {
+			continue
+		}
+		if err != nil {
+			l.errs = append(l.errs, err)
+			continue
+		}
+		for _, m := range modules {
+			if _, ok := l.modules[m.name]; ok {
+				continue
+			}
+			l.modules[m.name] = m
+			l.order = append(l.order, m)
+		}
+	}
+}
+
+// resolver computes the OIDs of the objects of a set of modules
+type resolver struct {
+	modules map[string]*module
+	// defined indexes the objects of each module by name
+	defined map[*module]map[string]*object
+	// global and globalTypes are the first module defining each name, for names used without being imported
+	global      map[string]*module
+	globalTypes map[string]*module
+	oids        map[*object][]uint32
+	failed      map[*object]bool
+	types       map[*typeDef]*Type
+	// reported holds the missing modules which were already reported
+	reported map[string]bool
+	errs     []error
+}
+
+// resolve builds the OID tree of the modules, which are given in the order they were loaded
+func (r *resolver) resolve(order []*module) *MIB {
+	r.defined = map[*module]map[string]*object{}
+	r.global = map[string]*module{}
+	r.globalTypes = map[string]*module{}
+	for _, m := range order {
+		for name := range m.types {
+			if _, ok := r.globalTypes[name]; !ok {
+				r.globalTypes[name] = m
+			}
+		}
+		defined := map[string]*object{}
+		for _, obj := range m.objects {
+			defined[obj.name] = obj
+			if _, ok := r.global[obj.name]; !ok {
+				r.global[obj.name] = m
+			}
+		}
+		r.defined[m] = defined
+	}
+
+	// SMIv2 modules name the OIDs they share with SMIv1 modules, so they are added to the tree first
+	sorted := append([]*module{}, order...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return isSMIv2(sorted[i]) && !isSMIv2(sorted[j])
+	})
+
+	mib := &MIB{root: &Node{children: map[uint32]*Node{}}}
+	for name, arc := range roots {
+		mib.root.children[arc] = &Node{Name: name, OID: strconv.FormatUint(uint64(arc), 10), Kind: "OBJECT IDENTIFIER"}
+	}
+	// entry is an object whose clauses refer to other objects, which are resolved once the
+	// whole tree is built
+	type entry struct {
+		m    *module
+		obj  *object
+		node *Node
+	}
+	var notifications, rows []entry
+	for _, m := range sorted {
+		for _, obj := range m.objects {
+			oid, ok := r.objectOID(m, obj)
+			if !ok {
+				continue
+			}
+			node := &Node{
+				Name:        obj.name,
+				Module:      m.name,
+				Kind:        obj.macro,
+				Status:      obj.status,
+				Description: obj.description,
+			}
+			if obj.macro == "OBJECT-TYPE" && obj.syntax != nil {
+				node.Type = r.syntaxType(m, obj.syntax, obj.line)
+			}
+			inserted := mib.insert(oid, node)
+			if inserted == nil {
+				continue
+			}
+			if obj.macro == "NOTIFICATION-TYPE" || obj.macro == "TRAP-TYPE" {
+				notifications = append(notifications, entry{m, obj, inserted})
+			}
+			if len(obj.index) > 0 || obj.augments != "" {
+				rows = append(rows, entry{m, obj, inserted})
+			}
+		}
+	}
+
+	// The objects of notifications are resolved once the whole tree is built, since they
+	// are usually defined after the notifications or in other modules
+	for _, n := range notifications {
+		for _, name := range n.obj.objects {
+			oid, ok := r.nameOID(n.m, n.obj, name)
+			if !ok {
+				continue
+			}
+			if node := mib.node(oid); node != nil {
+				n.node.Objects = append(n.node.Objects, node)
+			}
+		}
+	}
+
+	// Rows which AUGMENTS another share its index, so they are resolved after the others
+	var augments []entry
+	for _, row := range rows {
+		if row.obj.augments != "" {
+			augments = append(augments, row)
+			continue
+		}
+		row.node.Index, row.node.Implied = r.index(mib, row.m, row.obj)
+	}
+	for _, row := range augments {
+		oid, ok := r.nameOID(row.m, row.obj, row.obj.augments)
+		if !ok {
+			continue
+		}
+		if augmented := mib.node(oid); augmented != nil {
+			row.node.Index, row.node.Implied = augmented.Index, augmented.Implied
+		}
+	}
+	return mib
+}
+
+// index resolves the objects of the INDEX clause of a table row. SMIv1 modules may list types
+// there instead of objects, and those rows are left without an index since their instances
+// can't be decoded without knowing what they hold.
+func (r *resolver) index(mib *MIB, m *module, obj *object) ([]*Node, bool) {
+	var index []*Node
+	for _, item := range obj.index {
+		if _, ok := r.defined[m][item.name]; !ok && m.imports[item.name] == "" && r.isType(item.name) {
+			return nil, false
+		}
+		oid, ok := r.nameOID(m, obj, item.name)
+		if !ok {
+			return nil, false
+		}
+		node := mib.node(oid)
+		if node == nil {
+			return nil, false
+		}
+		index = append(index, node)
+	}
+	return index, obj.index[len(obj.index)-1].implied
+}
+
+// isType tells whether a name is a type rather than an object
+func (r *resolver) isType(name string) bool {
+	if _, ok := applicationTypes[name]; ok {
+		return true
+	}
+	_, ok := r.globalTypes[name]
+	return ok || name == BaseInteger
+}
+
+// isSMIv2 tells whether a module is written in SMIv2, which all modules importing from SNMPv2-SMI are
+func isSMIv2(m *module) bool {
+	switch m.name {
+	case "SNMPv2-SMI", "SNMPv2-TC", "SNMPv2-CONF":
+		return true
+	}
+	for _, from := range m.imports {
+		if from == "SNMPv2-SMI" {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *resolver) errorf(m *module, line int, format string, args ...any) {
+	r.errs = append(r.errs, &Error{File: m.file, Line: line, Msg: fmt.Sprintf(format, args...)})
+}
+
+// objectOID returns the OID of an object of a module. Objects which can't be resolved are
+// reported once, and objects under them fail without being reported again.
+func (r *resolver) objectOID(m *module, obj *object) ([]uint32, bool) {
+	if oid, ok := r.oids[obj]; ok {
+		return oid, true
+	}
+	if r.failed[obj] {
+		return nil, false
+	}
+	// Objects are marked as failed while they are resolved, which stops definition loops
+	r.failed[obj] = true
+
+	var oid []uint32
+	components := obj.oid
+	if obj.macro == "TRAP-TYPE" {
+		// The OID of an SMIv1 trap is its enterprise followed by 0 and its specific-trap number, RFC 3584
+		enterprise, ok := r.nameOID(m, obj, obj.enterprise)
+		if !ok {
+			return nil, false
+		}
+		// The generic traps are sent with the snmp enterprise, and their OIDs are under snmpTraps instead
+		if number := components[0].number; slices.Equal(enterprise, snmpOID) && number < genericTraps {
+			oid = append(append(oid, snmpTrapsOID...), number+1)
+			delete(r.failed, obj)
+			r.oids[obj] = oid
+			return oid, true
+		}
+		oid = append(append(oid, enterprise...), 0)
+	} else if first := components[0]; first.hasNumber {
+		oid = append(oid, first.number)
+		components = components[1:]
+	} else {
+		parent, ok := r.nameOID(m, obj, first.name)
+		if !ok {
+			return nil, false
+		}
+		oid = append(oid, parent...)
+		components = components[1:]
+	}
+	for _, component := range components {
+		if !component.hasNumber {
+			r.errorf(m, obj.line, "OID component '%s' of %s has no number", component.name, obj.name)
+			return nil, false
+		}
+		oid = append(oid, component.number)
+	}
+
+	delete(r.failed, obj)
+	r.oids[obj] = oid
+	return oid, true
+}
+
+// nameOID returns the OID of a name used by an object of a module, looking the name up in the
+// module, in its imports and in the roots of the tree. Names used without being imported are
+// looked up in the other modules, since many SMIv1 modules forget a few imports.
+func (r *resolver) nameOID(m *module, user *object, name string) ([]uint32, bool) {
+	if obj, ok := r.defined[m][name]; ok {
+		return r.objectOID(m, obj)
+	}
+
+	if from, ok := m.imports[name]; ok {
+		source, ok := r.importedModule(m, from)
+		if !ok {
+			return nil, false
+		}
+		obj, ok := r.defined[source][name]
+		if !ok {
+			r.errorf(m, user.line, "%s is not defined by %s", name, from)
+			return nil, false
+		}
+		return r.objectOID(source, obj)
+	}
+
+	if arc, ok := roots[name]; ok {
+		return []uint32{arc}, true
+	}
+	if source, ok := r.global[name]; ok {
+		return r.objectOID(source, r.defined[source][name])
+	}
+	r.errorf(m, user.line, "%s used by %s is not defined", name, user.name)
+	return nil, false
+}
+
+// importedModule returns a module imported by another. Missing modules are reported once per importing module.
+func (r *resolver) importedModule(m *module, from string) (*module, bool) {
+	source, ok := r.modules[from]
+	if !ok {
+		if key := m.name + "\x00" + from; !r.reported[key] {
+			r.reported[key] = true
+			r.errorf(m, m.importLines[from], "imported module %s was not found", from)
+		}
+	}
+	return source, ok
+}
+
+// insert adds a node to the tree at the given OID, and returns the node of the tree. An OID
+// which already has a name keeps it, and nil is returned.
+func (mib *MIB) insert(oid []uint32, node *Node) *Node {
+	parent := mib.root
+	for i, arc := range oid {
+		child, ok := parent.children[arc]
+		if !ok {
+			child = &Node{OID: formatOID(oid[:i+1]), parent: parent}
+			if parent.children == nil {
+				parent.children = map[uint32]*Node{}
+			}
+			parent.children[arc] = child
+		}
+		parent = child
+	}
+	if parent.Name != "" {
+		return nil
+	}
+	parent.Name = node.Name
+	parent.Module = node.Module
+	parent.Kind = node.Kind
+	parent.Status = node.Status
+	parent.Description = node.Description
+	parent.Type = node.Type
+	return parent
+}
+
+// node returns the named node at exactly the given OID, or nil
+func (mib *MIB) node(oid []uint32) *Node {
+	node := mib.root
+	for _, arc := range oid {
+		if node = node.children[arc]; node == nil {
+			return nil
+		}
+	}
+	if node.Name == "" {
+		return nil
+	}
+	return node
+}
+
+// Lookup returns the node with the longest OID which is a prefix of the given OID, along
+// with the arcs of the OID which follow it. The OID is dotted, with or without a leading dot.
+// A nil node is returned when the OID is invalid or isn't under any known node.
+func (mib *MIB) Lookup(oid string) (*Node, []uint32) {
+	arcs, ok := parseOID(oid)
+	if !ok {
+		return nil, nil
+	}
+	var found *Node
+	var suffix []uint32
+	node := mib.root
+	for i, arc := range arcs {
+		if node = node.children[arc]; node == nil {
+			break
+		}
+		if node.Name != "" {
+			found, suffix = node, arcs[i+1:]
+		}
+	}
+	return found, suffix
+}
+
+// Notification returns the NOTIFICATION-TYPE or TRAP-TYPE defined at exactly the given OID,
+// or nil when there is none
+func (mib *MIB) Notification(oid string) *Node {
+	arcs, ok := parseOID(oid)
+	if !ok {
+		return nil
+	}
+	node := mib.node(arcs)
+	if node == nil || (node.Kind != "NOTIFICATION-TYPE" && node.Kind != "TRAP-TYPE") {
+		return nil
+	}
+	return node
+}
+
+// Name translates an OID into the name of its node in the form MODULE::name, followed by
+// the arcs of the OID under that node, such as IF-MIB::ifOperStatus.3. An empty string is
+// returned when the OID can't be translated.
+func (mib *MIB) Name(oid string) string {
+	node, suffix := mib.Lookup(oid)
+	if node == nil {
+		return ""
+	}
+	name := node.Name
+	if node.Module != "" {
+		name = node.Module + "::" + name
+	}
+	if len(suffix) > 0 {
+		name += "." + formatOID(suffix)
+	}
+	return name
+}
+
+// OID translates a name in the form MODULE::name or name, followed by arcs under the node it
+// names, such as IF-MIB::ifOperStatus.3, into the dotted OID of the node followed by the arcs.
+// It is the reverse of Name. A name without its module must be defined by a single module.
+func (mib *MIB) OID(name string) (string, error) {
+	module, object := "", name
+	if i := strings.Index(name, "::"); i >= 0 {
+		module, object = name[:i], name[i+2:]
+	}
+	var suffix []uint32
+	if i := strings.IndexByte(object, '.'); i >= 0 {
+		var ok bool
+		if suffix, ok = parseOID(object[i+1:]); !ok {
+			return "", fmt.Errorf("invalid name %q", name)
+		}
+		object = object[:i]
+	}
+	if object == "" {
+		return "", fmt.Errorf("invalid name %q", name)
+	}
+
+	var found []*Node
+	mib.walk(func(node *Node) {
+		if node.Name == object && (module == "" || node.Module == module) {
+			found = append(found, node)
+		}
+	})
+	switch {
+	case len(found) == 0:
+		return "", fmt.Errorf("%s is not defined", strings.SplitN(name, ".", 2)[0])
+	case len(found) > 1:
+		modules := make([]string, len(found))
+		for i, node := range found {
+			modules[i] = node.Module
+		}
+		return "", fmt.Errorf("%s is ambiguous, it is defined by %s", object, strings.Join(modules, ", "))
+	}
+
+	oid := found[0].OID
+	if len(suffix) > 0 {
+		oid += "." + formatOID(suffix)
+	}
+	return oid, nil
+}
+
+// walk calls a function for each named node of the tree, depth first in the order of their OIDs
+func (mib *MIB) walk(fn func(node *Node)) {
+	var walk func(node *Node)
+	walk = func(node *Node) {
+		if node.Name != "" {
+			fn(node)
+		}
+		arcs := make([]uint32, 0, len(node.children))
+		for arc := range node.children {
+			arcs = append(arcs, arc)
+		}
+		sort.Slice(arcs, func(i, j int) bool { return arcs[i] < arcs[j] })
+		for _, arc := range arcs {
+			walk(node.children[arc])
+		}
+	}
+	walk(mib.root)
+}
+
+// parseOID parses a dotted OID, with or without a leading dot
+func parseOID(oid string) ([]uint32, bool) {
+	oid = strings.TrimPrefix(oid, ".")
+	if oid == "" {
+		return nil, false
+	}
+	parts := strings.Split(oid, ".")
+	arcs := make([]uint32, len(parts))
+	for i, part := range parts {
+		arc, err := strconv.ParseUint(part, 10, 32)
+		if err != nil {
+			return nil, false
+		}
+		arcs[i] = uint32(arc)
+	}
+	return arcs, true
+}
+
+// formatOID formats arcs as a dotted OID without a leading dot
+func formatOID(arcs []uint32) string {
+	parts := make([]string, len(arcs))
+	for i, arc := range arcs {
+		parts[i] = strconv.FormatUint(uint64(arc), 10)
+	}
+	return strings.Join(parts, ".")
+}
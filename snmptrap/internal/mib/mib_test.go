@@ -0,0 +1,216 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package mib // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver/internal/mib"
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	mib, err := Load(filepath.Join("testdata", "mibs"))
+	require.NoError(t, err)
+
+	type testCase struct {
+		name     string
+		oid      string
+		expected string
+	}
+
+	testCases := []testCase{
+		{name: "Column", oid: ".1.3.6.1.2.1.2.2.1.8.3", expected: "IF-MIB::ifOperStatus.3"},
+		{name: "WithoutLeadingDot", oid: "1.3.6.1.2.1.2.2.1.8.3", expected: "IF-MIB::ifOperStatus.3"},
+		{name: "ImportedParent", oid: ".1.3.6.1.6.3.1.1.5.3", expected: "IF-MIB::linkDown"},
+		{name: "Scalar", oid: ".1.3.6.1.2.1.1.3.0", expected: "SNMPv2-MIB::sysUpTime.0"},
+		{name: "TrapOID", oid: ".1.3.6.1.6.3.1.1.4.1.0", expected: "SNMPv2-MIB::snmpTrapOID.0"},
+		{name: "CoreSMI", oid: ".1.3.6.1.4.1.9.9.41", expected: "SNMPv2-SMI::enterprises.9.9.41"},
+		{name: "Root", oid: ".1.2.840", expected: "iso.2.840"},
+		{name: "NumericRoot", oid: ".0.0", expected: "SNMPv2-SMI::zeroDotZero"},
+		{name: "SMIv1", oid: ".1.3.6.1.4.1.32473.2.1.1.2.4", expected: "EXAMPLE-TRAP-MIB::exampleFanStatus.4"},
+		{name: "TrapType", oid: ".1.3.6.1.4.1.32473.1.1.0.1", expected: "EXAMPLE-TRAP-MIB::exampleFanFailure"},
+		{name: "NotImported", oid: ".1.3.6.1.4.1.32473.2.2.0", expected: "EXAMPLE-TRAP-MIB::exampleLabel.0"},
+		{name: "UnknownRoot", oid: ".3.1", expected: ""},
+		{name: "Invalid", oid: ".1.3.six", expected: ""},
+		{name: "Empty", oid: "", expected: ""},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, mib.Name(test.oid))
+		})
+	}
+
+	node, suffix := mib.Lookup(".1.3.6.1.2.1.2.2.1.8.3")
+	require.Equal(t, "ifOperStatus", node.Name)
+	require.Equal(t, "IF-MIB", node.Module)
+	require.Equal(t, "1.3.6.1.2.1.2.2.1.8", node.OID)
+	require.Equal(t, "OBJECT-TYPE", node.Kind)
+	require.Equal(t, "current", node.Status)
+	require.Equal(t, "The current operational state of the interface.", node.Description)
+	require.Equal(t, []uint32{3}, suffix)
+}
+
+func TestOID(t *testing.T) {
+	mib, err := LoadStandard(filepath.Join("testdata", "mibs"))
+	require.NoError(t, err)
+
+	type testCase struct {
+		name        string
+		input       string
+		expected    string
+		expectedErr string
+	}
+
+	testCases := []testCase{
+		{name: "Qualified", input: "IF-MIB::ifOperStatus.3", expected: "1.3.6.1.2.1.2.2.1.8.3"},
+		{name: "Unqualified", input: "linkDown", expected: "1.3.6.1.6.3.1.1.5.3"},
+		{name: "Root", input: "iso.2.840", expected: "1.2.840"},
+		{name: "ClosestAncestor", input: "SNMPv2-SMI::enterprises.9.9.41", expected: "1.3.6.1.4.1.9.9.41"},
+		{name: "Undefined", input: "IF-MIB::ifUndefined.3", expectedErr: "IF-MIB::ifUndefined is not defined"},
+		{name: "OtherModule", input: "SNMPv2-MIB::ifIndex", expectedErr: "SNMPv2-MIB::ifIndex is not defined"},
+		{name: "InvalidArcs", input: "ifIndex.x", expectedErr: `invalid name "ifIndex.x"`},
+		{name: "Empty", input: "", expectedErr: `invalid name ""`},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			oid, err := mib.OID(test.input)
+			if test.expectedErr != "" {
+				require.EqualError(t, err, test.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expected, oid)
+		})
+	}
+
+	// Names defined by several modules must be qualified
+	dir := t.TempDir()
+	for i, name := range []string{"FIRST-MIB", "SECOND-MIB"} {
+		module := fmt.Sprintf("%s DEFINITIONS ::= BEGIN\nIMPORTS enterprises FROM SNMPv2-SMI;\nexampleShared OBJECT IDENTIFIER ::= { enterprises 32473 9 %d }\nEND\n", name, i)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(module), 0o600))
+	}
+	mib, err = Load(dir)
+	require.NoError(t, err)
+	_, err = mib.OID("exampleShared")
+	require.EqualError(t, err, "exampleShared is ambiguous, it is defined by FIRST-MIB, SECOND-MIB")
+	oid, err := mib.OID("SECOND-MIB::exampleShared.1")
+	require.NoError(t, err)
+	require.Equal(t, "1.3.6.1.4.1.32473.9.1.1", oid)
+}
+
+// SMIv2 modules name the OIDs that SMIv1 modules also define
+func TestLoadPrefersSMIv2(t *testing.T) {
+	mib, err := Load()
+	require.NoError(t, err)
+	require.Equal(t, "SNMPv2-SMI::enterprises", mib.Name(".1.3.6.1.4.1"))
+}
+
+func TestLoadStandard(t *testing.T) {
+	mib, err := LoadStandard()
+	require.NoError(t, err)
+
+	type testCase struct {
+		name     string
+		oid      string
+		expected string
+	}
+
+	testCases := []testCase{
+		{name: "SNMPv2", oid: ".1.3.6.1.6.3.1.1.5.1", expected: "SNMPv2-MIB::coldStart"},
+		{name: "IF", oid: ".1.3.6.1.2.1.31.1.1.1.18.2", expected: "IF-MIB::ifAlias.2"},
+		{name: "Framework", oid: ".1.3.6.1.6.3.10.2.1.1.0", expected: "SNMP-FRAMEWORK-MIB::snmpEngineID.0"},
+		{name: "Entity", oid: ".1.3.6.1.2.1.47.2.0.1", expected: "ENTITY-MIB::entConfigChange"},
+		{name: "HostResources", oid: ".1.3.6.1.2.1.25.2.3.1.3.1", expected: "HOST-RESOURCES-MIB::hrStorageDescr.1"},
+		{name: "IP", oid: ".1.3.6.1.2.1.4.34.1.3.1.4.192.0.2.1", expected: "IP-MIB::ipAddressIfIndex.1.4.192.0.2.1"},
+		{name: "GenericTrapReplacedBySMIv2", oid: ".1.3.6.1.6.3.1.1.5.3", expected: "IF-MIB::linkDown"},
+		{name: "GenericTrap", oid: ".1.3.6.1.6.3.1.1.5.6", expected: "RFC1215-TRAPS::egpNeighborLoss"},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, mib.Name(test.oid))
+		})
+	}
+
+	egpNeighborLoss := mib.Notification(".1.3.6.1.6.3.1.1.5.6")
+	require.Len(t, egpNeighborLoss.Objects, 1)
+	require.Equal(t, "egpNeighAddr", egpNeighborLoss.Objects[0].Name)
+
+	// The modules of the directories replace the standard ones with the same name
+	layered, err := LoadStandard(filepath.Join("testdata", "mibs"))
+	require.NoError(t, err)
+	require.Equal(t, "EXAMPLE-TRAP-MIB::exampleFanFailure", layered.Name(".1.3.6.1.4.1.32473.1.1.0.1"))
+	require.Equal(t, "IF-MIB::ifOperStatus.3", layered.Name(".1.3.6.1.2.1.2.2.1.8.3"))
+	// The IF-MIB of the directory has no ifXTable
+	require.Equal(t, "IF-MIB::ifMIBObjects.1.1.18.2", layered.Name(".1.3.6.1.2.1.31.1.1.1.18.2"))
+	require.Equal(t, "ENTITY-MIB::entConfigChange", layered.Name(".1.3.6.1.2.1.47.2.0.1"))
+}
+
+func TestLoadErrors(t *testing.T) {
+	mib, err := Load(filepath.Join("testdata", "broken"), filepath.Join("testdata", "missing"))
+	require.Error(t, err)
+
+	file := filepath.Join("testdata", "broken", "UNRESOLVED-MIB")
+	for _, expected := range []string{
+		filepath.Join("testdata", "broken", "BROKEN-MIB") + ":17: unexpected 'read-only', expected '('",
+		file + ":7: imported module MISSING-MIB was not found",
+		file + ":15: nowhere used by unresolvedOther is not defined",
+		file + ":17: type UndefinedType is not defined",
+		file + ":24: undefinedObject used by unresolvedNotification is not defined",
+		"failed to read MIB directory " + filepath.Join("testdata", "missing"),
+	} {
+		require.ErrorContains(t, err, expected)
+	}
+	// Objects under an unresolved object aren't reported again
+	require.NotContains(t, err.Error(), "unresolvedGrandChild")
+
+	// The objects which could be resolved are still usable
+	require.Equal(t, "UNRESOLVED-MIB::unresolved.1", mib.Name(".1.3.6.1.4.1.32473.98.1"))
+	node, _ := mib.Lookup(".1.3.6.1.4.1.32473.98.2")
+	require.Equal(t, "unresolvedType", node.Name)
+	require.Nil(t, node.Type)
+
+	// Notifications keep the objects which could be resolved
+	notification := mib.Notification(".1.3.6.1.4.1.32473.98.0.1")
+	require.Len(t, notification.Objects, 1)
+	require.Equal(t, "unresolvedType", notification.Objects[0].Name)
+}
+
+func TestNotification(t *testing.T) {
+	mib, err := Load(filepath.Join("testdata", "mibs"))
+	require.NoError(t, err)
+
+	objects := func(node *Node) []string {
+		var names []string
+		for _, object := range node.Objects {
+			names = append(names, object.Module+"::"+object.Name)
+		}
+		return names
+	}
+
+	linkDown := mib.Notification(".1.3.6.1.6.3.1.1.5.3")
+	require.Equal(t, "linkDown", linkDown.Name)
+	require.Equal(t, "IF-MIB", linkDown.Module)
+	require.Equal(t, "NOTIFICATION-TYPE", linkDown.Kind)
+	require.Equal(t, []string{"IF-MIB::ifIndex", "IF-MIB::ifAdminStatus", "IF-MIB::ifOperStatus"}, objects(linkDown))
+
+	fanFailure := mib.Notification("1.3.6.1.4.1.32473.1.1.0.1")
+	require.Equal(t, "TRAP-TYPE", fanFailure.Kind)
+	require.Equal(t, "A fan failed.", fanFailure.Description)
+	require.Equal(t, []string{"EXAMPLE-TRAP-MIB::exampleFanIndex", "EXAMPLE-TRAP-MIB::exampleFanStatus"}, objects(fanFailure))
+
+	coldStart := mib.Notification(".1.3.6.1.6.3.1.1.5.1")
+	require.Equal(t, "coldStart", coldStart.Name)
+	require.Empty(t, coldStart.Objects)
+
+	// Only notifications are returned, and only at their exact OID
+	require.Nil(t, mib.Notification(".1.3.6.1.2.1.2.2.1.1"))
+	require.Nil(t, mib.Notification(".1.3.6.1.6.3.1.1.5.3.1"))
+	require.Nil(t, mib.Notification("invalid"))
+}
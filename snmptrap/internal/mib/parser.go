@@ -0,0 +1,661 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package mib // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver/internal/mib"
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// Error is a problem found in a MIB file, at the line it was found on
+type Error struct {
+	File string
+	Line int
+	Msg  string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Msg)
+}
+
+// errNotMIB is returned for files which don't start with a module definition.
+// Such files are skipped, since MIB directories often hold READMEs and the like.
+var errNotMIB = errors.New("not a MIB module")
+
+// module is a MIB module as written in its file, before the names it uses are resolved
+type module struct {
+	name string
+	file string
+	line int
+	// imports maps each imported symbol to the module it is imported from
+	imports map[string]string
+	// importLines is the line each imported module is named on
+	importLines map[string]int
+	objects     []*object
+	types       map[string]*typeDef
+}
+
+// object is a value assignment which names an OID, either an OBJECT IDENTIFIER
+// assignment or an invocation of a macro such as OBJECT-TYPE or NOTIFICATION-TYPE
+type object struct {
+	name  string
+	macro string
+	line  int
+	oid   []oidComponent
+
+	syntax      *syntax
+	units       string
+	access      string
+	status      string
+	description string
+	hint        string
+	index       []indexItem
+	augments    string
+	// objects holds the OBJECTS of a NOTIFICATION-TYPE or OBJECT-GROUP, or the VARIABLES of a TRAP-TYPE
+	objects []string
+	// enterprise is the ENTERPRISE of a TRAP-TYPE, whose value is then the specific-trap number
+	enterprise string
+}
+
+// oidComponent is one component of an OID value, such as "iso", "org(3)" or "6"
+type oidComponent struct {
+	name      string
+	number    uint32
+	hasNumber bool
+}
+
+// typeDef is a type assignment, which is a TEXTUAL-CONVENTION when tc is set
+type typeDef struct {
+	name        string
+	line        int
+	tc          bool
+	hint        string
+	status      string
+	description string
+	syntax      *syntax
+}
+
+// syntax is the type of an OBJECT-TYPE or a type assignment
+type syntax struct {
+	// base is INTEGER, OCTET STRING, OBJECT IDENTIFIER, BITS, SEQUENCE, SEQUENCE OF, CHOICE,
+	// or the name of the type it refines
+	base string
+	// named holds the enumeration of an INTEGER or the bits of a BITS
+	named []NamedNumber
+	// sizes holds the SIZE constraint of a string
+	sizes []sizeRange
+}
+
+// NamedNumber is a label of an enumeration or of a BITS
+type NamedNumber struct {
+	Name   string
+	Number int64
+}
+
+type sizeRange struct {
+	min, max int64
+}
+
+type indexItem struct {
+	name    string
+	implied bool
+}
+
+type parser struct {
+	file   string
+	tokens []token
+	pos    int
+}
+
+// parseFile parses the modules defined in a MIB file
+func parseFile(file string, data []byte) ([]*module, error) {
+	tokens, err := lex(file, data)
+	if len(tokens) < 2 || tokens[0].kind != tokenIdent || (tokens[1].text != "DEFINITIONS" && tokens[1].text != "{") {
+		return nil, errNotMIB
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{file: file, tokens: tokens}
+	var modules []*module
+	for p.peek().kind != tokenEOF {
+		module, err := p.parseModule()
+		if err != nil {
+			return nil, err
+		}
+		modules = append(modules, module)
+	}
+	return modules, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) errorf(tok token, format string, args ...any) error {
+	return &Error{File: p.file, Line: tok.line, Msg: fmt.Sprintf(format, args...)}
+}
+
+// unexpected reports a token which doesn't fit where it was found
+func (p *parser) unexpected(tok token, expected string) error {
+	if tok.kind == tokenEOF {
+		return p.errorf(tok, "unexpected end of file, expected %s", expected)
+	}
+	return p.errorf(tok, "unexpected '%s', expected %s", tok.text, expected)
+}
+
+func (p *parser) expect(text string) error {
+	if tok := p.next(); tok.text != text || tok.kind == tokenString {
+		return p.unexpected(tok, "'"+text+"'")
+	}
+	return nil
+}
+
+func (p *parser) expectIdent() (string, error) {
+	tok := p.next()
+	if tok.kind != tokenIdent {
+		return "", p.unexpected(tok, "an identifier")
+	}
+	return tok.text, nil
+}
+
+func (p *parser) expectString() (string, error) {
+	tok := p.next()
+	if tok.kind != tokenString {
+		return "", p.unexpected(tok, "a quoted string")
+	}
+	return tok.text, nil
+}
+
+// accept consumes the next token when it is the given keyword or punctuation
+func (p *parser) accept(text string) bool {
+	if tok := p.peek(); tok.text == text && (tok.kind == tokenIdent || tok.kind == tokenPunct) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// skipBalanced skips a block opened by the next token, which is "{", "(" or "[",
+// along with the blocks nested in it
+func (p *parser) skipBalanced() error {
+	open := p.next()
+	closing := map[string]string{"{": "}", "(": ")", "[": "]"}[open.text]
+	for depth := 1; depth > 0; {
+		tok := p.next()
+		switch {
+		case tok.kind == tokenEOF:
+			return p.errorf(open, "'%s' is never closed", open.text)
+		case tok.kind != tokenPunct:
+		case tok.text == open.text:
+			depth++
+		case tok.text == closing:
+			depth--
+		}
+	}
+	return nil
+}
+
+func (p *parser) parseModule() (*module, error) {
+	start := p.peek()
+	name, err := p.expectIdent()
+	if err != nil {
+		return nil, err
+	}
+	m := &module{
+		name:        name,
+		file:        p.file,
+		line:        start.line,
+		imports:     map[string]string{},
+		importLines: map[string]int{},
+		types:       map[string]*typeDef{},
+	}
+
+	if p.peek().text == "{" {
+		if err = p.skipBalanced(); err != nil {
+			return nil, err
+		}
+	}
+	if err = p.expect("DEFINITIONS"); err != nil {
+		return nil, err
+	}
+	// Skip the tagging default such as IMPLICIT TAGS
+	for p.peek().kind == tokenIdent {
+		p.next()
+	}
+	if err = p.expect("::="); err != nil {
+		return nil, err
+	}
+	if err = p.expect("BEGIN"); err != nil {
+		return nil, err
+	}
+
+	if p.accept("EXPORTS") {
+		for tok := p.next(); tok.text != ";"; tok = p.next() {
+			if tok.kind == tokenEOF {
+				return nil, p.unexpected(tok, "';'")
+			}
+		}
+	}
+	if p.peek().text == "IMPORTS" {
+		if err = p.parseImports(m); err != nil {
+			return nil, err
+		}
+	}
+
+	for !p.accept("END") {
+		if err = p.parseAssignment(m); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// parseImports parses lists of symbols followed by the module they come from, up to the ';'
+func (p *parser) parseImports(m *module) error {
+	p.next()
+	var symbols []string
+	for {
+		tok := p.next()
+		switch {
+		case tok.text == ";" && tok.kind == tokenPunct:
+			return nil
+		case tok.text == "," && tok.kind == tokenPunct:
+		case tok.text == "FROM" && tok.kind == tokenIdent:
+			from := p.peek()
+			name, err := p.expectIdent()
+			if err != nil {
+				return err
+			}
+			for _, symbol := range symbols {
+				m.imports[symbol] = name
+			}
+			if _, ok := m.importLines[name]; !ok {
+				m.importLines[name] = from.line
+			}
+			symbols = nil
+		case tok.kind == tokenIdent:
+			symbols = append(symbols, tok.text)
+		default:
+			return p.unexpected(tok, "an imported symbol")
+		}
+	}
+}
+
+// parseAssignment parses a type, value or macro assignment of a module
+func (p *parser) parseAssignment(m *module) error {
+	first := p.next()
+	if first.kind != tokenIdent {
+		return p.unexpected(first, "a definition or 'END'")
+	}
+
+	switch tok := p.peek(); {
+	case tok.text == "::=":
+		p.next()
+		return p.parseTypeAssignment(m, first)
+	case tok.text == "MACRO":
+		// Macros are part of the language understood by the parser, so their definition is skipped
+		for tok = p.next(); tok.text != "END"; tok = p.next() {
+			if tok.kind == tokenEOF {
+				return p.errorf(first, "macro %s is never ended", first.text)
+			}
+		}
+		return nil
+	case tok.kind != tokenIdent:
+		return p.unexpected(tok, "a type or a macro")
+	}
+
+	obj := &object{name: first.text, line: first.line, macro: p.next().text}
+	if obj.macro == "OBJECT" && p.accept("IDENTIFIER") {
+		obj.macro = "OBJECT IDENTIFIER"
+	}
+	if err := p.parseClauses(obj, false); err != nil {
+		return err
+	}
+	if err := p.expect("::="); err != nil {
+		return err
+	}
+
+	switch tok := p.peek(); {
+	case obj.macro == "TRAP-TYPE":
+		// The value of a TRAP-TYPE is its specific-trap number
+		number, err := p.parseNumber()
+		if err != nil {
+			return err
+		}
+		obj.oid = []oidComponent{{number: uint32(number), hasNumber: true}}
+	case tok.text == "{":
+		oid, err := p.parseOIDValue()
+		if err != nil {
+			return err
+		}
+		obj.oid = oid
+	default:
+		// Values other than OIDs don't name anything
+		p.next()
+		return nil
+	}
+	m.objects = append(m.objects, obj)
+	return nil
+}
+
+// parseTypeAssignment parses what follows the "::=" of a type assignment
+func (p *parser) parseTypeAssignment(m *module, name token) error {
+	def := &typeDef{name: name.text, line: name.line}
+	if p.accept("TEXTUAL-CONVENTION") {
+		obj := &object{name: name.text}
+		if err := p.parseClauses(obj, true); err != nil {
+			return err
+		}
+		def.tc = true
+		def.hint = obj.hint
+		def.status = obj.status
+		def.description = obj.description
+		def.syntax = obj.syntax
+	} else {
+		syntax, err := p.parseSyntax()
+		if err != nil {
+			return err
+		}
+		def.syntax = syntax
+	}
+	m.types[def.name] = def
+	return nil
+}
+
+// parseClauses parses the clauses of a macro invocation, up to its "::=". The clauses of a
+// TEXTUAL-CONVENTION aren't followed by a value, so they end with its SYNTAX instead.
+// Clauses which don't matter for naming and rendering OIDs are skipped.
+func (p *parser) parseClauses(obj *object, untilSyntax bool) error {
+	for {
+		tok := p.peek()
+		switch {
+		case tok.kind == tokenEOF:
+			return p.unexpected(tok, "'::='")
+		case tok.text == "::=" && !untilSyntax:
+			return nil
+		case tok.kind == tokenPunct && (tok.text == "{" || tok.text == "("):
+			if err := p.skipBalanced(); err != nil {
+				return err
+			}
+			continue
+		}
+		p.next()
+		if tok.kind != tokenIdent {
+			continue
+		}
+
+		var err error
+		switch tok.text {
+		case "SYNTAX":
+			if obj.syntax, err = p.parseSyntax(); err == nil && untilSyntax {
+				return nil
+			}
+		case "UNITS":
+			obj.units, err = p.expectString()
+		case "MAX-ACCESS", "ACCESS":
+			obj.access, err = p.expectIdent()
+		case "STATUS":
+			obj.status, err = p.expectIdent()
+		case "DESCRIPTION":
+			// Later descriptions belong to the REVISIONs of a MODULE-IDENTITY or to the groups of a MODULE-COMPLIANCE
+			var description string
+			if description, err = p.expectString(); obj.description == "" {
+				obj.description = description
+			}
+		case "DISPLAY-HINT":
+			obj.hint, err = p.expectString()
+		case "INDEX":
+			obj.index, err = p.parseIndex()
+		case "AUGMENTS":
+			var names []string
+			if names, err = p.parseNameList(); err == nil && len(names) == 1 {
+				obj.augments = names[0]
+			}
+		case "OBJECTS", "VARIABLES":
+			obj.objects, err = p.parseNameList()
+		case "ENTERPRISE":
+			obj.enterprise, err = p.expectIdent()
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// parseSyntax parses a type, keeping its base type, its named numbers and its SIZE
+func (p *parser) parseSyntax() (*syntax, error) {
+	// Skip the tag of the SMI application types, such as [APPLICATION 0] IMPLICIT
+	if p.peek().text == "[" {
+		if err := p.skipBalanced(); err != nil {
+			return nil, err
+		}
+		if !p.accept("IMPLICIT") {
+			p.accept("EXPLICIT")
+		}
+	}
+
+	tok := p.peek()
+	name, err := p.expectIdent()
+	if err != nil {
+		return nil, err
+	}
+	s := &syntax{base: name}
+	switch name {
+	case "OCTET":
+		if err = p.expect("STRING"); err != nil {
+			return nil, err
+		}
+		s.base = "OCTET STRING"
+	case "OBJECT":
+		if err = p.expect("IDENTIFIER"); err != nil {
+			return nil, err
+		}
+		s.base = "OBJECT IDENTIFIER"
+	case "SEQUENCE":
+		if p.accept("OF") {
+			s.base = "SEQUENCE OF"
+			if _, err = p.expectIdent(); err != nil {
+				return nil, err
+			}
+			return s, nil
+		}
+		return s, p.skipBalanced()
+	case "CHOICE":
+		return s, p.skipBalanced()
+	}
+
+	if p.peek().text == "{" {
+		if s.named, err = p.parseNamedNumbers(); err != nil {
+			return nil, err
+		}
+	} else if name == "BITS" {
+		return nil, p.unexpected(p.peek(), "the bits of "+tok.text)
+	}
+	if p.peek().text == "(" {
+		if s.sizes, err = p.parseConstraint(); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// parseNamedNumbers parses an enumeration or a list of bits such as { up(1), down(2) }
+func (p *parser) parseNamedNumbers() ([]NamedNumber, error) {
+	p.next()
+	var named []NamedNumber
+	for {
+		name, err := p.expectIdent()
+		if err != nil {
+			return nil, err
+		}
+		if err = p.expect("("); err != nil {
+			return nil, err
+		}
+		number, err := p.parseNumber()
+		if err != nil {
+			return nil, err
+		}
+		if err = p.expect(")"); err != nil {
+			return nil, err
+		}
+		named = append(named, NamedNumber{Name: name, Number: number})
+
+		if tok := p.next(); tok.text == "}" {
+			return named, nil
+		} else if tok.text != "," {
+			return nil, p.unexpected(tok, "',' or '}'")
+		}
+	}
+}
+
+// parseConstraint parses a parenthesized constraint, returning its ranges when it is a SIZE.
+// Value ranges such as (0..100) are skipped.
+func (p *parser) parseConstraint() ([]sizeRange, error) {
+	if p.tokens[p.pos+1].text != "SIZE" {
+		return nil, p.skipBalanced()
+	}
+	p.pos += 2
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	var sizes []sizeRange
+	for {
+		min, err := p.parseNumber()
+		if err != nil {
+			return nil, err
+		}
+		size := sizeRange{min: min, max: min}
+		if p.accept("..") {
+			if size.max, err = p.parseNumber(); err != nil {
+				return nil, err
+			}
+		}
+		sizes = append(sizes, size)
+
+		if tok := p.next(); tok.text == ")" {
+			break
+		} else if tok.text != "|" {
+			return nil, p.unexpected(tok, "'|' or ')'")
+		}
+	}
+	return sizes, p.expect(")")
+}
+
+func (p *parser) parseNumber() (int64, error) {
+	tok := p.next()
+	if tok.kind != tokenNumber {
+		return 0, p.unexpected(tok, "a number")
+	}
+	number, err := strconv.ParseInt(tok.text, 10, 64)
+	if err != nil {
+		// Counter64 ranges go up to 18446744073709551615
+		unsigned, uerr := strconv.ParseUint(tok.text, 10, 64)
+		if uerr != nil {
+			return 0, p.errorf(tok, "invalid number '%s'", tok.text)
+		}
+		number = int64(unsigned)
+	}
+	return number, nil
+}
+
+// parseOIDValue parses an OID value such as { iso org(3) dod(6) 1 }
+func (p *parser) parseOIDValue() ([]oidComponent, error) {
+	open := p.next()
+	var oid []oidComponent
+	for {
+		tok := p.next()
+		switch {
+		case tok.text == "}" && tok.kind == tokenPunct:
+			if len(oid) == 0 {
+				return nil, p.errorf(open, "empty OID value")
+			}
+			return oid, nil
+		case tok.kind == tokenNumber:
+			number, err := strconv.ParseUint(tok.text, 10, 32)
+			if err != nil {
+				return nil, p.errorf(tok, "invalid OID component '%s'", tok.text)
+			}
+			oid = append(oid, oidComponent{number: uint32(number), hasNumber: true})
+		case tok.kind == tokenIdent:
+			component := oidComponent{name: tok.text}
+			if p.accept("(") {
+				number, err := p.parseNumber()
+				if err != nil {
+					return nil, err
+				}
+				if number < 0 || number > 1<<32-1 {
+					return nil, p.errorf(tok, "invalid OID component '%s(%d)'", tok.text, number)
+				}
+				component.number, component.hasNumber = uint32(number), true
+				if err = p.expect(")"); err != nil {
+					return nil, err
+				}
+			}
+			oid = append(oid, component)
+		default:
+			return nil, p.unexpected(tok, "an OID component")
+		}
+	}
+}
+
+// parseIndex parses the objects of an INDEX clause, some of which may be IMPLIED.
+// SMIv1 modules may also list types there, in which case the type name is kept.
+func (p *parser) parseIndex() ([]indexItem, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var index []indexItem
+	var item indexItem
+	for {
+		tok := p.next()
+		switch {
+		case tok.kind == tokenIdent && tok.text == "IMPLIED":
+			item.implied = true
+		case tok.kind == tokenIdent:
+			item.name = tok.text
+		case tok.text == "," || tok.text == "}":
+			if item.name == "" {
+				return nil, p.unexpected(tok, "an index object")
+			}
+			index = append(index, item)
+			if tok.text == "}" {
+				return index, nil
+			}
+			item = indexItem{}
+		default:
+			return nil, p.unexpected(tok, "an index object")
+		}
+	}
+}
+
+// parseNameList parses a list of names such as { ifIndex, ifAdminStatus }
+func (p *parser) parseNameList() ([]string, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var names []string
+	for {
+		name, err := p.expectIdent()
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+		if tok := p.next(); tok.text == "}" {
+			return names, nil
+		} else if tok.text != "," {
+			return nil, p.unexpected(tok, "',' or '}'")
+		}
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package mib // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver/internal/mib"
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLex(t *testing.T) {
+	tokens, err := lex("test", []byte(`a-b ::= { c(1) } -- comment -- d
+"two
+lines" '0A'H -12 1..2 -- to the end of the line
+e`))
+	require.NoError(t, err)
+
+	var texts []string
+	for _, tok := range tokens {
+		texts = append(texts, tok.text)
+	}
+	require.Equal(t, []string{"a-b", "::=", "{", "c", "(", "1", ")", "}", "d", "two\nlines", "'0A'H", "-12", "1", "..", "2", "e", ""}, texts)
+	require.Equal(t, 1, tokens[8].line)
+	require.Equal(t, 2, tokens[9].line)
+	require.Equal(t, 4, tokens[15].line)
+	require.Equal(t, tokenEOF, tokens[16].kind)
+}
+
+func TestParseFile(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "mibs", "EXAMPLE-TRAP-MIB.my"))
+	require.NoError(t, err)
+	modules, err := parseFile("EXAMPLE-TRAP-MIB.my", data)
+	require.NoError(t, err)
+	require.Len(t, modules, 1)
+
+	m := modules[0]
+	require.Equal(t, "EXAMPLE-TRAP-MIB", m.name)
+	require.Equal(t, map[string]string{
+		"enterprises": "RFC1155-SMI",
+		"Counter":     "RFC1155-SMI",
+		"OBJECT-TYPE": "RFC-1212",
+		"TRAP-TYPE":   "RFC-1215",
+	}, m.imports)
+	require.Contains(t, m.types, "ExampleFanEntry")
+
+	objects := map[string]*object{}
+	for _, obj := range m.objects {
+		objects[obj.name] = obj
+	}
+	require.Len(t, objects, 11)
+
+	require.Equal(t, []oidComponent{{name: "enterprises"}, {number: 32473, hasNumber: true}}, objects["example"].oid)
+
+	status := objects["exampleFanStatus"]
+	require.Equal(t, "OBJECT-TYPE", status.macro)
+	require.Equal(t, "read-only", status.access)
+	require.Equal(t, "mandatory", status.status)
+	require.Equal(t, `The status of the fan, with a "quoted" word.`, status.description)
+	require.Equal(t, &syntax{base: "INTEGER", named: []NamedNumber{{"ok", 1}, {"failed", 2}, {"absent", -1}}}, status.syntax)
+
+	require.Equal(t, []indexItem{{name: "exampleFanIndex"}}, objects["exampleFanEntry"].index)
+
+	trap := objects["exampleFanFailure"]
+	require.Equal(t, "TRAP-TYPE", trap.macro)
+	require.Equal(t, "exampleSwitch", trap.enterprise)
+	require.Equal(t, []string{"exampleFanIndex", "exampleFanStatus"}, trap.objects)
+	require.Equal(t, []oidComponent{{number: 1, hasNumber: true}}, trap.oid)
+}
+
+func TestParseSyntax(t *testing.T) {
+	type testCase struct {
+		name     string
+		text     string
+		expected *syntax
+	}
+
+	testCases := []testCase{
+		{name: "Integer", text: "Integer32 (1..10)", expected: &syntax{base: "Integer32"}},
+		{name: "Enumeration", text: "INTEGER { true(1), false(2) }", expected: &syntax{base: "INTEGER", named: []NamedNumber{{"true", 1}, {"false", 2}}}},
+		{name: "Bits", text: "BITS { a(0), b(7) }", expected: &syntax{base: "BITS", named: []NamedNumber{{"a", 0}, {"b", 7}}}},
+		{name: "FixedSize", text: "OCTET STRING (SIZE (6))", expected: &syntax{base: "OCTET STRING", sizes: []sizeRange{{6, 6}}}},
+		{name: "Sizes", text: "OCTET STRING (SIZE (8 | 11))", expected: &syntax{base: "OCTET STRING", sizes: []sizeRange{{8, 8}, {11, 11}}}},
+		{name: "SizeRange", text: "DisplayString (SIZE(0..255))", expected: &syntax{base: "DisplayString", sizes: []sizeRange{{0, 255}}}},
+		{name: "ApplicationType", text: "[APPLICATION 6] IMPLICIT INTEGER (0..18446744073709551615)", expected: &syntax{base: "INTEGER"}},
+		{name: "ObjectIdentifier", text: "OBJECT IDENTIFIER", expected: &syntax{base: "OBJECT IDENTIFIER"}},
+		{name: "SequenceOf", text: "SEQUENCE OF IfEntry", expected: &syntax{base: "SEQUENCE OF"}},
+		{name: "Sequence", text: "SEQUENCE { a INTEGER, b OCTET STRING (SIZE (4)) }", expected: &syntax{base: "SEQUENCE"}},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			tokens, err := lex("test", []byte(test.text))
+			require.NoError(t, err)
+			p := &parser{file: "test", tokens: tokens}
+			s, err := p.parseSyntax()
+			require.NoError(t, err)
+			require.Equal(t, test.expected, s)
+			require.Equal(t, tokenEOF, p.peek().kind)
+		})
+	}
+}
+
+func TestParseFileErrors(t *testing.T) {
+	type testCase struct {
+		name        string
+		text        string
+		expectedErr string
+	}
+
+	testCases := []testCase{
+		{
+			name:        "UnterminatedString",
+			text:        "A-MIB DEFINITIONS ::= BEGIN\na OBJECT-TYPE\nDESCRIPTION \"never closed\n::= { b 1 }\nEND\n",
+			expectedErr: "A-MIB:3: unterminated string",
+		},
+		{
+			name:        "MissingEnd",
+			text:        "A-MIB DEFINITIONS ::= BEGIN\na OBJECT IDENTIFIER ::= { b 1 }\n",
+			expectedErr: "A-MIB:3: unexpected end of file, expected a definition or 'END'",
+		},
+		{
+			name:        "BadEnumeration",
+			text:        "A-MIB DEFINITIONS ::= BEGIN\na OBJECT-TYPE\nSYNTAX INTEGER { up(1), down }\n::= { b 1 }\nEND\n",
+			expectedErr: "A-MIB:3: unexpected '}', expected '('",
+		},
+		{
+			name:        "EmptyOID",
+			text:        "A-MIB DEFINITIONS ::= BEGIN\na OBJECT IDENTIFIER ::= { }\nEND\n",
+			expectedErr: "A-MIB:2: empty OID value",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := parseFile("A-MIB", []byte(test.text))
+			require.EqualError(t, err, test.expectedErr)
+		})
+	}
+
+	_, err := parseFile("README", []byte("Some notes about the MIBs of this directory"))
+	require.ErrorIs(t, err, errNotMIB)
+}
@@ -0,0 +1,156 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package mib // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver/internal/mib"
+
+import (
+	"strconv"
+)
+
+// Base types of the SMI, which the syntax of every object refines
+const (
+	BaseInteger          = "INTEGER"
+	BaseOctetString      = "OCTET STRING"
+	BaseObjectIdentifier = "OBJECT IDENTIFIER"
+	BaseBits             = "BITS"
+	BaseIPAddress        = "IpAddress"
+	BaseCounter32        = "Counter32"
+	BaseGauge32          = "Gauge32"
+	BaseTimeTicks        = "TimeTicks"
+	BaseOpaque           = "Opaque"
+	BaseCounter64        = "Counter64"
+)
+
+// applicationTypes maps the types defined by SNMPv2-SMI and RFC1155-SMI to their base type
+var applicationTypes = map[string]string{
+	"Integer32":      BaseInteger,
+	"IpAddress":      BaseIPAddress,
+	"NetworkAddress": BaseIPAddress,
+	"Counter32":      BaseCounter32,
+	"Counter":        BaseCounter32,
+	"Gauge32":        BaseGauge32,
+	"Gauge":          BaseGauge32,
+	"Unsigned32":     BaseGauge32,
+	"TimeTicks":      BaseTimeTicks,
+	"Opaque":         BaseOpaque,
+	"Counter64":      BaseCounter64,
+}
+
+// Type is the resolved SYNTAX of an object
+type Type struct {
+	// Name is the textual convention or the type the syntax refers to, such as TruthValue.
+	// It is empty when the syntax is written with a base type.
+	Name   string
+	Module string
+	Base   string
+	// Named holds the enumeration of an INTEGER or the labels of a BITS
+	Named []NamedNumber
+	// Hint is the DISPLAY-HINT of the nearest textual convention which has one
+	Hint string
+
+	sizes []sizeRange
+}
+
+// Label returns the label of a value of an enumeration, or of a bit of a BITS
+func (t *Type) Label(number int64) (string, bool) {
+	for _, named := range t.Named {
+		if named.Number == number {
+			return named.Name, true
+		}
+	}
+	return "", false
+}
+
+// BitLabels returns the labels of the bits set in the value of a BITS. Bit 0 is the most
+// significant bit of the first octet, RFC 2578 section 7.1.4. Bits without a label are
+// returned as their number.
+func (t *Type) BitLabels(value []byte) []string {
+	var labels []string
+	for i, octet := range value {
+		for bit := 0; bit < 8; bit++ {
+			if octet&(0x80>>bit) == 0 {
+				continue
+			}
+			number := int64(i*8 + bit)
+			label, ok := t.Label(number)
+			if !ok {
+				label = strconv.FormatInt(number, 10)
+			}
+			labels = append(labels, label)
+		}
+	}
+	return labels
+}
+
+// isCore tells whether a module is one of those defining the application types
+func isCore(m *module) bool {
+	return m.name == "SNMPv2-SMI" || m.name == "RFC1155-SMI"
+}
+
+// syntaxType resolves the syntax of an object or type of a module. Nil is returned for the
+// SEQUENCE and CHOICE types, which have no values of their own, and for unknown types.
+func (r *resolver) syntaxType(m *module, s *syntax, line int) *Type {
+	var t Type
+	switch s.base {
+	case BaseInteger, BaseOctetString, BaseObjectIdentifier, BaseBits:
+		t.Base = s.base
+	case "SEQUENCE", "SEQUENCE OF", "CHOICE":
+		return nil
+	default:
+		named := r.namedType(m, s.base, line)
+		if named == nil {
+			return nil
+		}
+		t = *named
+	}
+
+	// The syntax may refine the type it refers to, such as an Integer32 with an enumeration
+	if len(s.named) > 0 {
+		t.Named = s.named
+	}
+	if len(s.sizes) > 0 {
+		t.sizes = s.sizes
+	}
+	return &t
+}
+
+// namedType resolves a type referred to by name from a module, looking it up in the module,
+// in its imports and then in the other modules, like names of objects
+func (r *resolver) namedType(m *module, name string, line int) *Type {
+	source := m
+	def, ok := m.types[name]
+	if !ok {
+		if from, imported := m.imports[name]; imported {
+			if source, ok = r.importedModule(m, from); !ok {
+				return nil
+			}
+			def, ok = source.types[name]
+		} else if source, ok = r.globalTypes[name]; ok {
+			def = source.types[name]
+		}
+	}
+
+	if base, ok := applicationTypes[name]; ok && (def == nil || isCore(source)) {
+		return &Type{Base: base}
+	}
+	if def == nil {
+		r.errorf(m, line, "type %s is not defined", name)
+		return nil
+	}
+
+	if t, ok := r.types[def]; ok {
+		return t
+	}
+	// Types are resolved to nil while they are resolved, which stops definition loops
+	r.types[def] = nil
+	t := r.syntaxType(source, def.syntax, def.line)
+	if t != nil {
+		t.Name = def.name
+		t.Module = source.name
+		if def.hint != "" {
+			t.Hint = def.hint
+		}
+	}
+	r.types[def] = t
+	return t
+}
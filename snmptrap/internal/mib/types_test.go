@@ -0,0 +1,104 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package mib // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver/internal/mib"
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTypes(t *testing.T) {
+	mib, err := Load(filepath.Join("testdata", "mibs"))
+	require.NoError(t, err)
+
+	truthValue := []NamedNumber{{"true", 1}, {"false", 2}}
+
+	type testCase struct {
+		name     string
+		oid      string
+		expected *Type
+	}
+
+	testCases := []testCase{
+		{
+			name: "Enumeration",
+			oid:  "1.3.6.1.2.1.2.2.1.7",
+			expected: &Type{
+				Base:  BaseInteger,
+				Named: []NamedNumber{{"up", 1}, {"down", 2}, {"testing", 3}},
+			},
+		},
+		{
+			name:     "TextualConvention",
+			oid:      "1.3.6.1.4.1.32473.3.1.1.1.4",
+			expected: &Type{Name: "TruthValue", Module: "SNMPv2-TC", Base: BaseInteger, Named: truthValue},
+		},
+		{
+			name: "ImportedTextualConvention",
+			oid:  "1.3.6.1.4.1.32473.3.1.1.1.1",
+			expected: &Type{
+				Name:   "InetAddressType",
+				Module: "INET-ADDRESS-MIB",
+				Base:   BaseInteger,
+				Named:  []NamedNumber{{"unknown", 0}, {"ipv4", 1}, {"ipv6", 2}, {"ipv4z", 3}, {"ipv6z", 4}, {"dns", 16}},
+			},
+		},
+		{
+			name: "Bits",
+			oid:  "1.3.6.1.4.1.32473.3.1.1.1.5",
+			expected: &Type{
+				Name:   "ExampleAlarmFlags",
+				Module: "EXAMPLE-ALARM-MIB",
+				Base:   BaseBits,
+				Named:  []NamedNumber{{"critical", 0}, {"acknowledged", 1}, {"cleared", 2}, {"shelved", 9}},
+			},
+		},
+		{
+			name:     "HintOfRefinedType",
+			oid:      "1.3.6.1.2.1.2.2.1.1",
+			expected: &Type{Name: "InterfaceIndex", Module: "IF-MIB", Base: BaseInteger, Hint: "d"},
+		},
+		{
+			name:     "RefinedSize",
+			oid:      "1.3.6.1.2.1.2.2.1.2",
+			expected: &Type{Name: "DisplayString", Module: "SNMPv2-TC", Base: BaseOctetString, Hint: "255a", sizes: []sizeRange{{0, 255}}},
+		},
+		{
+			name:     "NotImported",
+			oid:      "1.3.6.1.4.1.32473.2.2",
+			expected: &Type{Name: "DisplayString", Module: "SNMPv2-TC", Base: BaseOctetString, Hint: "255a", sizes: []sizeRange{{0, 255}}},
+		},
+		{name: "ApplicationType", oid: "1.3.6.1.2.1.1.3", expected: &Type{Base: BaseTimeTicks}},
+		{name: "SMIv1ApplicationType", oid: "1.3.6.1.4.1.32473.2.1.1.3", expected: &Type{Base: BaseCounter32}},
+		{name: "ObjectIdentifier", oid: "1.3.6.1.6.3.1.1.4.1", expected: &Type{Base: BaseObjectIdentifier}},
+		{name: "Table", oid: "1.3.6.1.2.1.2.2", expected: nil},
+		{name: "Row", oid: "1.3.6.1.2.1.2.2.1", expected: nil},
+		{name: "Notification", oid: "1.3.6.1.6.3.1.1.5.3", expected: nil},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			node, suffix := mib.Lookup(test.oid)
+			require.Equal(t, test.oid, node.OID)
+			require.Empty(t, suffix)
+			require.Equal(t, test.expected, node.Type)
+		})
+	}
+}
+
+func TestTypeLabels(t *testing.T) {
+	flags := &Type{Base: BaseBits, Named: []NamedNumber{{"critical", 0}, {"acknowledged", 1}, {"cleared", 2}, {"shelved", 9}}}
+
+	label, ok := flags.Label(9)
+	require.True(t, ok)
+	require.Equal(t, "shelved", label)
+	_, ok = flags.Label(3)
+	require.False(t, ok)
+
+	require.Equal(t, []string{"critical", "cleared", "3", "shelved"}, flags.BitLabels([]byte{0xb0, 0x40}))
+	require.Empty(t, flags.BitLabels([]byte{0, 0}))
+	require.Empty(t, flags.BitLabels(nil))
+}
@@ -0,0 +1,155 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package pen tells which organization an OID under 1.3.6.1.4.1 belongs to, with the IANA
+// Private Enterprise Numbers registry.
+package pen // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver/internal/pen"
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+//go:generate sh -c "curl -fsSL -o enterprise-numbers https://www.iana.org/assignments/enterprise-numbers.txt && gzip -9nf enterprise-numbers"
+
+// enterpriseNumbers is a copy of the registry in the format IANA publishes it in, compressed
+// with gzip since the full registry is several megabytes
+//
+//go:embed enterprise-numbers.gz
+var enterpriseNumbers []byte
+
+// enterprisesPrefix is the OID of iso.org.dod.internet.private.enterprise, followed by the
+// number of an organization in the OIDs it defines
+const enterprisesPrefix = "1.3.6.1.4.1."
+
+var errNoEntries = errors.New("no enterprise numbers found")
+
+// Registry maps Private Enterprise Numbers to the organizations they are assigned to. It isn't
+// modified once loaded, so it can be used concurrently.
+type Registry struct {
+	organizations map[uint32]string
+}
+
+// Embedded returns the registry embedded in the package
+func Embedded() *Registry {
+	return embedded()
+}
+
+// embedded parses the embedded registry once
+var embedded = sync.OnceValue(func() *Registry {
+	r, err := gzip.NewReader(bytes.NewReader(enterpriseNumbers))
+	if err != nil {
+		panic(err)
+	}
+	registry, err := Parse(r)
+	if err != nil {
+		panic(err)
+	}
+	return registry
+})
+
+// Load reads a registry file, such as https://www.iana.org/assignments/enterprise-numbers.txt
+// once downloaded
+func Load(file string) (*Registry, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read enterprise numbers file: %w", err)
+	}
+	defer f.Close()
+
+	registry, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	return registry, nil
+}
+
+// Parse parses a registry in the format IANA publishes it in. Each entry starts with a number
+// alone on its line, and is followed by indented lines holding the organization, its contact and
+// its email address. Other lines, such as the header, are ignored.
+func Parse(r io.Reader) (*Registry, error) {
+	registry := &Registry{organizations: map[uint32]string{}}
+
+	scanner := bufio.NewScanner(r)
+	var (
+		number  uint32
+		pending bool
+		line    int
+	)
+	for scanner.Scan() {
+		line++
+		text := strings.TrimRight(scanner.Text(), " \t\r")
+		if text == "" {
+			continue
+		}
+		if text[0] != ' ' && text[0] != '\t' {
+			n, err := strconv.ParseUint(text, 10, 32)
+			if err != nil {
+				// Headers and the end of the document don't start with a digit, and entries do
+				if text[0] >= '0' && text[0] <= '9' {
+					return nil, fmt.Errorf("line %d: invalid enterprise number %q", line, text)
+				}
+				pending = false
+				continue
+			}
+			number, pending = uint32(n), true
+			continue
+		}
+		// The organization is the first line of the entry, and the contact and email follow it
+		if pending {
+			registry.organizations[number] = strings.TrimSpace(text)
+			pending = false
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(registry.organizations) == 0 {
+		return nil, errNoEntries
+	}
+	return registry, nil
+}
+
+// Organization returns the organization a number is assigned to
+func (registry *Registry) Organization(number uint32) (string, bool) {
+	organization, ok := registry.organizations[number]
+	return organization, ok
+}
+
+// Len returns the number of entries of the registry
+func (registry *Registry) Len() int {
+	return len(registry.organizations)
+}
+
+// Number returns the Private Enterprise Number of an OID under 1.3.6.1.4.1, such as the 9 of
+// .1.3.6.1.4.1.9.9.41.2.0.1
+func Number(oid string) (uint32, bool) {
+	rest, ok := strings.CutPrefix(strings.TrimPrefix(oid, "."), enterprisesPrefix)
+	if !ok {
+		return 0, false
+	}
+	arc, _, _ := strings.Cut(rest, ".")
+	number, err := strconv.ParseUint(arc, 10, 32)
+	if err != nil {
+		return 0, false
+	}
+	return uint32(number), true
+}
+
+// Vendor returns the organization which the number of an OID under 1.3.6.1.4.1 is assigned to
+func (registry *Registry) Vendor(oid string) (string, bool) {
+	number, ok := Number(oid)
+	if !ok {
+		return "", false
+	}
+	return registry.Organization(number)
+}
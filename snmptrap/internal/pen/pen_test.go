@@ -0,0 +1,162 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package pen // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver/internal/pen"
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// registry is an excerpt of the registry published by IANA, with its header, contacts and end
+const registry = `PRIVATE ENTERPRISE NUMBERS
+
+(last updated 2024-05-02)
+
+SMI Network Management Private Enterprise Codes:
+
+Prefix: iso.org.dod.internet.private.enterprise (1.3.6.1.4.1)
+
+Decimal
+| Organization
+| | Contact
+| | | Email
+| | | |
+0
+  Reserved
+    Internet Assigned Numbers Authority
+      iana&iana.org
+1
+  NxNetworks
+    Contact
+      email&example.com
+9
+  ciscoSystems
+    Contact
+      email&example.com
+32473
+  Example Enterprise Number for Documentation Use
+    IANA
+      iana&iana.org
+End of Document
+`
+
+func TestParse(t *testing.T) {
+	parsed, err := Parse(strings.NewReader(registry))
+	require.NoError(t, err)
+	require.Equal(t, 4, parsed.Len())
+
+	organization, ok := parsed.Organization(1)
+	require.True(t, ok)
+	require.Equal(t, "NxNetworks", organization)
+	organization, ok = parsed.Organization(32473)
+	require.True(t, ok)
+	require.Equal(t, "Example Enterprise Number for Documentation Use", organization)
+	_, ok = parsed.Organization(2)
+	require.False(t, ok)
+
+	type testCase struct {
+		name        string
+		data        string
+		expectedErr string
+	}
+
+	testCases := []testCase{
+		{name: "Empty", data: "", expectedErr: "no enterprise numbers found"},
+		{name: "HeaderOnly", data: "PRIVATE ENTERPRISE NUMBERS\n\nDecimal\n| Organization\n", expectedErr: "no enterprise numbers found"},
+		{name: "InvalidNumber", data: "0\n  Reserved\n1x\n  Other\n", expectedErr: `line 3: invalid enterprise number "1x"`},
+		{name: "NumberTooLarge", data: "4294967296\n  Other\n", expectedErr: `line 1: invalid enterprise number "4294967296"`},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(test.data))
+			require.EqualError(t, err, test.expectedErr)
+		})
+	}
+}
+
+func TestEmbedded(t *testing.T) {
+	embedded := Embedded()
+	require.Same(t, embedded, Embedded())
+
+	for number, expected := range map[uint32]string{
+		9:     "ciscoSystems",
+		2636:  "Juniper Networks, Inc.",
+		8072:  "net-snmp",
+		32473: "Example Enterprise Number for Documentation Use",
+	} {
+		organization, ok := embedded.Organization(number)
+		require.True(t, ok, number)
+		require.Equal(t, expected, organization)
+	}
+
+	// Every entry of the embedded file is loaded
+	r, err := gzip.NewReader(bytes.NewReader(enterpriseNumbers))
+	require.NoError(t, err)
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	var entries int
+	for _, line := range strings.Split(string(data), "\n") {
+		if _, err := strconv.ParseUint(strings.TrimSpace(line), 10, 32); err == nil {
+			entries++
+		}
+	}
+	require.Equal(t, entries, embedded.Len())
+}
+
+func TestVendor(t *testing.T) {
+	embedded := Embedded()
+
+	type testCase struct {
+		name     string
+		oid      string
+		expected string
+	}
+
+	testCases := []testCase{
+		{name: "Enterprise", oid: ".1.3.6.1.4.1.9", expected: "ciscoSystems"},
+		{name: "UnderEnterprise", oid: ".1.3.6.1.4.1.9.9.41.2.0.1", expected: "ciscoSystems"},
+		{name: "WithoutLeadingDot", oid: "1.3.6.1.4.1.2636.4.1.1", expected: "Juniper Networks, Inc."},
+		{name: "Unassigned", oid: ".1.3.6.1.4.1.4294967295.1"},
+		{name: "NotAnEnterprise", oid: ".1.3.6.1.6.3.1.1.5.3"},
+		{name: "Enterprises", oid: ".1.3.6.1.4.1"},
+		{name: "InvalidArc", oid: ".1.3.6.1.4.1.x.1"},
+		{name: "SimilarPrefix", oid: ".1.3.6.1.4.10.9"},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			vendor, ok := embedded.Vendor(test.oid)
+			require.Equal(t, test.expected != "", ok)
+			require.Equal(t, test.expected, vendor)
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "enterprise-numbers.txt")
+	require.NoError(t, os.WriteFile(file, []byte(registry), 0o600))
+
+	loaded, err := Load(file)
+	require.NoError(t, err)
+	vendor, ok := loaded.Vendor(".1.3.6.1.4.1.1.2")
+	require.True(t, ok)
+	require.Equal(t, "NxNetworks", vendor)
+
+	empty := filepath.Join(t.TempDir(), "empty.txt")
+	require.NoError(t, os.WriteFile(empty, nil, 0o600))
+	_, err = Load(empty)
+	require.EqualError(t, err, empty+": no enterprise numbers found")
+
+	_, err = Load(filepath.Join(t.TempDir(), "missing"))
+	require.ErrorContains(t, err, "failed to read enterprise numbers file")
+}
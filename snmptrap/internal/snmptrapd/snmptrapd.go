@@ -0,0 +1,434 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package snmptrapd reads the access control settings of Net-SNMP snmptrapd.conf files: the
+// authCommunity, createUser, authUser, disableAuthorization and engineID lines. The other lines,
+// such as traphandle or format, are about what snmptrapd does with notifications, which is left
+// to the pipeline, so they are ignored.
+package snmptrapd // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver/internal/snmptrapd"
+
+import (
+	"bufio"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"strings"
+)
+
+// Security levels of users, from the lowest to the highest
+const (
+	LevelNoAuth = "noauth"
+	LevelAuth   = "auth"
+	LevelPriv   = "priv"
+)
+
+// netSNMPTextEngineID starts the engine IDs of the engineID lines: the Net-SNMP enterprise number
+// with its high bit set, followed by the format of a text engine ID (RFC 3411 section 5)
+const netSNMPTextEngineID = "\x80\x00\x1f\x88\x04"
+
+// authProtocols and privProtocols map the protocols of createUser lines to their usual names
+var (
+	authProtocols = map[string]string{
+		"MD5":     "MD5",
+		"SHA":     "SHA",
+		"SHA-224": "SHA224",
+		"SHA-256": "SHA256",
+		"SHA-384": "SHA384",
+		"SHA-512": "SHA512",
+	}
+	privProtocols = map[string]string{
+		"DES":     "DES",
+		"AES":     "AES",
+		"AES-128": "AES",
+		"AES-192": "AES192",
+		"AES-256": "AES256",
+	}
+)
+
+// Config is the access control of a snmptrapd.conf file
+type Config struct {
+	// Communities are the communities of the authCommunity lines, in the order they are first given
+	Communities []Community
+	// Users are the users of the createUser lines which are authorized to send notifications:
+	// those with an authUser line, or all of them when authorization is disabled
+	Users []User
+	// DisableAuthorization is set by disableAuthorization yes, with which any community and any
+	// user is accepted
+	DisableAuthorization bool
+	// EngineID is the hex encoded engine ID of the engineID line, or empty when there is none
+	EngineID string
+}
+
+// Community is a community of authCommunity lines
+type Community struct {
+	Community string
+	// Sources are the CIDR blocks the community is accepted from, or empty for any source
+	Sources []string
+}
+
+// User is a user of a createUser line
+type User struct {
+	Name string
+	// EngineID is the hex encoded engine ID of the -e option, or empty for any engine
+	EngineID string
+	// Level is the security level of the user: noauth without an authentication protocol, auth
+	// without a privacy protocol, and priv otherwise
+	Level string
+	// AuthProtocol is MD5, SHA, SHA224, SHA256, SHA384 or SHA512, and PrivProtocol DES, AES,
+	// AES192 or AES256
+	AuthProtocol   string
+	AuthPassphrase string
+	PrivProtocol   string
+	PrivPassphrase string
+}
+
+// authorization is an authUser line
+type authorization struct {
+	level    string
+	position int
+}
+
+// Load reads a snmptrapd.conf file
+func Load(file string) (*Config, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snmptrapd configuration file: %w", err)
+	}
+	defer f.Close()
+	return Parse(f, file)
+}
+
+// Parse parses a snmptrapd.conf file. All of its problems are reported at once, with the name of
+// the file and their line.
+func Parse(r io.Reader, name string) (*Config, error) {
+	p := &parser{
+		name:           name,
+		config:         &Config{},
+		communities:    map[string]int{},
+		anySource:      map[string]bool{},
+		authorizations: map[string]authorization{},
+	}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		p.line++
+		p.parseLine(strings.TrimSpace(scanner.Text()))
+	}
+	if err := scanner.Err(); err != nil {
+		p.errs = append(p.errs, fmt.Errorf("%s: %w", name, err))
+	}
+	p.authorize()
+
+	if len(p.errs) > 0 {
+		return nil, errors.Join(p.errs...)
+	}
+	return p.config, nil
+}
+
+// parser holds the state of the parsing of a file
+type parser struct {
+	name   string
+	line   int
+	config *Config
+	errs   []error
+	// communities are the indexes of the communities of the configuration, and anySource tells
+	// which of them are accepted from any source
+	communities map[string]int
+	anySource   map[string]bool
+	// users are those of the createUser lines, which authorize filters with the authUser lines
+	users          []User
+	authorizations map[string]authorization
+	authorized     []string
+}
+
+func (p *parser) fail(line int, err error) {
+	p.errs = append(p.errs, fmt.Errorf("%s:%d: %w", p.name, line, err))
+}
+
+func (p *parser) parseLine(line string) {
+	if line == "" || strings.HasPrefix(line, "#") {
+		return
+	}
+	fields, err := splitFields(line)
+	if err != nil {
+		p.fail(p.line, err)
+		return
+	}
+
+	keyword, args := fields[0], fields[1:]
+	switch {
+	case strings.EqualFold(keyword, "authCommunity"):
+		err = p.parseAuthCommunity(args)
+	case strings.EqualFold(keyword, "createUser"):
+		err = p.parseCreateUser(args)
+	case strings.EqualFold(keyword, "authUser"):
+		err = p.parseAuthUser(args)
+	case strings.EqualFold(keyword, "disableAuthorization"):
+		err = p.parseDisableAuthorization(args)
+	case strings.EqualFold(keyword, "engineID"):
+		err = p.parseEngineID(args)
+	}
+	if err != nil {
+		p.fail(p.line, err)
+	}
+}
+
+// parseAuthCommunity parses authCommunity TYPES COMMUNITY [SOURCE [OID | -v VIEW]]
+func (p *parser) parseAuthCommunity(args []string) error {
+	if len(args) < 2 {
+		return errors.New("authCommunity must be followed by types and a community")
+	}
+	if err := checkTypes(args[0]); err != nil {
+		return err
+	}
+	if len(args) > 3 {
+		return errors.New("authCommunity restricted to an OID or a view is not supported")
+	}
+
+	community := args[1]
+	i, ok := p.communities[community]
+	if !ok {
+		i = len(p.config.Communities)
+		p.communities[community] = i
+		p.config.Communities = append(p.config.Communities, Community{Community: community})
+	}
+	if len(args) == 2 || strings.EqualFold(args[2], "default") {
+		p.anySource[community] = true
+		p.config.Communities[i].Sources = nil
+		return nil
+	}
+	source, err := parseSource(args[2])
+	if err != nil {
+		return err
+	}
+	if !p.anySource[community] {
+		p.config.Communities[i].Sources = append(p.config.Communities[i].Sources, source)
+	}
+	return nil
+}
+
+// parseCreateUser parses createUser [-e ENGINEID] NAME [AUTHPROTOCOL AUTHPASSPHRASE [PRIVPROTOCOL [PRIVPASSPHRASE]]]
+func (p *parser) parseCreateUser(args []string) error {
+	user := User{Level: LevelNoAuth}
+	if len(args) > 0 && args[0] == "-e" {
+		if len(args) < 2 {
+			return errors.New("-e must be followed by an engine ID")
+		}
+		engineID := strings.TrimPrefix(strings.ToLower(args[1]), "0x")
+		if _, err := hex.DecodeString(engineID); err != nil || engineID == "" {
+			return fmt.Errorf("invalid engine ID %q: must be hexadecimal", args[1])
+		}
+		user.EngineID, args = engineID, args[2:]
+	}
+	if len(args) == 0 {
+		return errors.New("createUser must be followed by a user name")
+	}
+	user.Name, args = args[0], args[1:]
+	for _, arg := range args {
+		if arg == "-l" || arg == "-m" {
+			return errors.New("createUser with localized or master keys is not supported, the passphrases must be given")
+		}
+	}
+
+	if len(args) > 0 {
+		protocol, ok := authProtocols[strings.ToUpper(args[0])]
+		if !ok {
+			return fmt.Errorf("invalid authentication protocol %q: must be MD5, SHA, SHA-224, SHA-256, SHA-384 or SHA-512", args[0])
+		}
+		if len(args) < 2 {
+			return fmt.Errorf("%s must be followed by a passphrase", args[0])
+		}
+		user.Level, user.AuthProtocol, user.AuthPassphrase, args = LevelAuth, protocol, args[1], args[2:]
+	}
+	if len(args) > 0 {
+		protocol, ok := privProtocols[strings.ToUpper(args[0])]
+		if !ok {
+			return fmt.Errorf("invalid privacy protocol %q: must be DES, AES, AES-192 or AES-256", args[0])
+		}
+		// The privacy passphrase defaults to the authentication one
+		user.Level, user.PrivProtocol, user.PrivPassphrase = LevelPriv, protocol, user.AuthPassphrase
+		if len(args) > 1 {
+			user.PrivPassphrase = args[1]
+		}
+		if len(args) > 2 {
+			return fmt.Errorf("unexpected %q after the privacy passphrase", args[2])
+		}
+	}
+
+	p.users = append(p.users, user)
+	return nil
+}
+
+// parseAuthUser parses authUser TYPES [-s MODEL] NAME [LEVEL [OID | -v VIEW]]
+func (p *parser) parseAuthUser(args []string) error {
+	if len(args) < 2 {
+		return errors.New("authUser must be followed by types and a user name")
+	}
+	if err := checkTypes(args[0]); err != nil {
+		return err
+	}
+	args = args[1:]
+	if args[0] == "-s" {
+		if len(args) < 3 {
+			return errors.New("authUser must be followed by types and a user name")
+		}
+		if !strings.EqualFold(args[1], "usm") {
+			return fmt.Errorf("security model %q is not supported, only usm is", args[1])
+		}
+		args = args[2:]
+	}
+	if len(args) > 2 {
+		return errors.New("authUser restricted to an OID or a view is not supported")
+	}
+
+	level := LevelAuth
+	if len(args) == 2 {
+		level = strings.ToLower(args[1])
+		if rank(level) < 0 {
+			return fmt.Errorf("invalid level %q: must be noauth, auth or priv", args[1])
+		}
+	}
+	if _, ok := p.authorizations[args[0]]; !ok {
+		p.authorized = append(p.authorized, args[0])
+	}
+	p.authorizations[args[0]] = authorization{level: level, position: p.line}
+	return nil
+}
+
+// parseDisableAuthorization parses disableAuthorization yes|no
+func (p *parser) parseDisableAuthorization(args []string) error {
+	if len(args) != 1 {
+		return errors.New("disableAuthorization must be followed by yes or no")
+	}
+	switch strings.ToLower(args[0]) {
+	case "yes", "true", "1":
+		p.config.DisableAuthorization = true
+	case "no", "false", "0":
+		p.config.DisableAuthorization = false
+	default:
+		return fmt.Errorf("invalid value %q for disableAuthorization: must be yes or no", args[0])
+	}
+	return nil
+}
+
+// parseEngineID parses engineID TEXT, which Net-SNMP turns into an engine ID of the text format
+func (p *parser) parseEngineID(args []string) error {
+	if len(args) != 1 {
+		return errors.New("engineID must be followed by a text")
+	}
+	p.config.EngineID = hex.EncodeToString([]byte(netSNMPTextEngineID + args[0]))
+	return nil
+}
+
+// authorize keeps the users which are authorized, checking that their keys allow the level
+// their authUser line requires
+func (p *parser) authorize() {
+	created := map[string]bool{}
+	for _, user := range p.users {
+		created[user.Name] = true
+		if p.config.DisableAuthorization {
+			p.config.Users = append(p.config.Users, user)
+			continue
+		}
+		authorization, ok := p.authorizations[user.Name]
+		if !ok {
+			continue
+		}
+		if rank(user.Level) < rank(authorization.level) {
+			p.fail(authorization.position, fmt.Errorf("user %q requires the level %s, which its createUser line doesn't allow", user.Name, authorization.level))
+			continue
+		}
+		p.config.Users = append(p.config.Users, user)
+	}
+
+	for _, name := range p.authorized {
+		if !created[name] {
+			p.fail(p.authorizations[name].position, fmt.Errorf("user %q has no createUser line", name))
+		}
+	}
+}
+
+// rank orders the security levels, and is negative for unknown levels
+func rank(level string) int {
+	switch level {
+	case LevelNoAuth:
+		return 0
+	case LevelAuth:
+		return 1
+	case LevelPriv:
+		return 2
+	default:
+		return -1
+	}
+}
+
+// checkTypes checks the types of notification processing of authCommunity and authUser lines
+func checkTypes(types string) error {
+	for _, t := range strings.Split(types, ",") {
+		switch strings.ToLower(t) {
+		case "log", "execute", "net":
+		default:
+			return fmt.Errorf("invalid type %q: must be log, execute or net", t)
+		}
+	}
+	return nil
+}
+
+// parseSource parses the source of an authCommunity line, an address or a network given with
+// the length of its prefix or its mask, into a CIDR block
+func parseSource(source string) (string, error) {
+	address, mask, hasMask := strings.Cut(source, "/")
+	addr, err := netip.ParseAddr(address)
+	if err != nil {
+		return "", fmt.Errorf("invalid source %q: must be an address or a network, host names are not supported", source)
+	}
+	addr = addr.Unmap()
+	if !hasMask {
+		return netip.PrefixFrom(addr, addr.BitLen()).String(), nil
+	}
+
+	if prefix, err := netip.ParsePrefix(addr.String() + "/" + mask); err == nil {
+		return prefix.Masked().String(), nil
+	}
+	// A mask such as 255.255.255.0 must be contiguous
+	maskAddr, err := netip.ParseAddr(mask)
+	if err == nil && maskAddr.Is4() && addr.Is4() {
+		bits, ones := maskAddr.As4(), 0
+		value := uint32(bits[0])<<24 | uint32(bits[1])<<16 | uint32(bits[2])<<8 | uint32(bits[3])
+		for value&(1<<31) != 0 {
+			ones, value = ones+1, value<<1
+		}
+		if value == 0 {
+			return netip.PrefixFrom(addr, ones).Masked().String(), nil
+		}
+	}
+	return "", fmt.Errorf("invalid source %q: the mask must be a prefix length or a contiguous netmask", source)
+}
+
+// splitFields splits a line into its words, which may be quoted with double quotes
+func splitFields(line string) ([]string, error) {
+	var fields []string
+	for {
+		line = strings.TrimLeft(line, " \t")
+		if line == "" {
+			return fields, nil
+		}
+		if line[0] == '"' {
+			end := strings.IndexByte(line[1:], '"')
+			if end < 0 {
+				return nil, errors.New("a quote is not closed")
+			}
+			fields = append(fields, line[1:end+1])
+			line = line[end+2:]
+			continue
+		}
+		end := strings.IndexAny(line, " \t")
+		if end < 0 {
+			end = len(line)
+		}
+		fields = append(fields, line[:end])
+		line = line[end:]
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package snmptrapd // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver/internal/snmptrapd"
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	config, err := Load(filepath.Join("testdata", "snmptrapd.conf"))
+	require.NoError(t, err)
+
+	require.Equal(t, []Community{
+		{Community: "public"},
+		{Community: "private", Sources: []string{"10.0.0.0/8", "192.0.2.10/32", "198.51.100.0/24"}},
+		{Community: "two words"},
+	}, config.Communities)
+	require.Equal(t, []User{
+		{
+			Name:           "alice",
+			EngineID:       "8000000001020304",
+			Level:          LevelPriv,
+			AuthProtocol:   "SHA256",
+			AuthPassphrase: "alice's secret",
+			PrivProtocol:   "AES256",
+			PrivPassphrase: "alice-privacy",
+		},
+		{Name: "bob", Level: LevelPriv, AuthProtocol: "MD5", AuthPassphrase: "bob-secret", PrivProtocol: "DES", PrivPassphrase: "bob-secret"},
+		{Name: "carol", Level: LevelNoAuth},
+	}, config.Users)
+	require.False(t, config.DisableAuthorization)
+	require.Equal(t, "80001f8804"+"747261702d686f73742d3031", config.EngineID)
+
+	_, err = Load(filepath.Join(t.TempDir(), "missing"))
+	require.ErrorContains(t, err, "failed to read snmptrapd configuration file")
+}
+
+func TestDisableAuthorization(t *testing.T) {
+	config, err := Parse(strings.NewReader(`
+authCommunity log public default
+authCommunity log public 10.0.0.0/8
+createUser alice SHA alice-secret
+createUser bob
+disableAuthorization yes
+`), "snmptrapd.conf")
+	require.NoError(t, err)
+	require.True(t, config.DisableAuthorization)
+	// A community accepted from any source stays so
+	require.Equal(t, []Community{{Community: "public"}}, config.Communities)
+	require.Len(t, config.Users, 2)
+}
+
+func TestParseErrors(t *testing.T) {
+	type testCase struct {
+		name        string
+		data        string
+		expectedErr string
+	}
+
+	testCases := []testCase{
+		{name: "ShortAuthCommunity", data: "authCommunity log\n", expectedErr: "snmptrapd.conf:1: authCommunity must be followed by types and a community"},
+		{name: "BadType", data: "authCommunity log,print public\n", expectedErr: `snmptrapd.conf:1: invalid type "print": must be log, execute or net`},
+		{name: "CommunityView", data: "authCommunity log public default -v traps\n", expectedErr: "snmptrapd.conf:1: authCommunity restricted to an OID or a view is not supported"},
+		{name: "HostName", data: "authCommunity log public core-sw-01\n", expectedErr: `snmptrapd.conf:1: invalid source "core-sw-01": must be an address or a network, host names are not supported`},
+		{name: "BadMask", data: "authCommunity log public 10.0.0.0/255.0.255.0\n", expectedErr: `snmptrapd.conf:1: invalid source "10.0.0.0/255.0.255.0": the mask must be a prefix length or a contiguous netmask`},
+		{name: "UnclosedQuote", data: "authCommunity log \"public\n", expectedErr: "snmptrapd.conf:1: a quote is not closed"},
+		{name: "NoUser", data: "createUser -e 0x80000000\n", expectedErr: "snmptrapd.conf:1: createUser must be followed by a user name"},
+		{name: "BadEngineID", data: "createUser -e 0xnope alice\n", expectedErr: `snmptrapd.conf:1: invalid engine ID "0xnope": must be hexadecimal`},
+		{name: "BadAuthProtocol", data: "createUser alice SHA-1 secret\n", expectedErr: `snmptrapd.conf:1: invalid authentication protocol "SHA-1"`},
+		{name: "NoPassphrase", data: "createUser alice SHA\n", expectedErr: "snmptrapd.conf:1: SHA must be followed by a passphrase"},
+		{name: "BadPrivProtocol", data: "createUser alice SHA secret 3DES\n", expectedErr: `snmptrapd.conf:1: invalid privacy protocol "3DES"`},
+		{name: "LocalizedKeys", data: "createUser -e 0x8000000001020304 alice SHA -l 0x0102 AES -l 0x0304\n", expectedErr: "snmptrapd.conf:1: createUser with localized or master keys is not supported"},
+		{name: "BadModel", data: "createUser alice\nauthUser log -s tsm alice\n", expectedErr: `snmptrapd.conf:2: security model "tsm" is not supported, only usm is`},
+		{name: "BadLevel", data: "createUser alice\nauthUser log alice authpriv\n", expectedErr: `snmptrapd.conf:2: invalid level "authpriv": must be noauth, auth or priv`},
+		{name: "LevelTooHigh", data: "authUser log alice priv\ncreateUser alice SHA secret\n", expectedErr: `snmptrapd.conf:1: user "alice" requires the level priv, which its createUser line doesn't allow`},
+		{name: "DefaultLevelTooHigh", data: "createUser alice\nauthUser log alice\n", expectedErr: `snmptrapd.conf:2: user "alice" requires the level auth, which its createUser line doesn't allow`},
+		{name: "UnknownUser", data: "authUser log alice noauth\n", expectedErr: `snmptrapd.conf:1: user "alice" has no createUser line`},
+		{name: "BadDisableAuthorization", data: "disableAuthorization maybe\n", expectedErr: `snmptrapd.conf:1: invalid value "maybe" for disableAuthorization: must be yes or no`},
+		{name: "EmptyEngineID", data: "engineID\n", expectedErr: "snmptrapd.conf:1: engineID must be followed by a text"},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			config, err := Parse(strings.NewReader(test.data), "snmptrapd.conf")
+			require.ErrorContains(t, err, test.expectedErr)
+			require.Nil(t, config)
+		})
+	}
+}
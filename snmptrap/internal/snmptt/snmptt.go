@@ -0,0 +1,589 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package snmptt loads the trap definitions of SNMPTT configuration files, snmptt.conf, so that
+// they can be used without SNMPTT. The EVENT, FORMAT, MATCH, NODES and REGEX lines are
+// supported. EXEC and PREEXEC run commands, which is left to the pipeline, so they are ignored
+// like the descriptions between SDESC and EDESC.
+package snmptt // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver/internal/snmptt"
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Event is a trap definition, from an EVENT line to the next one
+type Event struct {
+	Name string
+	// OID is the OID of the traps the event matches, dotted without a leading dot. When
+	// Wildcard is set the OID ended with .* and the event matches the traps under it.
+	OID      string
+	Wildcard bool
+	Category string
+	Severity string
+	// Format is the message of the FORMAT line, with its variables, or empty when there is none
+	Format string
+	// Position is the file and line of the EVENT line
+	Position string
+
+	matches  []match
+	matchAll bool
+	nodes    []node
+	negate   bool
+	regexes  []replacement
+}
+
+// match is a MATCH line, which holds when the value of its variable satisfies its expression
+type match struct {
+	variable string
+	negate   bool
+	pattern  *regexp.Regexp
+	// low and high bound the numeric values which match a number or a range, and low is the
+	// bound of > and < alone
+	low, high float64
+	less      bool
+	greater   bool
+}
+
+// node is an entry of a NODES line: an address, a network, a range of addresses or a host name
+type node struct {
+	prefix   netip.Prefix
+	from, to netip.Addr
+	name     string
+}
+
+// replacement is a REGEX line, which rewrites the message of the FORMAT line
+type replacement struct {
+	pattern *regexp.Regexp
+	replace string
+	global  bool
+}
+
+var errNoEvent = errors.New("must follow an EVENT line")
+
+// Load loads the events of SNMPTT configuration files, in the order of the files. Problems are
+// reported with their file and line, and the events which have none are still returned.
+func Load(files ...string) ([]*Event, error) {
+	var (
+		events []*Event
+		errs   []error
+	)
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to read SNMPTT configuration file: %w", err))
+			continue
+		}
+		parsed, err := Parse(f, file)
+		f.Close()
+		events = append(events, parsed...)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return events, errors.Join(errs...)
+}
+
+// Parse parses an SNMPTT configuration file. An event with a problem is left out, and the problem
+// is reported with the name of the file and its line.
+func Parse(r io.Reader, name string) ([]*Event, error) {
+	p := &parser{name: name}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		p.line++
+		p.parseLine(strings.TrimSpace(scanner.Text()))
+	}
+	if err := scanner.Err(); err != nil {
+		p.errs = append(p.errs, fmt.Errorf("%s: %w", name, err))
+	}
+	if p.inDescription {
+		p.fail(errors.New("SDESC is not closed by EDESC"))
+	}
+	p.end()
+	return p.events, errors.Join(p.errs...)
+}
+
+// parser holds the state of the parsing of a file
+type parser struct {
+	name   string
+	line   int
+	events []*Event
+	errs   []error
+	// event is the event being parsed, and failed tells whether it had a problem
+	event         *Event
+	failed        bool
+	inDescription bool
+}
+
+// fail reports a problem with the current line, which drops the current event
+func (p *parser) fail(err error) {
+	p.errs = append(p.errs, fmt.Errorf("%s:%d: %w", p.name, p.line, err))
+	p.failed = true
+}
+
+// end ends the current event
+func (p *parser) end() {
+	if p.event != nil && !p.failed {
+		p.events = append(p.events, p.event)
+	}
+	p.event, p.failed = nil, false
+}
+
+func (p *parser) parseLine(line string) {
+	keyword, rest := line, ""
+	if i := strings.IndexAny(line, " \t"); i >= 0 {
+		keyword, rest = line[:i], strings.TrimSpace(line[i:])
+	}
+	keyword = strings.ToUpper(keyword)
+
+	if p.inDescription {
+		p.inDescription = keyword != "EDESC"
+		return
+	}
+	if line == "" || strings.HasPrefix(line, "#") {
+		return
+	}
+
+	if keyword == "EVENT" {
+		p.end()
+		p.event = &Event{Position: fmt.Sprintf("%s:%d", p.name, p.line)}
+		if err := parseEvent(p.event, rest); err != nil {
+			p.fail(err)
+		}
+		return
+	}
+	if p.event == nil {
+		p.fail(fmt.Errorf("%s %w", keyword, errNoEvent))
+		return
+	}
+
+	var err error
+	switch keyword {
+	case "FORMAT":
+		p.event.Format = rest
+	case "MATCH":
+		err = parseMatch(p.event, rest)
+	case "NODES":
+		err = parseNodes(p.event, rest)
+	case "REGEX":
+		err = parseRegex(p.event, rest)
+	case "SDESC":
+		p.inDescription = true
+	case "EXEC", "PREEXEC", "EDESC":
+	default:
+		err = fmt.Errorf("unknown keyword %s", keyword)
+	}
+	if err != nil {
+		p.fail(err)
+	}
+}
+
+// parseEvent parses an EVENT line: EVENT name OID "category" severity
+func parseEvent(event *Event, rest string) error {
+	fields := strings.Fields(rest)
+	if len(fields) < 4 {
+		return errors.New(`EVENT must be followed by a name, an OID, a category and a severity`)
+	}
+	event.Name = fields[0]
+
+	oid := strings.TrimPrefix(fields[1], ".")
+	if prefix, ok := strings.CutSuffix(oid, ".*"); ok {
+		oid, event.Wildcard = prefix, true
+	}
+	if !isOID(oid) {
+		return fmt.Errorf("invalid OID %q: must be a dotted OID, optionally followed by .*", fields[1])
+	}
+	event.OID = oid
+
+	// The category is quoted when it has spaces, and the severity is the rest of the line
+	rest = strings.TrimSpace(rest[strings.Index(rest, fields[1])+len(fields[1]):])
+	if strings.HasPrefix(rest, `"`) {
+		end := strings.Index(rest[1:], `"`)
+		if end < 0 {
+			return errors.New("the category of EVENT is not closed by a quote")
+		}
+		event.Category = rest[1 : end+1]
+		rest = strings.TrimSpace(rest[end+2:])
+	} else {
+		event.Category, rest, _ = strings.Cut(rest, " ")
+		rest = strings.TrimSpace(rest)
+	}
+	if rest == "" {
+		return errors.New("EVENT must be followed by a name, an OID, a category and a severity")
+	}
+	event.Severity = rest
+	return nil
+}
+
+// parseMatch parses a MATCH line: MATCH MODE=and|or, or MATCH $x: [!]expression, where the
+// expression is a number, a range n-m, >n, <n, or a regular expression in parentheses
+// optionally followed by i
+func parseMatch(event *Event, rest string) error {
+	if mode, ok := cutPrefixFold(rest, "MODE="); ok {
+		switch strings.ToLower(mode) {
+		case "and":
+			event.matchAll = true
+		case "or":
+			event.matchAll = false
+		default:
+			return fmt.Errorf("invalid MATCH MODE %q: must be either and or or", mode)
+		}
+		return nil
+	}
+
+	variable, expression, ok := strings.Cut(rest, ":")
+	variable = strings.TrimSpace(variable)
+	if !ok || !strings.HasPrefix(variable, "$") || len(variable) < 2 {
+		return fmt.Errorf("invalid MATCH %q: must be $x: expression", rest)
+	}
+	m := match{variable: variable[1:]}
+	expression = strings.TrimSpace(expression)
+	if negated, ok := strings.CutPrefix(expression, "!"); ok {
+		m.negate, expression = true, strings.TrimSpace(negated)
+	}
+
+	switch {
+	case strings.HasPrefix(expression, "("):
+		end := strings.LastIndex(expression, ")")
+		if end < 0 {
+			return fmt.Errorf("invalid MATCH %q: the regular expression is not closed by a parenthesis", rest)
+		}
+		source := expression[1:end]
+		switch flags := expression[end+1:]; flags {
+		case "":
+		case "i":
+			source = "(?i)" + source
+		default:
+			return fmt.Errorf("invalid MATCH %q: unknown flags %q", rest, flags)
+		}
+		pattern, err := regexp.Compile(source)
+		if err != nil {
+			return fmt.Errorf("invalid MATCH %q: %w", rest, err)
+		}
+		m.pattern = pattern
+	case strings.HasPrefix(expression, ">") || strings.HasPrefix(expression, "<"):
+		number, err := strconv.ParseFloat(strings.TrimSpace(expression[1:]), 64)
+		if err != nil {
+			return fmt.Errorf("invalid MATCH %q: %s must be followed by a number", rest, expression[:1])
+		}
+		m.low, m.high, m.greater, m.less = number, number, expression[0] == '>', expression[0] == '<'
+	default:
+		low, high, ok := parseRange(expression)
+		if !ok {
+			return fmt.Errorf("invalid MATCH %q: must be a number, a range, >n, <n or a regular expression in parentheses", rest)
+		}
+		m.low, m.high = low, high
+	}
+
+	event.matches = append(event.matches, m)
+	return nil
+}
+
+// parseRange parses a number, or a range of numbers n-m
+func parseRange(expression string) (float64, float64, bool) {
+	if number, err := strconv.ParseFloat(expression, 64); err == nil {
+		return number, number, true
+	}
+	// The separator is the first dash which doesn't start a negative number
+	for i := 1; i < len(expression); i++ {
+		if expression[i] != '-' {
+			continue
+		}
+		low, errLow := strconv.ParseFloat(strings.TrimSpace(expression[:i]), 64)
+		high, errHigh := strconv.ParseFloat(strings.TrimSpace(expression[i+1:]), 64)
+		if errLow == nil && errHigh == nil {
+			return low, high, true
+		}
+	}
+	return 0, 0, false
+}
+
+// parseNodes parses a NODES line: NODES MODE=POS|NEG, or NODES followed by addresses, networks,
+// ranges of addresses, host names and files listing more of them
+func parseNodes(event *Event, rest string) error {
+	if mode, ok := cutPrefixFold(rest, "MODE="); ok {
+		switch strings.ToUpper(mode) {
+		case "POS":
+			event.negate = false
+		case "NEG":
+			event.negate = true
+		default:
+			return fmt.Errorf("invalid NODES MODE %q: must be either POS or NEG", mode)
+		}
+		return nil
+	}
+
+	for _, entry := range strings.Fields(rest) {
+		if !strings.HasPrefix(entry, "/") {
+			event.nodes = append(event.nodes, parseNode(entry))
+			continue
+		}
+		data, err := os.ReadFile(entry)
+		if err != nil {
+			return fmt.Errorf("failed to read NODES file: %w", err)
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			line, _, _ = strings.Cut(line, "#")
+			for _, entry := range strings.Fields(line) {
+				event.nodes = append(event.nodes, parseNode(entry))
+			}
+		}
+	}
+	return nil
+}
+
+// parseNode parses an entry of NODES, which is a host name when it isn't an address
+func parseNode(entry string) node {
+	if prefix, err := netip.ParsePrefix(entry); err == nil {
+		return node{prefix: prefix.Masked()}
+	}
+	if addr, err := netip.ParseAddr(entry); err == nil {
+		addr = addr.Unmap()
+		return node{prefix: netip.PrefixFrom(addr, addr.BitLen())}
+	}
+	if from, to, ok := strings.Cut(entry, "-"); ok {
+		fromAddr, errFrom := netip.ParseAddr(from)
+		toAddr, errTo := netip.ParseAddr(to)
+		if errFrom == nil && errTo == nil {
+			return node{from: fromAddr.Unmap(), to: toAddr.Unmap()}
+		}
+	}
+	return node{name: strings.ToLower(entry)}
+}
+
+// parseRegex parses a REGEX line: REGEX (search)(replace) optionally followed by the flags g and i
+func parseRegex(event *Event, rest string) error {
+	search, after, ok := cutParenthesized(rest)
+	if !ok {
+		return fmt.Errorf("invalid REGEX %q: must be (search)(replace)", rest)
+	}
+	replace, flags, ok := cutParenthesized(strings.TrimSpace(after))
+	if !ok {
+		return fmt.Errorf("invalid REGEX %q: must be (search)(replace)", rest)
+	}
+
+	r := replacement{replace: backReferences.ReplaceAllString(replace, "$${$1}")}
+	for _, flag := range strings.TrimSpace(flags) {
+		switch flag {
+		case 'g':
+			r.global = true
+		case 'i':
+			search = "(?i)" + search
+		default:
+			return fmt.Errorf("invalid REGEX %q: unknown flag %q", rest, flag)
+		}
+	}
+	pattern, err := regexp.Compile(search)
+	if err != nil {
+		return fmt.Errorf("invalid REGEX %q: %w", rest, err)
+	}
+	r.pattern = pattern
+
+	event.regexes = append(event.regexes, r)
+	return nil
+}
+
+// backReferences are the \1 and $1 references of Perl replacements
+var backReferences = regexp.MustCompile(`[\\$](\d+)`)
+
+// cutParenthesized returns the text between the parenthesis s starts with and the one closing
+// it, and the text which follows
+func cutParenthesized(s string) (string, string, bool) {
+	if !strings.HasPrefix(s, "(") {
+		return "", "", false
+	}
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return s[1:i], s[i+1:], true
+			}
+		}
+	}
+	return "", "", false
+}
+
+// cutPrefixFold is strings.CutPrefix ignoring case
+func cutPrefixFold(s, prefix string) (string, bool) {
+	if len(s) < len(prefix) || !strings.EqualFold(s[:len(prefix)], prefix) {
+		return s, false
+	}
+	return strings.TrimSpace(s[len(prefix):]), true
+}
+
+// isOID tells whether s is a dotted OID without a leading dot
+func isOID(s string) bool {
+	for _, arc := range strings.Split(s, ".") {
+		if _, err := strconv.ParseUint(arc, 10, 32); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// Lookup returns the value of a variable of FORMAT and MATCH lines, given without its $, such
+// as 1 or A. It returns false for the variables it doesn't know.
+type Lookup func(variable string) (string, bool)
+
+// Matches tells whether the OID of a trap, dotted without a leading dot, is that of the event
+func (event *Event) Matches(oid string) bool {
+	if event.Wildcard {
+		return strings.HasPrefix(oid, event.OID+".")
+	}
+	return oid == event.OID
+}
+
+// Accepts tells whether the event applies to a trap from an agent, with its NODES and MATCH
+// lines. The agent is given by its address and the name it is known by, if any.
+func (event *Event) Accepts(agent netip.Addr, name string, lookup Lookup) bool {
+	if len(event.nodes) > 0 && event.inNodes(agent, name) == event.negate {
+		return false
+	}
+	if len(event.matches) == 0 {
+		return true
+	}
+	for _, m := range event.matches {
+		value, _ := lookup(m.variable)
+		if m.holds(value) != event.matchAll {
+			return !event.matchAll
+		}
+	}
+	return event.matchAll
+}
+
+// HasHostNames tells whether NODES lines of the event name hosts, which only match agents
+// given with the name they are known by
+func (event *Event) HasHostNames() bool {
+	for _, n := range event.nodes {
+		if n.name != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// inNodes tells whether an agent is one of the nodes of the event. Host names match the name
+// of the agent with or without its domain.
+func (event *Event) inNodes(agent netip.Addr, name string) bool {
+	agent = agent.Unmap()
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	host, _, _ := strings.Cut(name, ".")
+	for _, n := range event.nodes {
+		switch {
+		case n.name != "":
+			if n.name == name || n.name == host || n.name == agent.String() {
+				return true
+			}
+		case n.prefix.IsValid():
+			if n.prefix.Contains(agent) {
+				return true
+			}
+		default:
+			if agent.Compare(n.from) >= 0 && agent.Compare(n.to) <= 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// holds tells whether a value satisfies the expression of a MATCH line
+func (m match) holds(value string) bool {
+	var holds bool
+	if m.pattern != nil {
+		holds = m.pattern.MatchString(value)
+	} else if number, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+		switch {
+		case m.greater:
+			holds = number > m.low
+		case m.less:
+			holds = number < m.low
+		default:
+			holds = number >= m.low && number <= m.high
+		}
+	}
+	return holds != m.negate
+}
+
+// Message returns the FORMAT of the event with its variables substituted, rewritten by its
+// REGEX lines
+func (event *Event) Message(lookup Lookup) string {
+	message := Expand(event.Format, lookup)
+	for _, r := range event.regexes {
+		if r.global {
+			message = r.pattern.ReplaceAllString(message, r.replace)
+			continue
+		}
+		if loc := r.pattern.FindStringSubmatchIndex(message); loc != nil {
+			expanded := r.pattern.ExpandString(nil, r.replace, message, loc)
+			message = message[:loc[0]] + string(expanded) + message[loc[1]:]
+		}
+	}
+	return message
+}
+
+// Expand substitutes the variables of a FORMAT line, such as $1, $+1, $N or $aA. $$ stands for
+// a dollar sign, and variables which the lookup doesn't know are kept as they are.
+func Expand(format string, lookup Lookup) string {
+	var expanded strings.Builder
+	for i := 0; i < len(format); i++ {
+		if format[i] != '$' || i+1 == len(format) {
+			expanded.WriteByte(format[i])
+			continue
+		}
+		variable := variableAt(format[i+1:])
+		if variable == "$" {
+			expanded.WriteByte('$')
+			i++
+			continue
+		}
+		if value, ok := lookup(variable); ok && variable != "" {
+			expanded.WriteString(value)
+			i += len(variable)
+			continue
+		}
+		expanded.WriteByte('$')
+	}
+	return expanded.String()
+}
+
+// variableAt returns the name of the variable at the start of s, which follows a $
+func variableAt(s string) string {
+	end := 0
+	switch {
+	case s[0] == '+' || s[0] == '-':
+		end = 1
+		if len(s) > 1 && s[1] == '*' {
+			return s[:2]
+		}
+		for end < len(s) && s[end] >= '0' && s[end] <= '9' {
+			end++
+		}
+		if end == 1 {
+			return ""
+		}
+		return s[:end]
+	case s[0] >= '0' && s[0] <= '9':
+		for end < len(s) && s[end] >= '0' && s[end] <= '9' {
+			end++
+		}
+		return s[:end]
+	case s[0] == 'a' && len(s) > 1 && (s[1] == 'A' || s[1] == 'R'):
+		return s[:2]
+	default:
+		return s[:1]
+	}
+}
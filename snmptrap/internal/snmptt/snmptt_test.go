@@ -0,0 +1,205 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package snmptt // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver/internal/snmptt"
+
+import (
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// lookup looks variables up in a map
+func lookup(variables map[string]string) Lookup {
+	return func(variable string) (string, bool) {
+		value, ok := variables[variable]
+		return value, ok
+	}
+}
+
+func TestLoad(t *testing.T) {
+	file := filepath.Join("testdata", "snmptt.conf")
+	events, err := Load(file)
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+
+	linkDown := events[0]
+	require.Equal(t, "linkDown", linkDown.Name)
+	require.Equal(t, "1.3.6.1.6.3.1.1.5.3", linkDown.OID)
+	require.False(t, linkDown.Wildcard)
+	require.Equal(t, "Status Events", linkDown.Category)
+	require.Equal(t, "Minor", linkDown.Severity)
+	require.Equal(t, "Link down on interface $1. Admin state: $2. Operational state: $3", linkDown.Format)
+	require.Equal(t, file+":4", linkDown.Position)
+
+	linkUp := events[1]
+	require.Equal(t, "Status", linkUp.Category)
+	require.Equal(t, "Normal", linkUp.Severity)
+
+	example := events[2]
+	require.True(t, example.Wildcard)
+	require.Equal(t, "1.3.6.1.4.1.32473.1", example.OID)
+	require.True(t, example.Matches("1.3.6.1.4.1.32473.1.1.0.1"))
+	require.False(t, example.Matches("1.3.6.1.4.1.32473.1"))
+	require.False(t, example.Matches("1.3.6.1.4.1.32473.10.1"))
+	require.True(t, linkDown.Matches("1.3.6.1.6.3.1.1.5.3"))
+	require.False(t, linkDown.Matches("1.3.6.1.6.3.1.1.5.3.1"))
+
+	_, err = Load(filepath.Join(t.TempDir(), "missing"))
+	require.ErrorContains(t, err, "failed to read SNMPTT configuration file")
+}
+
+func TestParseErrors(t *testing.T) {
+	type testCase struct {
+		name        string
+		data        string
+		expectedErr string
+	}
+
+	testCases := []testCase{
+		{name: "NoEvent", data: "FORMAT hello\n", expectedErr: "snmptt.conf:1: FORMAT must follow an EVENT line"},
+		{name: "ShortEvent", data: "EVENT linkDown .1.3.6.1.6.3.1.1.5.3 Status\n", expectedErr: "snmptt.conf:1: EVENT must be followed by a name, an OID, a category and a severity"},
+		{name: "NamedOID", data: "EVENT linkDown IF-MIB::linkDown Status Normal\n", expectedErr: `snmptt.conf:1: invalid OID "IF-MIB::linkDown": must be a dotted OID, optionally followed by .*`},
+		{name: "UnclosedCategory", data: "EVENT linkDown .1.3.6.1.6.3.1.1.5.3 \"Status Normal\n", expectedErr: "snmptt.conf:1: the category of EVENT is not closed by a quote"},
+		{name: "QuotedCategoryWithoutSeverity", data: "EVENT linkDown .1.3.6.1.6.3.1.1.5.3 \"Status Events\" \n", expectedErr: "snmptt.conf:1: EVENT must be followed by a name, an OID, a category and a severity"},
+		{name: "UnknownKeyword", data: "EVENT a .1.3 Status Normal\nFORMATT hello\n", expectedErr: "snmptt.conf:2: unknown keyword FORMATT"},
+		{name: "BadMatchMode", data: "EVENT a .1.3 Status Normal\nMATCH MODE=xor\n", expectedErr: `snmptt.conf:2: invalid MATCH MODE "xor": must be either and or or`},
+		{name: "BadMatch", data: "EVENT a .1.3 Status Normal\nMATCH 1: 2\n", expectedErr: `snmptt.conf:2: invalid MATCH "1: 2": must be $x: expression`},
+		{name: "BadMatchExpression", data: "EVENT a .1.3 Status Normal\nMATCH $1: up\n", expectedErr: `snmptt.conf:2: invalid MATCH "$1: up": must be a number, a range, >n, <n or a regular expression in parentheses`},
+		{name: "BadMatchRegex", data: "EVENT a .1.3 Status Normal\nMATCH $1: ([a-)\n", expectedErr: "snmptt.conf:2: invalid MATCH \"$1: ([a-)\": error parsing regexp: missing closing ]: `[a-`"},
+		{name: "BadMatchComparison", data: "EVENT a .1.3 Status Normal\nMATCH $1: >x\n", expectedErr: `snmptt.conf:2: invalid MATCH "$1: >x": > must be followed by a number`},
+		{name: "BadNodesMode", data: "EVENT a .1.3 Status Normal\nNODES MODE=ALL\n", expectedErr: `snmptt.conf:2: invalid NODES MODE "ALL": must be either POS or NEG`},
+		{name: "MissingNodesFile", data: "EVENT a .1.3 Status Normal\nNODES /nonexistent/nodes\n", expectedErr: "snmptt.conf:2: failed to read NODES file"},
+		{name: "BadRegex", data: "EVENT a .1.3 Status Normal\nREGEX (a)\n", expectedErr: `snmptt.conf:2: invalid REGEX "(a)": must be (search)(replace)`},
+		{name: "BadRegexFlag", data: "EVENT a .1.3 Status Normal\nREGEX (a)(b)x\n", expectedErr: `snmptt.conf:2: invalid REGEX "(a)(b)x": unknown flag 'x'`},
+		{name: "UnclosedDescription", data: "EVENT a .1.3 Status Normal\nSDESC\nText\n", expectedErr: "snmptt.conf:3: SDESC is not closed by EDESC"},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			events, err := Parse(strings.NewReader(test.data), "snmptt.conf")
+			require.ErrorContains(t, err, test.expectedErr)
+			require.Empty(t, events)
+		})
+	}
+
+	// Only the events with problems are left out
+	events, err := Parse(strings.NewReader("EVENT a .1.3 Status Normal\nMATCH $1: up\nEVENT b .1.4 Status Normal\n"), "snmptt.conf")
+	require.Error(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, "b", events[0].Name)
+}
+
+func TestAccepts(t *testing.T) {
+	nodes := filepath.Join(t.TempDir(), "nodes")
+	require.NoError(t, os.WriteFile(nodes, []byte("# Edge routers\n198.51.100.1 198.51.100.2\nedge-rtr-03\n"), 0o600))
+
+	events, err := Parse(strings.NewReader(`
+EVENT or .1.3.1 Status Normal
+MATCH $1: 2
+MATCH $2: 5-10
+EVENT and .1.3.2 Status Normal
+MATCH MODE=and
+MATCH $1: !(^eth)i
+MATCH $2: >100
+MATCH $3: <0
+EVENT nodes .1.3.3 Status Normal
+NODES 10.0.0.0/8 192.0.2.10-192.0.2.20 core-sw-01
+NODES `+nodes+`
+EVENT negated .1.3.4 Status Normal
+NODES MODE=NEG
+NODES 10.0.0.0/8
+`), "snmptt.conf")
+	require.NoError(t, err)
+	or, and, inNodes, negated := events[0], events[1], events[2], events[3]
+	agent := netip.MustParseAddr("192.0.2.1")
+
+	require.True(t, or.Accepts(agent, "", lookup(map[string]string{"1": "2", "2": "20"})))
+	require.True(t, or.Accepts(agent, "", lookup(map[string]string{"1": "1", "2": "7"})))
+	require.False(t, or.Accepts(agent, "", lookup(map[string]string{"1": "1", "2": "20"})))
+	require.False(t, or.Accepts(agent, "", lookup(map[string]string{})))
+
+	require.True(t, and.Accepts(agent, "", lookup(map[string]string{"1": "Gi0/1", "2": "101", "3": "-1"})))
+	require.False(t, and.Accepts(agent, "", lookup(map[string]string{"1": "ETH0", "2": "101", "3": "-1"})))
+	require.False(t, and.Accepts(agent, "", lookup(map[string]string{"1": "Gi0/1", "2": "100", "3": "-1"})))
+
+	for _, test := range []struct {
+		agent    string
+		name     string
+		expected bool
+	}{
+		{agent: "10.1.2.3", expected: true},
+		{agent: "::ffff:10.1.2.3", expected: true},
+		{agent: "192.0.2.15", expected: true},
+		{agent: "192.0.2.21", expected: false},
+		{agent: "192.0.2.1", name: "CORE-SW-01", expected: true},
+		{agent: "198.51.100.2", expected: true},
+		{agent: "192.0.2.1", name: "edge-rtr-03", expected: true},
+		{agent: "192.0.2.1", name: "edge-rtr-03.example.net.", expected: true},
+		{agent: "192.0.2.1", name: "edge-rtr-04.example.net", expected: false},
+		{agent: "192.0.2.1", expected: false},
+	} {
+		require.Equal(t, test.expected, inNodes.Accepts(netip.MustParseAddr(test.agent), test.name, lookup(nil)), test.agent)
+	}
+
+	require.True(t, inNodes.HasHostNames())
+	require.False(t, negated.HasHostNames())
+	require.False(t, negated.Accepts(netip.MustParseAddr("10.1.2.3"), "", lookup(nil)))
+	require.True(t, negated.Accepts(agent, "", lookup(nil)))
+}
+
+func TestMessage(t *testing.T) {
+	events, err := Load(filepath.Join("testdata", "snmptt.conf"))
+	require.NoError(t, err)
+	linkDown, linkUp, example := events[0], events[1], events[2]
+
+	variables := lookup(map[string]string{
+		"1":  "Gi0/1",
+		"2":  "up",
+		"3":  "down",
+		"A":  "core-sw-01",
+		"N":  "exampleTraps",
+		"aR": "192.0.2.1",
+		"*":  "fan 1 failed, fan 2 failed",
+	})
+	require.Equal(t, "Link down on interface Gi0/1. Admin state: up. Operational state: down", linkDown.Message(variables))
+	require.Equal(t, "Link up on interface Gi0/1 of core-sw-01", linkUp.Message(variables))
+	require.Equal(t, "Example trap exampleTraps from 192.0.2.1: FAN-1 FAILED, FAN-2 failed", example.Message(variables))
+}
+
+func TestExpand(t *testing.T) {
+	variables := lookup(map[string]string{
+		"1":  "one",
+		"12": "twelve",
+		"+1": "ifDescr:one",
+		"+*": "ifDescr:one ifType:6",
+		"*":  "one 6",
+		"A":  "agent",
+		"aA": "192.0.2.1",
+	})
+
+	type testCase struct {
+		format   string
+		expected string
+	}
+
+	testCases := []testCase{
+		{format: "$1 and $12", expected: "one and twelve"},
+		{format: "$1$A", expected: "oneagent"},
+		{format: "$+1 $+*", expected: "ifDescr:one ifDescr:one ifType:6"},
+		{format: "all: $*", expected: "all: one 6"},
+		{format: "$aA ($A)", expected: "192.0.2.1 (agent)"},
+		{format: "costs $$5", expected: "costs $5"},
+		{format: "unknown $2 $Z $+x $", expected: "unknown $2 $Z $+x $"},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.format, func(t *testing.T) {
+			require.Equal(t, test.expected, Expand(test.format, variables))
+		})
+	}
+}
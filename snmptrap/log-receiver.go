@@ -1,54 +1,423 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
 
 package snmptrapreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver"
 
 import (
 	"context"
-//	"time"
-        "net"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	gosnmp "github.com/gosnmp/gosnmp"
 	"go.opentelemetry.io/collector/component"
-//	"go.opentelemetry.io/collector/consumer"
-//	"go.uber.org/zap"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/receiver"
+	"go.opentelemetry.io/collector/receiver/receiverhelper"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 
-	gosnmp "github.com/gosnmp/gosnmp"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver/internal/mib"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver/internal/pen"
+)
+
+const (
+	transportUDP = "udp"
+
+	defaultCloseTimeout = 3 * time.Second
+
+	// templateWarningInterval is how often a failure to render message templates is logged,
+	// as a template which fails usually does so for every notification it applies to
+	templateWarningInterval = time.Minute
 )
 
-type snmptrapReceiver struct{
-	host component.Host
-	cancel context.CancelFunc
-	config *Config
-	listener  *gosnmp.TrapListener
+type snmptrapReceiver struct {
+	host          component.Host
+	cancel        context.CancelFunc
+	config        *Config
+	settings      receiver.CreateSettings
+	logger        *zap.Logger
+	sampledLogger *zap.Logger
+	nextConsumer  consumer.Logs
+	obsrecvs      map[string]*receiverhelper.ObsReport
+	telemetry     *receiverTelemetry
+	engine        *snmpEngine
+	informs       *informDeduplicator
+	acl           *sourceACL
+	communities   *communityPolicy
+	severities    severityRules
+	templates     messageTemplates
+	snmptt        *snmpttEvents
+	mibLoader     *mibLoader
+	mibs          atomic.Pointer[mib.MIB]
+	enterprises   *pen.Registry
+	listeners     []trapListener
+	wg            sync.WaitGroup
+}
+
+// newSnmptrapReceiver creates the SNMP trap receiver with the given parameters
+func newSnmptrapReceiver(settings receiver.CreateSettings, config *Config, nextConsumer consumer.Logs) (*snmptrapReceiver, error) {
+	// The communities, users and engine ID of snmptrapd_conf are used as if they were configured
+	config, err := config.withSnmptrapdConf()
+	if err != nil {
+		return nil, fmt.Errorf("snmptrapd_conf: %w", err)
+	}
+
+	// Operations are reported per transport, so there is one ObsReport for each transport in use
+	obsrecvs := map[string]*receiverhelper.ObsReport{}
+	for _, listenerCfg := range config.listenerConfigs() {
+		transport := transportUDP
+		if strings.HasPrefix(strings.ToLower(listenerCfg.ListenAddress), transportTCP) {
+			transport = transportTCP
+		}
+		if obsrecvs[transport] != nil {
+			continue
+		}
+
+		obsrecv, err := receiverhelper.NewObsReport(receiverhelper.ObsReportSettings{
+			ReceiverID:             settings.ID,
+			Transport:              transport,
+			ReceiverCreateSettings: settings,
+		})
+		if err != nil {
+			return nil, err
+		}
+		obsrecvs[transport] = obsrecv
+	}
+
+	telemetry, err := newReceiverTelemetry(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	engine, err := newSNMPEngine(config.EngineID)
+	if err != nil {
+		return nil, err
+	}
+
+	acl, err := newSourceACL(config)
+	if err != nil {
+		return nil, err
+	}
+
+	communities, err := newCommunityPolicy(config)
+	if err != nil {
+		return nil, err
+	}
+
+	severities, err := newSeverityRules(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &snmptrapReceiver{
+		config:        config,
+		settings:      settings,
+		logger:        settings.Logger,
+		sampledLogger: newSampledLogger(settings.Logger, templateWarningInterval),
+		nextConsumer:  nextConsumer,
+		obsrecvs:      obsrecvs,
+		telemetry:     telemetry,
+		engine:        engine,
+		informs:       newInformDeduplicator(config.InformDeduplicationWindow),
+		acl:           acl,
+		communities:   communities,
+		severities:    severities,
+		mibLoader:     newMIBLoader(config, settings.Logger),
+	}, nil
 }
 
+// newSampledLogger returns a logger which logs each message at most once per interval, for
+// problems which would otherwise be logged for every notification
+func newSampledLogger(logger *zap.Logger, interval time.Duration) *zap.Logger {
+	return logger.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return zapcore.NewSamplerWithOptions(core, interval, 1, 0)
+	}))
+}
 
-// Start will create a SNMP trap listener with a callback handler
-func (snmptrapRcvr *snmptrapReceiver) Start(ctx context.Context, host component.Host) error {
+// Start binds every listen address and starts handling the traps received on them
+func (snmptrapRcvr *snmptrapReceiver) Start(_ context.Context, host component.Host) error {
 	snmptrapRcvr.host = host
-	ctx = context.Background()
-	ctx, snmptrapRcvr.cancel = context.WithCancel(ctx)
+	var ctx context.Context
+	ctx, snmptrapRcvr.cancel = context.WithCancel(context.Background())
 
-	// A TrapListener defines parameters for running a SNMP Trap receiver
-	// nil values will be replaced by default values.
-	snmptrapRcvr.listener = gosnmp.NewTrapListener()
+	snmptrapRcvr.mibs.Store(snmptrapRcvr.mibLoader.load())
+	snmptrapRcvr.enterprises = loadEnterpriseNumbers(snmptrapRcvr.config, snmptrapRcvr.logger)
 
-	// When the listener receives a trap, invoke this callback handler
-	snmptrapRcvr.listener.OnNewTrap = trapCallback
+	templates, err := newMessageTemplates(snmptrapRcvr.config)
+	if err != nil {
+		return err
+	}
+	snmptrapRcvr.templates = templates
 
-	snmptrapRcvr.listener.Params = gosnmp.Default
-	snmptrapRcvr.listener.Params.Community = snmptrapRcvr.config.Community
+	events, err := newSNMPTTEvents(snmptrapRcvr.config)
+	if err != nil {
+		return err
+	}
+	snmptrapRcvr.snmptt = events
+
+	// The connection limits are shared by all of the tcp listeners
+	limits := tcpLimits{
+		idleTimeout: snmptrapRcvr.config.TCPIdleTimeout,
+		conns:       newConnLimiter(snmptrapRcvr.config.TCPMaxConnections, snmptrapRcvr.config.TCPMaxConnectionsPerPeer),
+	}
+	for _, listenerCfg := range snmptrapRcvr.config.listenerConfigs() {
+		// Each socket decodes packets with its own version and credentials
+		unmarshaller := newUnmarshaller(listenerCfg)
+		var users *usmUsers
+		if unmarshaller.Version == gosnmp.Version3 {
+			var err error
+			if users, err = newUSMUsers(listenerCfg); err != nil {
+				_ = snmptrapRcvr.Shutdown(ctx)
+				return err
+			}
+		}
+
+		listener, err := newTrapListener(listenerCfg.ListenAddress, func(message []byte, peer net.Addr, local net.Addr, reply func([]byte) error) {
+			snmptrapRcvr.handleMessage(ctx, unmarshaller, users, message, peer, local, reply)
+		}, limits, snmptrapRcvr.logger)
+		if err != nil {
+			// Release the sockets that were already bound
+			_ = snmptrapRcvr.Shutdown(ctx)
+			return fmt.Errorf("failed to listen on %s: %w", listenerCfg.ListenAddress, err)
+		}
+		snmptrapRcvr.listeners = append(snmptrapRcvr.listeners, listener)
+	}
+
+	for _, listener := range snmptrapRcvr.listeners {
+		snmptrapRcvr.wg.Add(1)
+		go func(listener trapListener) {
+			defer snmptrapRcvr.wg.Done()
+			listener.serve()
+		}(listener)
+	}
+
+	if interval := snmptrapRcvr.config.MIBReloadInterval; interval > 0 && snmptrapRcvr.mibLoader.hasFiles() {
+		snmptrapRcvr.wg.Add(1)
+		go func() {
+			defer snmptrapRcvr.wg.Done()
+			snmptrapRcvr.watchMIBs(ctx, interval)
+		}()
+	}
 
 	return nil
 }
 
-// Shutdown will stop our listner
-func (snmptrapRcvr *snmptrapReceiver) Shutdown(ctx context.Context) error {
-	snmptrapRcvr.listener.Close()
-	snmptrapRcvr.cancel()
-	return nil
+// Shutdown will stop our listeners and wait for them to finish handling any traps
+func (snmptrapRcvr *snmptrapReceiver) Shutdown(_ context.Context) error {
+	if snmptrapRcvr.cancel != nil {
+		snmptrapRcvr.cancel()
+	}
+	if len(snmptrapRcvr.listeners) == 0 {
+		return nil
+	}
+
+	var err error
+	for _, listener := range snmptrapRcvr.listeners {
+		err = errors.Join(err, listener.close())
+	}
+	snmptrapRcvr.listeners = nil
+
+	closeTimeout := snmptrapRcvr.config.CloseTimeout
+	if closeTimeout <= 0 {
+		closeTimeout = defaultCloseTimeout
+	}
+	done := make(chan struct{})
+	go func() {
+		snmptrapRcvr.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(closeTimeout):
+		snmptrapRcvr.logger.Warn("Timed out waiting for the trap listeners to close")
+	}
+
+	return err
+}
+
+// newUnmarshaller creates the gosnmp instance holding the version and credentials used to decode packets.
+// SNMPv3 messages are decoded with the credentials of the listener's usmUsers instead.
+func newUnmarshaller(cfg *Config) *gosnmp.GoSNMP {
+	unmarshaller := &otelGoSNMPWrapper{
+		gosnmp.GoSNMP{
+			Timeout: gosnmp.Default.Timeout,
+			MaxOids: gosnmp.Default.MaxOids,
+		},
+	}
+	unmarshaller.SetVersion(getSNMPVersion(cfg.Version))
+	if unmarshaller.GetVersion() == gosnmp.Version3 {
+		setV3ClientConfigs(unmarshaller, cfg)
+	} else {
+		unmarshaller.SetCommunity(cfg.Community)
+	}
+	return &unmarshaller.GoSNMP
+}
+
+// handleMessage decodes a message received by a listener and hands it on to trapCallback.
+// Informs are acknowledged once they have been consumed, and retransmissions of an inform
+// received within the deduplication window are acknowledged without being logged again.
+func (snmptrapRcvr *snmptrapReceiver) handleMessage(ctx context.Context, unmarshaller *gosnmp.GoSNMP, users *usmUsers, message []byte, peer net.Addr, local net.Addr, reply func([]byte) error) {
+	// Denied sources are dropped before spending anything on decoding their messages
+	if ip, _ := splitAddr(peer); !snmptrapRcvr.acl.permits(ip) {
+		snmptrapRcvr.telemetry.recordDenied(ctx)
+		return
+	}
+
+	packet, err := snmptrapRcvr.engine.unmarshal(unmarshaller, users, message, reply)
+	if err != nil {
+		snmptrapRcvr.logger.Debug("Dropping SNMP message", zap.Stringer("source", peer), zap.Stringer("local", local), zap.Error(err))
+		return
+	}
+
+	switch packet.PDUType {
+	case gosnmp.Trap, gosnmp.SNMPv2Trap, gosnmp.InformRequest:
+	default:
+		snmptrapRcvr.logger.Debug("Dropping SNMP message that is not a notification", zap.Stringer("source", peer), zap.Stringer("pdu_type", packet.PDUType))
+		return
+	}
+
+	if !snmptrapRcvr.acl.permitsAgentAddress(packet) {
+		snmptrapRcvr.logger.Debug("Dropping v1 trap with a denied agent address", zap.Stringer("source", peer), zap.String("agent_address", packet.AgentAddress))
+		snmptrapRcvr.telemetry.recordRejected(ctx, reasonAgentAddress)
+		return
+	}
+
+	authorized, accepted := snmptrapRcvr.authorizeCommunity(ctx, packet, peer)
+	if !accepted {
+		return
+	}
+
+	if packet.PDUType != gosnmp.InformRequest {
+		_ = snmptrapRcvr.trapCallback(ctx, packet, peer, local, authorized)
+		return
+	}
+
+	ip, _ := splitAddr(peer)
+	key := newInformKey(ip.String(), packet)
+	if duplicate, acknowledged := snmptrapRcvr.informs.begin(key, time.Now()); duplicate {
+		// The original is still being handled when it isn't acknowledged yet, and the
+		// sender will retransmit again if it doesn't get through
+		snmptrapRcvr.logger.Debug("Dropping retransmitted inform", zap.Stringer("source", peer), zap.Uint32("request_id", packet.RequestID))
+		if acknowledged {
+			snmptrapRcvr.acknowledgeInform(packet, peer, reply)
+		}
+		return
+	}
+
+	// An inform that couldn't be consumed is not acknowledged, so that the sender retransmits it
+	if err := snmptrapRcvr.trapCallback(ctx, packet, peer, local, authorized); err != nil {
+		snmptrapRcvr.informs.forget(key)
+		return
+	}
+	snmptrapRcvr.informs.acknowledge(key)
+	snmptrapRcvr.acknowledgeInform(packet, peer, reply)
+}
+
+// authorizeCommunity applies the community policy to a v1 or v2c notification. It returns
+// whether the community is allowed and whether the notification should be passed on, which
+// it is when the community isn't allowed but is only to be tagged.
+func (snmptrapRcvr *snmptrapReceiver) authorizeCommunity(ctx context.Context, packet *gosnmp.SnmpPacket, peer net.Addr) (authorized bool, accepted bool) {
+	if packet.Version == gosnmp.Version3 {
+		return true, true
+	}
+	ip, _ := splitAddr(peer)
+	if snmptrapRcvr.communities.authorized(packet.Community, ip) {
+		return true, true
+	}
+
+	switch snmptrapRcvr.communities.mode {
+	case communityModeTag:
+		return false, true
+	case communityModeReject:
+		snmptrapRcvr.logger.Warn("Rejecting SNMP notification with a community that is not allowed", zap.Stringer("source", peer))
+	}
+	snmptrapRcvr.telemetry.recordRejected(ctx, reasonCommunity)
+	return false, false
 }
 
-// trapCallback is the callback for handling traps received by the listener.
-//
-func trapCallback(rawtrap *gosnmp.SnmpPacket, addr *net.UDPAddr) {
+// acknowledgeInform sends the response to an inform
+func (snmptrapRcvr *snmptrapReceiver) acknowledgeInform(packet *gosnmp.SnmpPacket, peer net.Addr, reply func([]byte) error) {
+	response, err := snmptrapRcvr.engine.informResponse(packet)
+	if err == nil {
+		err = reply(response)
+	}
+	if err != nil {
+		snmptrapRcvr.logger.Warn("Failed to acknowledge inform", zap.Stringer("source", peer), zap.Error(err))
+	}
 }
 
+// trapCallback is the callback for handling traps received by the listeners.
+// Each trap is converted to a log record and passed on to the next consumer.
+// Traps with a community that isn't allowed are tagged when they get here.
+// The agent which originated the trap, rather than a relay, is resolved from its addresses
+// as received, in the order of agent_address_order.
+// OIDs are named with the MIB modules of mib_paths when there are any, which also
+// describe the notification and the objects it should carry. The vendor is named with the
+// enterprise numbers registry, and the severity is set by the first matching severity rule,
+// or by the event of the SNMPTT configuration files which matches the notification.
+// Notifications with a message template get the rendered message as their body, and the
+// others the FORMAT of their SNMPTT event. Templates which fail to render are counted, and
+// logged at most once per templateWarningInterval.
+func (snmptrapRcvr *snmptrapReceiver) trapCallback(ctx context.Context, packet *gosnmp.SnmpPacket, peer net.Addr, local net.Addr, communityAuthorized bool) error {
+	original := packet
+	if snmptrapRcvr.config.NormalizeV1Traps && packet.Version == gosnmp.Version1 {
+		packet = normalizeV1Trap(packet)
+	}
+
+	logs := trapToLogs(packet, peer, local, time.Now())
+	resourceLogs := logs.ResourceLogs().At(0)
+	logRecord := resourceLogs.ScopeLogs().At(0).LogRecords().At(0)
+	attributes := logRecord.Attributes()
+	if packet != original {
+		encodeNormalizedV1Trap(original, packet, logRecord.Body().Map())
+		putV1TrapAttributes(attributes, original)
+	}
+	addresses := newAgentAddresses(original, peer)
+	putAgentAddresses(resourceLogs.Resource().Attributes(), attributes, addresses, snmptrapRcvr.config.AgentAddressOrder)
+	putGenericTrapAttributes(attributes, packet)
+	mibs := snmptrapRcvr.mibs.Load()
+	putTrapOID(attributes, packet, mibs)
+	putVendor(attributes, packet, snmptrapRcvr.enterprises)
+	annotateVarbinds(logRecord, packet, mibs)
+	putNotification(logRecord, packet, mibs)
+	// Without any of the addresses of agent_address_order, the sender stands for the agent
+	agent, _ := addresses.resolve(snmptrapRcvr.config.AgentAddressOrder)
+	if !agent.IsValid() {
+		agent = addresses.source
+	}
+	snmptrapRcvr.severities.apply(logRecord, packet, agent, mibs)
+	snmptrapRcvr.snmptt.apply(logRecord, packet, peer, agent, mibs)
+	if !communityAuthorized {
+		attributes.PutBool(attributeSNMPCommunityAuthorized, false)
+	}
+	if err := snmptrapRcvr.templates.apply(logRecord, packet, peer, agent, mibs); err != nil {
+		snmptrapRcvr.telemetry.recordTemplateFailure(ctx, strings.TrimPrefix(trapOID(packet), "."))
+		snmptrapRcvr.sampledLogger.Warn("Failed to render the message of a trap", zap.Stringer("source", peer), zap.Error(err))
+	}
+
+	obsrecv := snmptrapRcvr.obsrecvs[addrTransport(local)]
+	ctx = obsrecv.StartLogsOp(ctx)
+	err := snmptrapRcvr.nextConsumer.ConsumeLogs(ctx, logs)
+	obsrecv.EndLogsOp(ctx, "snmptrap", logs.LogRecordCount(), err)
+	if err != nil {
+		snmptrapRcvr.logger.Error("Failed to consume trap", zap.Stringer("source", peer), zap.Error(err))
+	}
+	return err
+}
+
+// getSNMPVersion gets the gosnmp version based on config version
+func getSNMPVersion(version string) gosnmp.SnmpVersion {
+	switch strings.ToLower(version) {
+	case "v1":
+		return gosnmp.Version1
+	case "v3":
+		return gosnmp.Version3
+	default:
+		return gosnmp.Version2c
+	}
+}
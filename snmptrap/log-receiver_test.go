@@ -0,0 +1,164 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package snmptrapreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver"
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+)
+
+// getFreeUDPPort returns a port that is currently free on the loopback interface
+func getFreeUDPPort(t *testing.T) int {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+	return conn.LocalAddr().(*net.UDPAddr).Port
+}
+
+// sendTrap sends a trap to the receiver using a gosnmp client
+func sendTrap(t *testing.T, version gosnmp.SnmpVersion, port int, trap gosnmp.SnmpTrap) {
+	client := &gosnmp.GoSNMP{
+		Target:    "127.0.0.1",
+		Port:      uint16(port),
+		Transport: "udp",
+		Community: "public",
+		Version:   version,
+		Timeout:   time.Second,
+		Retries:   0,
+		MaxOids:   gosnmp.MaxOids,
+	}
+	require.NoError(t, client.Connect())
+	defer client.Conn.Close()
+
+	_, err := client.SendTrap(trap)
+	require.NoError(t, err)
+}
+
+func TestReceiveTrap(t *testing.T) {
+	port := getFreeUDPPort(t)
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.ListenAddress = "udp://127.0.0.1:" + strconv.Itoa(port)
+
+	sink := new(consumertest.LogsSink)
+	rcvr, err := NewFactory().CreateLogsReceiver(context.Background(), receivertest.NewNopCreateSettings(), cfg, sink)
+	require.NoError(t, err)
+	require.NoError(t, rcvr.Start(context.Background(), componenttest.NewNopHost()))
+	defer func() {
+		require.NoError(t, rcvr.Shutdown(context.Background()))
+	}()
+
+	sendTrap(t, gosnmp.Version2c, port, gosnmp.SnmpTrap{
+		Variables: []gosnmp.SnmpPDU{
+			{Name: ".1.3.6.1.2.1.1.3.0", Type: gosnmp.TimeTicks, Value: uint32(100)},
+			{Name: ".1.3.6.1.6.3.1.1.4.1.0", Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.6.3.1.1.5.1"},
+		},
+	})
+
+	require.Eventually(t, func() bool {
+		return sink.LogRecordCount() == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	body := sink.AllLogs()[0].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Body().Map()
+	pduType, ok := body.Get(bodyPDUType)
+	require.True(t, ok)
+	require.Equal(t, "SNMPv2Trap", pduType.Str())
+	varbinds, ok := body.Get(bodyVarbinds)
+	require.True(t, ok)
+	require.Equal(t, 2, varbinds.Slice().Len())
+}
+
+func TestReceiveTrapMultipleListenAddresses(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.ListenAddresses = []ListenAddressConfig{
+		{Address: "udp://127.0.0.1:0"},
+		{Address: "udp4://127.0.0.1:0", Version: "v1"},
+		{Address: "tcp://127.0.0.1:0"},
+	}
+
+	sink := new(consumertest.LogsSink)
+	rcvr, err := newSnmptrapReceiver(receivertest.NewNopCreateSettings(), cfg, sink)
+	require.NoError(t, err)
+	require.NoError(t, rcvr.Start(context.Background(), componenttest.NewNopHost()))
+	defer func() {
+		require.NoError(t, rcvr.Shutdown(context.Background()))
+	}()
+	require.Len(t, rcvr.listeners, 3)
+
+	versions := []gosnmp.SnmpVersion{gosnmp.Version2c, gosnmp.Version1, gosnmp.Version2c}
+	transports := []string{"udp", "udp", "tcp"}
+	expectedPorts := map[int64]bool{}
+	for i, listener := range rcvr.listeners {
+		_, port := splitAddr(listener.localAddr())
+		expectedPorts[int64(port)] = true
+
+		client := &gosnmp.GoSNMP{
+			Target:    "127.0.0.1",
+			Port:      uint16(port),
+			Transport: transports[i],
+			Community: "public",
+			Version:   versions[i],
+			Timeout:   time.Second,
+			MaxOids:   gosnmp.MaxOids,
+		}
+		require.NoError(t, client.Connect())
+		_, err = client.SendTrap(gosnmp.SnmpTrap{
+			Variables: []gosnmp.SnmpPDU{
+				{Name: ".1.3.6.1.2.1.1.3.0", Type: gosnmp.TimeTicks, Value: uint32(100)},
+				{Name: ".1.3.6.1.6.3.1.1.4.1.0", Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.6.3.1.1.5.1"},
+			},
+			Enterprise:   ".1.3.6.1.4.1.8072",
+			AgentAddress: "127.0.0.1",
+		})
+		require.NoError(t, err)
+		client.Conn.Close()
+	}
+
+	require.Eventually(t, func() bool {
+		return sink.LogRecordCount() == 3
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// Every record is tagged with the socket that received it
+	for _, logs := range sink.AllLogs() {
+		logRecord := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+		requireAttribute(t, logRecord, attributeNetSockHostAddr, "127.0.0.1")
+		port, ok := logRecord.Attributes().Get(attributeNetSockHostPort)
+		require.True(t, ok)
+		require.True(t, expectedPorts[port.Int()])
+		delete(expectedPorts, port.Int())
+	}
+	require.Empty(t, expectedPorts)
+}
+
+// A receiver that fails to bind one of its addresses must release the others
+func TestStartReleasesListenersOnError(t *testing.T) {
+	occupied, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer occupied.Close()
+
+	free := getFreeUDPPort(t)
+	cfg := createDefaultConfig().(*Config)
+	cfg.ListenAddresses = []ListenAddressConfig{
+		{Address: "udp://127.0.0.1:" + strconv.Itoa(free)},
+		{Address: "udp://" + occupied.LocalAddr().String()},
+	}
+
+	rcvr, err := newSnmptrapReceiver(receivertest.NewNopCreateSettings(), cfg, new(consumertest.LogsSink))
+	require.NoError(t, err)
+	require.Error(t, rcvr.Start(context.Background(), componenttest.NewNopHost()))
+	require.NoError(t, rcvr.Shutdown(context.Background()))
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:"+strconv.Itoa(free))
+	require.NoError(t, err)
+	conn.Close()
+}
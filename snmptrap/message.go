@@ -0,0 +1,212 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package snmptrapreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver"
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+	"text/template"
+
+	"github.com/gosnmp/gosnmp"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver/internal/mib"
+)
+
+var (
+	errMsgInvalidTemplate = `invalid template: %w`
+
+	errEmptyTemplateTrapOID = errors.New("trap_oid must be specified")
+	errEmptyTemplate        = errors.New("template must be specified")
+	errDuplicateTemplateOID = errors.New("trap_oid is given more than once")
+)
+
+// messageTemplates render the body of the log records of notifications, by their snmpTrapOID
+// without a leading dot
+type messageTemplates map[string]*template.Template
+
+// newMessageTemplates compiles the message templates of the configuration. It returns nil when
+// there are none, in which case the body of log records is left as is.
+func newMessageTemplates(cfg *Config) (messageTemplates, error) {
+	if len(cfg.MessageTemplates) == 0 {
+		return nil, nil
+	}
+
+	templates := messageTemplates{}
+	for i, templateCfg := range cfg.MessageTemplates {
+		compiled, err := parseMessageTemplate(templateCfg)
+		if err != nil {
+			return nil, fmt.Errorf("message_templates[%d]: %w", i, err)
+		}
+		templates[strings.TrimPrefix(templateCfg.TrapOID, ".")] = compiled
+	}
+	return templates, nil
+}
+
+// parseMessageTemplate compiles a message template, returning all of its problems at once
+func parseMessageTemplate(cfg MessageTemplateConfig) (*template.Template, error) {
+	var combinedErr error
+
+	oid := strings.TrimPrefix(cfg.TrapOID, ".")
+	if cfg.TrapOID == "" {
+		combinedErr = errors.Join(combinedErr, errEmptyTemplateTrapOID)
+	} else if !isDottedOID(oid) {
+		combinedErr = errors.Join(combinedErr, fmt.Errorf(errMsgInvalidRuleOID, "trap_oid", cfg.TrapOID))
+	}
+
+	if cfg.Template == "" {
+		return nil, errors.Join(combinedErr, errEmptyTemplate)
+	}
+	compiled, err := template.New(oid).Parse(cfg.Template)
+	if err != nil {
+		combinedErr = errors.Join(combinedErr, fmt.Errorf(errMsgInvalidTemplate, err))
+	}
+
+	if combinedErr != nil {
+		return nil, combinedErr
+	}
+	return compiled, nil
+}
+
+// apply renders the message of a notification which has a template into the body of its log
+// record, and moves the map of the PDU that was there to the snmp.pdu attribute. The log record
+// is left as is when the template fails to render.
+func (templates messageTemplates) apply(logRecord plog.LogRecord, packet *gosnmp.SnmpPacket, peer net.Addr, agent netip.Addr, mibs *mib.MIB) error {
+	oid := strings.TrimPrefix(trapOID(packet), ".")
+	compiled, ok := templates[oid]
+	if !ok {
+		return nil
+	}
+
+	var message strings.Builder
+	if err := compiled.Execute(&message, newTrapMessage(logRecord, packet, peer, agent, mibs)); err != nil {
+		return err
+	}
+	setMessage(logRecord, message.String())
+	return nil
+}
+
+// setMessage sets the body of a log record to a message, and moves the map of the PDU that was
+// there to the snmp.pdu attribute
+func setMessage(logRecord plog.LogRecord, message string) {
+	if logRecord.Body().Type() == pcommon.ValueTypeMap {
+		logRecord.Body().Map().CopyTo(logRecord.Attributes().PutEmptyMap(attributeSNMPPDU))
+	}
+	logRecord.Body().SetStr(message)
+}
+
+// trapMessage is the data message templates are executed with. Its fields are the fields of
+// the notification, and its methods look up its varbinds and the attributes of its log record.
+type trapMessage struct {
+	// Source is the IP address of the sender of the packet, which may be a relay, and Agent that
+	// of the agent which originated the notification, resolved in the order of agent_address_order
+	Source string
+	Agent  string
+	// TrapOID is the snmpTrapOID, dotted without a leading dot, and TrapName its name when the
+	// MIBs resolve it, such as IF-MIB::linkDown
+	TrapOID  string
+	TrapName string
+	// Version is v1, v2c or v3
+	Version   string
+	Community string
+	// Enterprise, AgentAddress, GenericTrap and SpecificTrap are the fields of v1 traps
+	Enterprise   string
+	AgentAddress string
+	GenericTrap  int
+	SpecificTrap int
+	// Uptime is the sysUpTime of the sender in hundredths of a second
+	Uptime uint32
+
+	packet     *gosnmp.SnmpPacket
+	mibs       *mib.MIB
+	attributes pcommon.Map
+}
+
+func newTrapMessage(logRecord plog.LogRecord, packet *gosnmp.SnmpPacket, peer net.Addr, agent netip.Addr, mibs *mib.MIB) *trapMessage {
+	message := &trapMessage{
+		TrapOID:      strings.TrimPrefix(trapOID(packet), "."),
+		Version:      versionToString(packet.Version),
+		Community:    packet.Community,
+		Enterprise:   strings.TrimPrefix(packet.Enterprise, "."),
+		AgentAddress: packet.AgentAddress,
+		GenericTrap:  packet.GenericTrap,
+		SpecificTrap: packet.SpecificTrap,
+		packet:       packet,
+		mibs:         mibs,
+		attributes:   logRecord.Attributes(),
+	}
+	if ip, _ := splitAddr(peer); ip != nil {
+		message.Source = ip.String()
+	}
+	if agent.IsValid() {
+		message.Agent = agent.Unmap().String()
+	}
+	if mibs != nil {
+		message.TrapName = mibs.Name(message.TrapOID)
+	}
+	message.Uptime, _ = trapUptime(packet)
+	return message
+}
+
+// Varbind returns the value of the first varbind of an object, rendered with the MIBs like the
+// value.display of the body, such as the label of an enumeration. The object is given by its
+// name, such as ifDescr or IF-MIB::ifDescr, or by its OID, which also matches its instances.
+// An empty string is returned when the notification carries no varbind of the object.
+func (message *trapMessage) Varbind(object string) string {
+	variable, node, ok := message.varbind(object)
+	if !ok {
+		return ""
+	}
+	if display, ok := displayValue(message.mibs, node, variable); ok {
+		return display
+	}
+	return varbindText(variable)
+}
+
+// Raw is like Varbind, but returns the value as received: numbers in decimal, strings as they
+// are and OIDs without a leading dot
+func (message *trapMessage) Raw(object string) string {
+	variable, _, ok := message.varbind(object)
+	if !ok {
+		return ""
+	}
+	return varbindText(variable)
+}
+
+// Attribute returns an attribute of the log record as a string, such as snmp.index.ifIndex or
+// vendor, or an empty string when the log record doesn't have it
+func (message *trapMessage) Attribute(key string) string {
+	value, ok := message.attributes.Get(key)
+	if !ok {
+		return ""
+	}
+	return value.AsString()
+}
+
+// varbind returns the first varbind of an object, with its node when the MIBs know it
+func (message *trapMessage) varbind(object string) (gosnmp.SnmpPDU, *mib.Node, bool) {
+	oid := strings.TrimPrefix(object, ".")
+	byOID := isDottedOID(oid)
+	for _, variable := range message.packet.Variables {
+		var node *mib.Node
+		if message.mibs != nil {
+			node, _ = message.mibs.Lookup(variable.Name)
+		}
+		switch {
+		case byOID:
+			if isUnderOID(strings.TrimPrefix(variable.Name, "."), oid) {
+				return variable, node, true
+			}
+		case node != nil:
+			if node.Name == object || node.Module+"::"+node.Name == object {
+				return variable, node, true
+			}
+		}
+	}
+	return gosnmp.SnmpPDU{}, nil, false
+}
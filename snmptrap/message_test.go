@@ -0,0 +1,195 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package snmptrapreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver"
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver/internal/mib"
+)
+
+func TestMessageTemplates(t *testing.T) {
+	mibs, err := mib.LoadStandard()
+	require.NoError(t, err)
+
+	templates, err := newMessageTemplates(&Config{MessageTemplates: []MessageTemplateConfig{
+		{
+			TrapOID:  ".1.3.6.1.6.3.1.1.5.3",
+			Template: `Interface {{ .Varbind "ifDescr" }} (ifIndex {{ .Attribute "snmp.index.ifIndex" }}) went {{ .Varbind "IF-MIB::ifOperStatus" }} on {{ .Agent }} via {{ .Source }}`,
+		},
+		{
+			TrapOID:  "1.3.6.1.4.1.32473.1.1.0.1",
+			Template: `{{ .TrapName }} {{ .Version }}/{{ .Community }} from {{ .AgentAddress }} ({{ .Enterprise }} {{ .GenericTrap }}/{{ .SpecificTrap }}) up {{ .Uptime }}: fan {{ .Raw "1.3.6.1.4.1.32473.2.1.1.1" }}{{ .Varbind "missing" }}`,
+		},
+		{
+			TrapOID:  "1.3.6.1.4.1.32473.1.1.0.2",
+			Template: `{{ .Nope }}`,
+		},
+	}})
+	require.NoError(t, err)
+
+	peer := &net.UDPAddr{IP: net.ParseIP("192.0.2.1"), Port: 1620}
+	local := &net.UDPAddr{IP: net.ParseIP("192.0.2.2"), Port: 162}
+	agent := netip.MustParseAddr("192.0.2.10")
+	newLogRecord := func(packet *gosnmp.SnmpPacket) plog.LogRecord {
+		logs := trapToLogs(packet, peer, local, time.Now())
+		return logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+	}
+
+	linkDown := &gosnmp.SnmpPacket{
+		Version:   gosnmp.Version2c,
+		Community: "public",
+		PDUType:   gosnmp.SNMPv2Trap,
+		Variables: []gosnmp.SnmpPDU{
+			{Name: oidSysUpTime, Type: gosnmp.TimeTicks, Value: uint32(100)},
+			{Name: oidSnmpTrapOID, Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.6.3.1.1.5.3"},
+			{Name: ".1.3.6.1.2.1.2.2.1.2.12", Type: gosnmp.OctetString, Value: []byte("Gi0/1")},
+			{Name: ".1.3.6.1.2.1.2.2.1.8.12", Type: gosnmp.Integer, Value: 2},
+		},
+	}
+	logRecord := newLogRecord(linkDown)
+	annotateVarbinds(logRecord, linkDown, mibs)
+	require.NoError(t, templates.apply(logRecord, linkDown, peer, agent, mibs))
+	require.Equal(t, "Interface Gi0/1 (ifIndex 12) went down on 192.0.2.10 via 192.0.2.1", logRecord.Body().Str())
+
+	// The PDU is moved to an attribute, from which it can still be decoded
+	pdu, ok := logRecord.Attributes().Get(attributeSNMPPDU)
+	require.True(t, ok)
+	varbinds, ok := pdu.Map().Get(bodyVarbinds)
+	require.True(t, ok)
+	require.Equal(t, 4, varbinds.Slice().Len())
+	decoded, err := DecodeLogRecord(logRecord)
+	require.NoError(t, err)
+	require.Equal(t, linkDown.Variables[2].Value, decoded.Variables[2].Value)
+
+	// The fields of v1 traps are available, and missing varbinds render as nothing
+	fanFailure := &gosnmp.SnmpPacket{
+		Version:   gosnmp.Version1,
+		Community: "public",
+		PDUType:   gosnmp.Trap,
+		Variables: []gosnmp.SnmpPDU{{Name: ".1.3.6.1.4.1.32473.2.1.1.1.0", Type: gosnmp.Integer, Value: 3}},
+		SnmpTrap:  gosnmp.SnmpTrap{Enterprise: ".1.3.6.1.4.1.32473.1.1", AgentAddress: "192.0.2.10", GenericTrap: 6, SpecificTrap: 1, Timestamp: 4200},
+	}
+	logRecord = newLogRecord(fanFailure)
+	require.NoError(t, templates.apply(logRecord, fanFailure, peer, agent, nil))
+	require.Equal(t, " v1/public from 192.0.2.10 (1.3.6.1.4.1.32473.1.1 6/1) up 4200: fan 3", logRecord.Body().Str())
+
+	// A template which fails to render leaves the log record as is
+	fanFailure.SpecificTrap = 2
+	logRecord = newLogRecord(fanFailure)
+	require.ErrorContains(t, templates.apply(logRecord, fanFailure, peer, agent, nil), "can't evaluate field Nope")
+	require.Equal(t, pcommon.ValueTypeMap, logRecord.Body().Type())
+	_, ok = logRecord.Attributes().Get(attributeSNMPPDU)
+	require.False(t, ok)
+
+	// Notifications without a template are left as is
+	fanFailure.SpecificTrap = 3
+	logRecord = newLogRecord(fanFailure)
+	require.NoError(t, templates.apply(logRecord, fanFailure, peer, agent, mibs))
+	require.Equal(t, pcommon.ValueTypeMap, logRecord.Body().Type())
+
+	// Without MIBs, varbinds are only found by their OID
+	linkDown.Variables[3].Value = 7
+	logRecord = newLogRecord(linkDown)
+	require.NoError(t, templates.apply(logRecord, linkDown, peer, agent, nil))
+	require.Equal(t, "Interface  (ifIndex ) went  on 192.0.2.10 via 192.0.2.1", logRecord.Body().Str())
+}
+
+func TestReceiveTrapWithFailingTemplate(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.ListenAddress = "udp://127.0.0.1:0"
+	// The template parses, and only fails when executed with a notification carrying ifDescr
+	cfg.MessageTemplates = []MessageTemplateConfig{{
+		TrapOID:  "1.3.6.1.6.3.1.1.5.3",
+		Template: `{{ if .Varbind "ifDescr" }}Interface {{ .Varbind }} went down{{ end }}`,
+	}}
+
+	reader := sdkmetric.NewManualReader()
+	core, logs := observer.New(zapcore.WarnLevel)
+	settings := receivertest.NewNopCreateSettings()
+	settings.MeterProvider = sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	settings.Logger = zap.New(core)
+	sink := new(consumertest.LogsSink)
+	rcvr, err := newSnmptrapReceiver(settings, cfg, sink)
+	require.NoError(t, err)
+	require.NoError(t, rcvr.Start(context.Background(), componenttest.NewNopHost()))
+	defer func() {
+		require.NoError(t, rcvr.Shutdown(context.Background()))
+	}()
+
+	_, port := splitAddr(rcvr.listeners[0].localAddr())
+	for i := 0; i < 3; i++ {
+		sendTrap(t, gosnmp.Version2c, port, gosnmp.SnmpTrap{
+			Variables: []gosnmp.SnmpPDU{
+				{Name: oidSysUpTime, Type: gosnmp.TimeTicks, Value: uint32(100)},
+				{Name: oidSnmpTrapOID, Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.6.3.1.1.5.3"},
+				{Name: ".1.3.6.1.2.1.2.2.1.2.12", Type: gosnmp.OctetString, Value: []byte("Gi0/1")},
+			},
+		})
+	}
+
+	require.Eventually(t, func() bool {
+		return sink.LogRecordCount() == 3
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// Every failure is counted, and the log records keep the map of the PDU as their body
+	require.EqualValues(t, 3, counterValueWith(t, reader, metricTemplateFailures, attributeTrapOID, "1.3.6.1.6.3.1.1.5.3"))
+	for _, resourceLogs := range sink.AllLogs() {
+		logRecord := resourceLogs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+		require.Equal(t, pcommon.ValueTypeMap, logRecord.Body().Type())
+	}
+
+	// Only the first failure is logged
+	warnings := logs.FilterMessage("Failed to render the message of a trap").All()
+	require.Len(t, warnings, 1)
+	require.Equal(t, zapcore.WarnLevel, warnings[0].Level)
+	require.Contains(t, warnings[0].ContextMap()["error"], "wrong number of args for Varbind")
+}
+
+func TestParseMessageTemplateErrors(t *testing.T) {
+	type testCase struct {
+		name        string
+		template    MessageTemplateConfig
+		expectedErr string
+	}
+
+	testCases := []testCase{
+		{name: "MissingTrapOID", template: MessageTemplateConfig{Template: "hello"}, expectedErr: "trap_oid must be specified"},
+		{name: "BadTrapOID", template: MessageTemplateConfig{TrapOID: "IF-MIB::linkDown", Template: "hello"}, expectedErr: "invalid trap_oid 'IF-MIB::linkDown': must be a dotted OID"},
+		{name: "MissingTemplate", template: MessageTemplateConfig{TrapOID: "1.3.6.1.6.3.1.1.5.3"}, expectedErr: "template must be specified"},
+		{
+			name:        "BadSyntax",
+			template:    MessageTemplateConfig{TrapOID: "1.3.6.1.6.3.1.1.5.3", Template: `{{ .Varbind "ifDescr" }`},
+			expectedErr: `invalid template: template: 1.3.6.1.6.3.1.1.5.3:1: unexpected "}" in operand`,
+		},
+		{
+			name:        "UnclosedAction",
+			template:    MessageTemplateConfig{TrapOID: "1.3.6.1.6.3.1.1.5.3", Template: `{{ if .Source }}up`},
+			expectedErr: "invalid template: template: 1.3.6.1.6.3.1.1.5.3:1: unexpected EOF",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := parseMessageTemplate(test.template)
+			require.EqualError(t, err, test.expectedErr)
+		})
+	}
+}
@@ -0,0 +1,355 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package snmptrapreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver"
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver/internal/mib"
+)
+
+// Attributes holding the snmpTrapOID of a notification, and its name once resolved with the MIBs
+const (
+	attributeSNMPTrapOID     = "snmp.trap_oid"
+	attributeSNMPTrapOIDName = "snmp.trap_oid.name"
+)
+
+// attributePrefixIndex is followed by the name of an object of the index of a table, such as
+// snmp.index.ifIndex, to hold its value decoded from the instance of a varbind
+const attributePrefixIndex = "snmp.index."
+
+// Keys added to each varbind of the body to help humans read it. They are not part of the
+// schema, and DecodeLogRecord ignores them.
+const (
+	// bodyVarbindOIDName holds the name of the OID resolved with the MIBs, next to "oid"
+	bodyVarbindOIDName = "oid.name"
+	// bodyVarbindValueDisplay holds the value rendered with the syntax of the object, next to "value"
+	bodyVarbindValueDisplay = "value.display"
+	// bodyVarbindIndex holds the index of the instance of a column, by the names of the objects of the index
+	bodyVarbindIndex = "index"
+)
+
+// mibLoader loads the MIBs of the configuration, and loads them again when their files change
+type mibLoader struct {
+	cfg    *Config
+	logger *zap.Logger
+	// stamps hold the size and modification time of the files the MIBs were last loaded from
+	stamps map[string]fileStamp
+	// problems are the errors of the modules of the current MIBs, which don't prevent reloading them
+	problems map[string]bool
+}
+
+// fileStamp tells whether a file has changed
+type fileStamp struct {
+	size    int64
+	modTime time.Time
+}
+
+func newMIBLoader(cfg *Config, logger *zap.Logger) *mibLoader {
+	return &mibLoader{cfg: cfg, logger: logger}
+}
+
+// enabled tells whether there are MIBs to load
+func (l *mibLoader) enabled() bool {
+	return l.cfg.StandardMIBs || l.hasFiles()
+}
+
+// hasFiles tells whether MIBs are loaded from files, which can change while the receiver runs
+func (l *mibLoader) hasFiles() bool {
+	return l.cfg.CompiledMIB != "" || len(l.cfg.MIBPaths) > 0
+}
+
+// load loads the compiled MIB, or the MIB modules of the configured directories on top of the
+// standard ones, or returns nil when there are none. Modules which can't be loaded are logged and skipped, so that a single
+// broken vendor MIB doesn't prevent the others from being used.
+func (l *mibLoader) load() *mib.MIB {
+	if !l.enabled() {
+		return nil
+	}
+	l.stamps = l.stat()
+	mibs, err := l.read()
+	l.problems = problems(err)
+	if err != nil {
+		if l.cfg.CompiledMIB != "" {
+			l.logger.Warn("The compiled MIB could not be loaded, OIDs will not be named", zap.Error(err))
+		} else {
+			l.logger.Warn("Some MIB modules could not be loaded", zap.Error(err))
+		}
+	}
+	return mibs
+}
+
+// changed tells whether the files of the MIBs have changed since they were last loaded
+func (l *mibLoader) changed() bool {
+	stamps := l.stat()
+	if len(stamps) != len(l.stamps) {
+		return true
+	}
+	for file, stamp := range stamps {
+		if previous, ok := l.stamps[file]; !ok || previous.size != stamp.size || !previous.modTime.Equal(stamp.modTime) {
+			return true
+		}
+	}
+	return false
+}
+
+// reload loads the MIBs again. An error is returned rather than MIBs which have problems the
+// current ones didn't have, such as a newly added module which fails to parse, so that the
+// current MIBs keep being used. The files are not loaded again until they change once more.
+func (l *mibLoader) reload() (*mib.MIB, error) {
+	l.stamps = l.stat()
+	mibs, err := l.read()
+	if l.cfg.CompiledMIB != "" {
+		if err != nil {
+			return nil, err
+		}
+		l.problems = nil
+		return mibs, nil
+	}
+
+	var introduced []error
+	for _, problem := range unjoin(err) {
+		if !l.problems[problem.Error()] {
+			introduced = append(introduced, problem)
+		}
+	}
+	if len(introduced) > 0 {
+		return nil, errors.Join(introduced...)
+	}
+	l.problems = problems(err)
+	return mibs, nil
+}
+
+// read loads the MIBs from their files
+func (l *mibLoader) read() (*mib.MIB, error) {
+	if l.cfg.CompiledMIB != "" {
+		return mib.LoadCompiled(l.cfg.CompiledMIB)
+	}
+	if l.cfg.StandardMIBs {
+		return mib.LoadStandard(l.cfg.MIBPaths...)
+	}
+	return mib.Load(l.cfg.MIBPaths...)
+}
+
+// stat returns the stamps of the compiled MIB or of the files at the top of the MIB directories.
+// Files which can't be read have no stamp, so that they are noticed when they appear.
+func (l *mibLoader) stat() map[string]fileStamp {
+	stamps := map[string]fileStamp{}
+	add := func(file string, info fs.FileInfo) {
+		stamps[file] = fileStamp{size: info.Size(), modTime: info.ModTime()}
+	}
+
+	if l.cfg.CompiledMIB != "" {
+		if info, err := os.Stat(l.cfg.CompiledMIB); err == nil {
+			add(l.cfg.CompiledMIB, info)
+		}
+		return stamps
+	}
+	for _, dir := range l.cfg.MIBPaths {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if info, err := entry.Info(); err == nil && !info.IsDir() {
+				add(filepath.Join(dir, entry.Name()), info)
+			}
+		}
+	}
+	return stamps
+}
+
+// unjoin returns the errors joined by the MIB loader
+func unjoin(err error) []error {
+	if err == nil {
+		return nil
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		return joined.Unwrap()
+	}
+	return []error{err}
+}
+
+// problems indexes the errors joined by the MIB loader by their message
+func problems(err error) map[string]bool {
+	indexed := map[string]bool{}
+	for _, problem := range unjoin(err) {
+		indexed[problem.Error()] = true
+	}
+	return indexed
+}
+
+// watchMIBs loads the MIBs again whenever their files change, until the context is done. The
+// MIBs used by the trap callback are replaced at once, and only when they could be reloaded.
+func (snmptrapRcvr *snmptrapReceiver) watchMIBs(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if !snmptrapRcvr.mibLoader.changed() {
+			continue
+		}
+
+		mibs, err := snmptrapRcvr.mibLoader.reload()
+		if err != nil {
+			snmptrapRcvr.logger.Warn("The MIBs have changed but could not be reloaded, the previous ones are still used", zap.Error(err))
+			snmptrapRcvr.telemetry.recordMIBReload(ctx, reloadFailure)
+			continue
+		}
+		snmptrapRcvr.mibs.Store(mibs)
+		snmptrapRcvr.logger.Info("Reloaded the MIBs")
+		snmptrapRcvr.telemetry.recordMIBReload(ctx, reloadSuccess)
+	}
+}
+
+// putTrapOID records the snmpTrapOID of a notification, along with its name when the MIBs resolve it
+func putTrapOID(attributes pcommon.Map, packet *gosnmp.SnmpPacket, mibs *mib.MIB) {
+	oid := trapOID(packet)
+	if oid == "" {
+		return
+	}
+	attributes.PutStr(attributeSNMPTrapOID, strings.TrimPrefix(oid, "."))
+	if mibs == nil {
+		return
+	}
+	if name := mibs.Name(oid); name != "" {
+		attributes.PutStr(attributeSNMPTrapOIDName, name)
+	}
+}
+
+// annotateVarbinds adds the name of its OID to each varbind of the body of a log record, along
+// with its value rendered for humans when the syntax of the object or the value itself tells how,
+// and the index of its instance when it is a column of a table.
+// The varbinds of the body are those of the packet, in the same order.
+func annotateVarbinds(logRecord plog.LogRecord, packet *gosnmp.SnmpPacket, mibs *mib.MIB) {
+	varbinds, ok := logRecord.Body().Map().Get(bodyVarbinds)
+	if !ok || varbinds.Slice().Len() != len(packet.Variables) {
+		return
+	}
+	for i, variable := range packet.Variables {
+		varbind := varbinds.Slice().At(i).Map()
+		var node *mib.Node
+		if mibs != nil {
+			var suffix []uint32
+			node, suffix = mibs.Lookup(variable.Name)
+			if name := mibs.Name(variable.Name); name != "" {
+				varbind.PutStr(bodyVarbindOIDName, name)
+			}
+			if node != nil {
+				putIndex(logRecord.Attributes(), varbind, mibs, node, suffix)
+			}
+		}
+		if display, ok := displayValue(mibs, node, variable); ok {
+			varbind.PutStr(bodyVarbindValueDisplay, display)
+		}
+	}
+}
+
+// putIndex decodes the instance of a column with the INDEX clause of its table, such as the
+// ifIndex 12 of ifDescr.12, into the index of the varbind and into an attribute for each object
+// of the index. When several varbinds carry the same object of an index, the first one sets its
+// attribute.
+func putIndex(attributes pcommon.Map, varbind pcommon.Map, mibs *mib.MIB, node *mib.Node, suffix []uint32) {
+	values, ok := node.DecodeIndex(suffix)
+	if !ok {
+		return
+	}
+	index := varbind.PutEmptyMap(bodyVarbindIndex)
+	for _, value := range values {
+		putIndexValue(index, value.Object.Name, mibs, value)
+		key := attributePrefixIndex + value.Object.Name
+		if _, exists := attributes.Get(key); !exists {
+			putIndexValue(attributes, key, mibs, value)
+		}
+	}
+}
+
+// putIndexValue puts a value of an index in a map. Integers are kept as such unless they are
+// enumerated, and the other values are rendered like those of varbinds. An InetAddress of 4 or
+// 16 octets is rendered as an IP address, since it has no DISPLAY-HINT.
+func putIndexValue(m pcommon.Map, key string, mibs *mib.MIB, value mib.IndexValue) {
+	t := value.Object.Type
+	switch v := value.Value.(type) {
+	case int64:
+		if label, ok := t.Label(v); ok && t.Base == mib.BaseInteger {
+			m.PutStr(key, label)
+			return
+		}
+		m.PutInt(key, v)
+	case []byte:
+		if t.Name == "InetAddress" && (len(v) == net.IPv4len || len(v) == net.IPv6len) {
+			m.PutStr(key, net.IP(v).String())
+			return
+		}
+		display, _ := displayValue(mibs, value.Object, gosnmp.SnmpPDU{Type: gosnmp.OctetString, Value: v})
+		m.PutStr(key, display)
+	case string:
+		if t.Base == mib.BaseObjectIdentifier {
+			if name := mibs.Name(v); name != "" {
+				m.PutStr(key, name)
+				return
+			}
+		}
+		m.PutStr(key, v)
+	}
+}
+
+// displayValue renders the value of a varbind with the syntax of its object, if any:
+//   - enumerations by their label, such as TruthValue or RowStatus values
+//   - BITS by the labels of the bits which are set
+//   - OCTET STRINGs with the DISPLAY-HINT of their textual convention, such as MAC addresses,
+//     and otherwise as text or hexadecimal octets depending on whether they are printable
+//   - OIDs by their name
+//
+// It returns false when the value can't be rendered any better than it already is.
+func displayValue(mibs *mib.MIB, node *mib.Node, variable gosnmp.SnmpPDU) (string, bool) {
+	var t *mib.Type
+	if node != nil {
+		t = node.Type
+	}
+
+	switch variable.Type { // nolint:exhaustive
+	case gosnmp.ObjectIdentifier:
+		if mibs == nil {
+			return "", false
+		}
+		oid, _ := variable.Value.(string)
+		name := mibs.Name(oid)
+		return name, name != ""
+	case gosnmp.Integer:
+		if t == nil || t.Base != mib.BaseInteger {
+			return "", false
+		}
+		return t.Label(gosnmp.ToBigInt(variable.Value).Int64())
+	case gosnmp.OctetString:
+		value, _ := variable.Value.([]byte)
+		if t != nil && t.Base == mib.BaseBits {
+			return strings.Join(t.BitLabels(value), " "), true
+		}
+		if t != nil && t.Base == mib.BaseOctetString && t.Hint != "" {
+			// Hints which can't be parsed are ignored like missing ones
+			if text, err := mib.FormatOctetString(t.Hint, value); err == nil {
+				return text, true
+			}
+		}
+		return mib.FormatOctets(value), true
+	}
+	return "", false
+}
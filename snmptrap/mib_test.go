@@ -0,0 +1,539 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package snmptrapreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver"
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver/internal/mib"
+)
+
+// testMIBPath holds the modules used by the tests of the MIB loader
+var testMIBPath = filepath.Join("internal", "mib", "testdata", "mibs")
+
+func TestPutTrapOID(t *testing.T) {
+	mibs, err := mib.Load(testMIBPath)
+	require.NoError(t, err)
+
+	v2 := func(trapOID string) *gosnmp.SnmpPacket {
+		return &gosnmp.SnmpPacket{
+			Version: gosnmp.Version2c,
+			PDUType: gosnmp.SNMPv2Trap,
+			Variables: []gosnmp.SnmpPDU{
+				{Name: oidSysUpTime, Type: gosnmp.TimeTicks, Value: uint32(100)},
+				{Name: oidSnmpTrapOID, Type: gosnmp.ObjectIdentifier, Value: trapOID},
+			},
+		}
+	}
+
+	type testCase struct {
+		name     string
+		packet   *gosnmp.SnmpPacket
+		mibs     *mib.MIB
+		expected map[string]any
+	}
+
+	testCases := []testCase{
+		{
+			name:   "V2",
+			packet: v2(".1.3.6.1.6.3.1.1.5.3"),
+			mibs:   mibs,
+			expected: map[string]any{
+				attributeSNMPTrapOID:     "1.3.6.1.6.3.1.1.5.3",
+				attributeSNMPTrapOIDName: "IF-MIB::linkDown",
+			},
+		},
+		{
+			name: "V1",
+			packet: &gosnmp.SnmpPacket{
+				Version:  gosnmp.Version1,
+				PDUType:  gosnmp.Trap,
+				SnmpTrap: gosnmp.SnmpTrap{Enterprise: ".1.3.6.1.4.1.32473.1.1", GenericTrap: genericTrapEnterpriseSpecific, SpecificTrap: 1},
+			},
+			mibs: mibs,
+			expected: map[string]any{
+				attributeSNMPTrapOID:     "1.3.6.1.4.1.32473.1.1.0.1",
+				attributeSNMPTrapOIDName: "EXAMPLE-TRAP-MIB::exampleFanFailure",
+			},
+		},
+		{
+			name:     "WithoutMIBs",
+			packet:   v2(".1.3.6.1.6.3.1.1.5.3"),
+			expected: map[string]any{attributeSNMPTrapOID: "1.3.6.1.6.3.1.1.5.3"},
+		},
+		{
+			name:     "Unresolved",
+			packet:   v2(".3.1"),
+			mibs:     mibs,
+			expected: map[string]any{attributeSNMPTrapOID: "3.1"},
+		},
+		{
+			name:     "NoTrapOID",
+			packet:   &gosnmp.SnmpPacket{Version: gosnmp.Version2c, PDUType: gosnmp.SNMPv2Trap},
+			mibs:     mibs,
+			expected: map[string]any{},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			attributes := pcommon.NewMap()
+			putTrapOID(attributes, test.packet, test.mibs)
+			require.Equal(t, test.expected, attributes.AsRaw())
+		})
+	}
+}
+
+func TestAnnotateVarbinds(t *testing.T) {
+	mibs, err := mib.Load(testMIBPath)
+	require.NoError(t, err)
+
+	// The columns of exampleAlarmTable, indexed by an IPv4 address and an alarm number
+	const alarmEntry = ".1.3.6.1.4.1.32473.3.1.1.1"
+	const alarmIndex = ".1.4.192.0.2.1.7"
+
+	type testCase struct {
+		name            string
+		variable        gosnmp.SnmpPDU
+		withoutMIBs     bool
+		expectedName    string
+		expectedDisplay any
+	}
+
+	testCases := []testCase{
+		{
+			name:            "TruthValue",
+			variable:        gosnmp.SnmpPDU{Name: alarmEntry + ".4" + alarmIndex, Type: gosnmp.Integer, Value: 1},
+			expectedName:    "EXAMPLE-ALARM-MIB::exampleAlarmActive.1.4.192.0.2.1.7",
+			expectedDisplay: "true",
+		},
+		{
+			name:            "RowStatus",
+			variable:        gosnmp.SnmpPDU{Name: alarmEntry + ".6" + alarmIndex, Type: gosnmp.Integer, Value: 6},
+			expectedName:    "EXAMPLE-ALARM-MIB::exampleAlarmRowStatus.1.4.192.0.2.1.7",
+			expectedDisplay: "destroy",
+		},
+		{
+			name:            "InetAddressType",
+			variable:        gosnmp.SnmpPDU{Name: alarmEntry + ".1" + alarmIndex, Type: gosnmp.Integer, Value: 2},
+			expectedName:    "EXAMPLE-ALARM-MIB::exampleAlarmAddressType.1.4.192.0.2.1.7",
+			expectedDisplay: "ipv6",
+		},
+		{
+			name:            "UnknownEnumerationValue",
+			variable:        gosnmp.SnmpPDU{Name: alarmEntry + ".4" + alarmIndex, Type: gosnmp.Integer, Value: 3},
+			expectedName:    "EXAMPLE-ALARM-MIB::exampleAlarmActive.1.4.192.0.2.1.7",
+			expectedDisplay: nil,
+		},
+		{
+			name:            "Bits",
+			variable:        gosnmp.SnmpPDU{Name: alarmEntry + ".5" + alarmIndex, Type: gosnmp.OctetString, Value: []byte{0xa0, 0x60}},
+			expectedName:    "EXAMPLE-ALARM-MIB::exampleAlarmFlags.1.4.192.0.2.1.7",
+			expectedDisplay: "critical cleared shelved 10",
+		},
+		{
+			name:            "SMIv1Enumeration",
+			variable:        gosnmp.SnmpPDU{Name: ".1.3.6.1.4.1.32473.2.1.1.2.3", Type: gosnmp.Integer, Value: -1},
+			expectedName:    "EXAMPLE-TRAP-MIB::exampleFanStatus.3",
+			expectedDisplay: "absent",
+		},
+		{
+			name:            "ObjectIdentifier",
+			variable:        gosnmp.SnmpPDU{Name: oidSnmpTrapOID, Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.6.3.1.1.5.3"},
+			expectedName:    "SNMPv2-MIB::snmpTrapOID.0",
+			expectedDisplay: "IF-MIB::linkDown",
+		},
+		{
+			name:            "WithoutSyntax",
+			variable:        gosnmp.SnmpPDU{Name: oidIfIndex + ".3", Type: gosnmp.Integer, Value: 3},
+			expectedName:    "IF-MIB::ifIndex.3",
+			expectedDisplay: nil,
+		},
+		{
+			name:            "Unresolved",
+			variable:        gosnmp.SnmpPDU{Name: ".3.1", Type: gosnmp.Integer, Value: 1},
+			expectedName:    "",
+			expectedDisplay: nil,
+		},
+		{
+			name:            "DisplayHint",
+			variable:        gosnmp.SnmpPDU{Name: ".1.3.6.1.2.1.2.2.1.6.3", Type: gosnmp.OctetString, Value: []byte{0x00, 0x1a, 0x2b, 0x3c, 0x4d, 0x5e}},
+			expectedName:    "IF-MIB::ifPhysAddress.3",
+			expectedDisplay: "00:1a:2b:3c:4d:5e",
+		},
+		{
+			name:            "DisplayString",
+			variable:        gosnmp.SnmpPDU{Name: ".1.3.6.1.2.1.2.2.1.2.3", Type: gosnmp.OctetString, Value: []byte("eth0")},
+			expectedName:    "IF-MIB::ifDescr.3",
+			expectedDisplay: "eth0",
+		},
+		{
+			name:            "OctetStringWithoutHint",
+			variable:        gosnmp.SnmpPDU{Name: alarmEntry + ".2" + alarmIndex, Type: gosnmp.OctetString, Value: []byte{192, 0, 2, 1}},
+			expectedName:    "EXAMPLE-ALARM-MIB::exampleAlarmAddress.1.4.192.0.2.1.7",
+			expectedDisplay: "C0 00 02 01",
+		},
+		{
+			name:            "BinaryWithoutMIBs",
+			variable:        gosnmp.SnmpPDU{Name: ".1.3.6.1.2.1.2.2.1.6.3", Type: gosnmp.OctetString, Value: []byte{0x00, 0x1a, 0x2b}},
+			withoutMIBs:     true,
+			expectedDisplay: "00 1A 2B",
+		},
+		{
+			name:            "TextWithoutMIBs",
+			variable:        gosnmp.SnmpPDU{Name: ".1.3.6.1.2.1.2.2.1.2.3", Type: gosnmp.OctetString, Value: []byte("eth0")},
+			withoutMIBs:     true,
+			expectedDisplay: "eth0",
+		},
+		{
+			name:            "ObjectIdentifierWithoutMIBs",
+			variable:        gosnmp.SnmpPDU{Name: oidSnmpTrapOID, Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.6.3.1.1.5.3"},
+			withoutMIBs:     true,
+			expectedDisplay: nil,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			packet := &gosnmp.SnmpPacket{Version: gosnmp.Version2c, PDUType: gosnmp.SNMPv2Trap, Variables: []gosnmp.SnmpPDU{test.variable}}
+			logRecord := plog.NewLogRecord()
+			require.NoError(t, logRecord.Body().SetEmptyMap().FromRaw(map[string]any{
+				bodyVarbinds: []any{map[string]any{bodyVarbindOID: test.variable.Name}},
+			}))
+			if test.withoutMIBs {
+				annotateVarbinds(logRecord, packet, nil)
+			} else {
+				annotateVarbinds(logRecord, packet, mibs)
+			}
+
+			varbind := logRecord.Body().Map().AsRaw()[bodyVarbinds].([]any)[0].(map[string]any)
+			if test.expectedName == "" {
+				require.NotContains(t, varbind, bodyVarbindOIDName)
+			} else {
+				require.Equal(t, test.expectedName, varbind[bodyVarbindOIDName])
+			}
+			require.Equal(t, test.expectedDisplay, varbind[bodyVarbindValueDisplay])
+		})
+	}
+}
+
+func TestAnnotateVarbindsIndex(t *testing.T) {
+	mibs, err := mib.LoadStandard(testMIBPath)
+	require.NoError(t, err)
+
+	type testCase struct {
+		name               string
+		variables          []gosnmp.SnmpPDU
+		expectedIndex      []any
+		expectedAttributes map[string]any
+	}
+
+	testCases := []testCase{
+		{
+			name: "Integer",
+			variables: []gosnmp.SnmpPDU{
+				{Name: oidIfIndex + ".12", Type: gosnmp.Integer, Value: 12},
+				{Name: ".1.3.6.1.2.1.2.2.1.2.12", Type: gosnmp.OctetString, Value: []byte("eth0")},
+			},
+			expectedIndex: []any{map[string]any{"ifIndex": int64(12)}, map[string]any{"ifIndex": int64(12)}},
+			expectedAttributes: map[string]any{
+				attributePrefixIndex + "ifIndex": int64(12),
+			},
+		},
+		{
+			name: "IPAddress",
+			variables: []gosnmp.SnmpPDU{
+				{Name: ".1.3.6.1.4.1.32473.3.1.5.1.3.3.10.0.0.1", Type: gosnmp.OctetString, Value: []byte{0x00, 0x1a, 0x2b, 0x3c, 0x4d, 0x5e}},
+			},
+			expectedIndex: []any{map[string]any{"exampleNeighborIfIndex": int64(3), "exampleNeighborAddress": "10.0.0.1"}},
+			expectedAttributes: map[string]any{
+				attributePrefixIndex + "exampleNeighborIfIndex": int64(3),
+				attributePrefixIndex + "exampleNeighborAddress": "10.0.0.1",
+			},
+		},
+		{
+			name: "EnumerationAndInetAddress",
+			variables: []gosnmp.SnmpPDU{
+				{Name: ".1.3.6.1.4.1.32473.3.1.1.1.4.1.4.192.0.2.1.7", Type: gosnmp.Integer, Value: 1},
+			},
+			expectedIndex: []any{map[string]any{"exampleAlarmAddressType": "ipv4", "exampleAlarmAddress": "192.0.2.1", "exampleAlarmId": int64(7)}},
+			expectedAttributes: map[string]any{
+				attributePrefixIndex + "exampleAlarmAddressType": "ipv4",
+				attributePrefixIndex + "exampleAlarmAddress":     "192.0.2.1",
+				attributePrefixIndex + "exampleAlarmId":          int64(7),
+			},
+		},
+		{
+			name: "DisplayHintAndImpliedString",
+			variables: []gosnmp.SnmpPDU{
+				{Name: ".1.3.6.1.4.1.32473.3.1.2.1.3.0.26.43.60.77.94.101.116.104.48", Type: gosnmp.Integer, Value: 1},
+			},
+			expectedIndex: []any{map[string]any{"exampleProfilePort": "00:1a:2b:3c:4d:5e", "exampleProfileName": "eth0"}},
+			expectedAttributes: map[string]any{
+				attributePrefixIndex + "exampleProfilePort": "00:1a:2b:3c:4d:5e",
+				attributePrefixIndex + "exampleProfileName": "eth0",
+			},
+		},
+		{
+			name: "ObjectIdentifier",
+			variables: []gosnmp.SnmpPDU{
+				{Name: ".1.3.6.1.4.1.32473.3.1.4.1.3.8.1.3.6.1.2.1.25.2.1", Type: gosnmp.Integer, Value: 40},
+			},
+			expectedIndex: []any{map[string]any{"exampleSensorType": "HOST-RESOURCES-MIB::hrStorage", "exampleSensorIndex": int64(1)}},
+			expectedAttributes: map[string]any{
+				attributePrefixIndex + "exampleSensorType":  "HOST-RESOURCES-MIB::hrStorage",
+				attributePrefixIndex + "exampleSensorIndex": int64(1),
+			},
+		},
+		{
+			name: "FirstVarbindWins",
+			variables: []gosnmp.SnmpPDU{
+				{Name: oidIfIndex + ".3", Type: gosnmp.Integer, Value: 3},
+				{Name: oidIfIndex + ".4", Type: gosnmp.Integer, Value: 4},
+			},
+			expectedIndex: []any{map[string]any{"ifIndex": int64(3)}, map[string]any{"ifIndex": int64(4)}},
+			expectedAttributes: map[string]any{
+				attributePrefixIndex + "ifIndex": int64(3),
+			},
+		},
+		{
+			name: "NotAColumn",
+			variables: []gosnmp.SnmpPDU{
+				{Name: oidSysUpTime, Type: gosnmp.TimeTicks, Value: uint32(100)},
+				{Name: ".1.3.6.1.2.1.2.2.1.2.3.1", Type: gosnmp.OctetString, Value: []byte("eth0")},
+			},
+			expectedIndex:      []any{nil, nil},
+			expectedAttributes: map[string]any{},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			packet := &gosnmp.SnmpPacket{Version: gosnmp.Version2c, PDUType: gosnmp.SNMPv2Trap, Variables: test.variables}
+			logRecord := plog.NewLogRecord()
+			var varbinds []any
+			for _, variable := range test.variables {
+				varbinds = append(varbinds, map[string]any{bodyVarbindOID: variable.Name})
+			}
+			require.NoError(t, logRecord.Body().SetEmptyMap().FromRaw(map[string]any{bodyVarbinds: varbinds}))
+			annotateVarbinds(logRecord, packet, mibs)
+
+			var index []any
+			for _, varbind := range logRecord.Body().Map().AsRaw()[bodyVarbinds].([]any) {
+				index = append(index, varbind.(map[string]any)[bodyVarbindIndex])
+			}
+			require.Equal(t, test.expectedIndex, index)
+			require.Equal(t, test.expectedAttributes, logRecord.Attributes().AsRaw())
+		})
+	}
+}
+
+func TestLoadMIBs(t *testing.T) {
+	// The standard MIBs are loaded by default
+	cfg := createDefaultConfig().(*Config)
+	mibs := newMIBLoader(cfg, zap.NewNop()).load()
+	require.NotNil(t, mibs)
+	require.Equal(t, "IF-MIB::ifIndex.1", mibs.Name(".1.3.6.1.2.1.2.2.1.1.1"))
+	cfg.StandardMIBs = false
+	require.Nil(t, newMIBLoader(cfg, zap.NewNop()).load())
+
+	// A broken directory doesn't prevent the others from being used
+	cfg.MIBPaths = []string{filepath.Join("internal", "mib", "testdata", "broken"), testMIBPath}
+	mibs = newMIBLoader(cfg, zap.NewNop()).load()
+	require.NotNil(t, mibs)
+	require.Equal(t, "IF-MIB::ifIndex.1", mibs.Name(".1.3.6.1.2.1.2.2.1.1.1"))
+
+	// A compiled MIB is loaded instead of the modules
+	compiled := filepath.Join(t.TempDir(), "mibs.compiled")
+	var buf bytes.Buffer
+	require.NoError(t, mibs.Encode(&buf))
+	require.NoError(t, os.WriteFile(compiled, buf.Bytes(), 0o600))
+	cfg = createDefaultConfig().(*Config)
+	cfg.CompiledMIB = compiled
+	mibs = newMIBLoader(cfg, zap.NewNop()).load()
+	require.NotNil(t, mibs)
+	require.Equal(t, "IF-MIB::ifIndex.1", mibs.Name(".1.3.6.1.2.1.2.2.1.1.1"))
+
+	cfg.CompiledMIB = filepath.Join(testMIBPath, "IF-MIB")
+	require.Nil(t, newMIBLoader(cfg, zap.NewNop()).load())
+}
+
+// copyMIBs copies MIB modules of the test directories to a directory
+func copyMIBs(t *testing.T, dir string, files ...string) {
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, filepath.Base(file)), data, 0o600))
+	}
+}
+
+func TestMIBLoaderReload(t *testing.T) {
+	dir := t.TempDir()
+	brokenMIB := filepath.Join("internal", "mib", "testdata", "broken", "BROKEN-MIB")
+	copyMIBs(t, dir, filepath.Join(testMIBPath, "SNMPv2-MIB"), brokenMIB)
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.MIBPaths = []string{dir}
+	loader := newMIBLoader(cfg, zap.NewNop())
+	mibs := loader.load()
+	require.Equal(t, "SNMPv2-MIB::sysUpTime.0", mibs.Name(".1.3.6.1.2.1.1.3.0"))
+	require.False(t, loader.changed())
+
+	// A new module is picked up, even though another module was broken from the start
+	copyMIBs(t, dir, filepath.Join(testMIBPath, "IF-MIB"))
+	require.True(t, loader.changed())
+	mibs, err := loader.reload()
+	require.NoError(t, err)
+	require.Equal(t, "IF-MIB::linkDown", mibs.Name(".1.3.6.1.6.3.1.1.5.3"))
+	require.False(t, loader.changed())
+
+	// A module which breaks the MIBs is refused, and isn't loaded again until it changes
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "NEW-MIB"), []byte("NEW-MIB DEFINITIONS ::= BEGIN\nnew OBJECT IDENTIFIER ::= {\n"), 0o600))
+	require.True(t, loader.changed())
+	_, err = loader.reload()
+	require.ErrorContains(t, err, filepath.Join(dir, "NEW-MIB")+":3: unexpected end of file")
+	require.NotContains(t, err.Error(), "BROKEN-MIB")
+	require.False(t, loader.changed())
+
+	require.NoError(t, os.Remove(filepath.Join(dir, "NEW-MIB")))
+	require.True(t, loader.changed())
+	_, err = loader.reload()
+	require.NoError(t, err)
+
+	// A compiled MIB which can't be read is refused
+	cfg = createDefaultConfig().(*Config)
+	cfg.CompiledMIB = filepath.Join(dir, "mibs.compiled")
+	loader = newMIBLoader(cfg, zap.NewNop())
+	require.Nil(t, loader.load())
+	require.NoError(t, os.WriteFile(cfg.CompiledMIB, []byte("garbage"), 0o600))
+	require.True(t, loader.changed())
+	_, err = loader.reload()
+	require.ErrorContains(t, err, "not a compiled MIB file")
+
+	var buf bytes.Buffer
+	require.NoError(t, mibs.Encode(&buf))
+	require.NoError(t, os.WriteFile(cfg.CompiledMIB, buf.Bytes(), 0o600))
+	require.True(t, loader.changed())
+	mibs, err = loader.reload()
+	require.NoError(t, err)
+	require.Equal(t, "IF-MIB::linkDown", mibs.Name(".1.3.6.1.6.3.1.1.5.3"))
+}
+
+func TestReceiverReloadsMIBs(t *testing.T) {
+	dir := t.TempDir()
+	copyMIBs(t, dir, filepath.Join(testMIBPath, "SNMPv2-MIB"))
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.ListenAddress = "udp://127.0.0.1:0"
+	cfg.MIBPaths = []string{dir}
+	cfg.MIBReloadInterval = 10 * time.Millisecond
+	// The standard IF-MIB would name linkDown before the one copied to the directory
+	cfg.StandardMIBs = false
+
+	reader := sdkmetric.NewManualReader()
+	settings := receivertest.NewNopCreateSettings()
+	settings.MeterProvider = sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	rcvr, err := newSnmptrapReceiver(settings, cfg, consumertest.NewNop())
+	require.NoError(t, err)
+	require.NoError(t, rcvr.Start(context.Background(), componenttest.NewNopHost()))
+	defer func() {
+		require.NoError(t, rcvr.Shutdown(context.Background()))
+	}()
+	require.Equal(t, "SNMPv2-MIB::snmpTraps.3", rcvr.mibs.Load().Name(".1.3.6.1.6.3.1.1.5.3"))
+
+	copyMIBs(t, dir, filepath.Join(testMIBPath, "IF-MIB"))
+	require.Eventually(t, func() bool {
+		return rcvr.mibs.Load().Name(".1.3.6.1.6.3.1.1.5.3") == "IF-MIB::linkDown"
+	}, 5*time.Second, 10*time.Millisecond)
+	require.EqualValues(t, 1, counterValueWith(t, reader, metricMIBReloads, attributeResult, reloadSuccess))
+
+	// The MIBs in use are kept when a broken module is added
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "NEW-MIB"), []byte("NEW-MIB DEFINITIONS ::= BEGIN\n"), 0o600))
+	require.Eventually(t, func() bool {
+		return counterValueWith(t, reader, metricMIBReloads, attributeResult, reloadFailure) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, "IF-MIB::linkDown", rcvr.mibs.Load().Name(".1.3.6.1.6.3.1.1.5.3"))
+}
+
+func TestReceiveTrapWithMIBs(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.ListenAddress = "udp://127.0.0.1:0"
+	cfg.MIBPaths = []string{testMIBPath}
+
+	sink := new(consumertest.LogsSink)
+	rcvr, err := newSnmptrapReceiver(receivertest.NewNopCreateSettings(), cfg, sink)
+	require.NoError(t, err)
+	require.NoError(t, rcvr.Start(context.Background(), componenttest.NewNopHost()))
+	defer func() {
+		require.NoError(t, rcvr.Shutdown(context.Background()))
+	}()
+
+	_, port := splitAddr(rcvr.listeners[0].localAddr())
+	client := &gosnmp.GoSNMP{
+		Target:    "127.0.0.1",
+		Port:      uint16(port),
+		Transport: "udp",
+		Community: "public",
+		Version:   gosnmp.Version2c,
+		Timeout:   time.Second,
+		MaxOids:   gosnmp.MaxOids,
+	}
+	require.NoError(t, client.Connect())
+	defer client.Conn.Close()
+
+	_, err = client.SendTrap(gosnmp.SnmpTrap{
+		Variables: []gosnmp.SnmpPDU{
+			{Name: oidSysUpTime, Type: gosnmp.TimeTicks, Value: uint32(100)},
+			{Name: oidSnmpTrapOID, Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.6.3.1.1.5.3"},
+			{Name: oidIfIndex + ".3", Type: gosnmp.Integer, Value: 3},
+			{Name: ".1.3.6.1.4.1.32473.2.1.1.2.3", Type: gosnmp.Integer, Value: 2},
+			{Name: ".3.1", Type: gosnmp.Integer, Value: 1},
+		},
+	})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return sink.LogRecordCount() == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	logRecord := sink.AllLogs()[0].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+	requireAttribute(t, logRecord, attributeSNMPTrapOID, "1.3.6.1.6.3.1.1.5.3")
+	requireAttribute(t, logRecord, attributeSNMPTrapOIDName, "IF-MIB::linkDown")
+	requireAttribute(t, logRecord, attributeNotificationName, "linkDown")
+	requireAttribute(t, logRecord, attributeNotificationModule, "IF-MIB")
+
+	var names, displays []any
+	for _, varbind := range logRecord.Body().Map().AsRaw()[bodyVarbinds].([]any) {
+		names = append(names, varbind.(map[string]any)[bodyVarbindOIDName])
+		displays = append(displays, varbind.(map[string]any)[bodyVarbindValueDisplay])
+	}
+	require.Equal(t, []any{
+		"SNMPv2-MIB::sysUpTime.0",
+		"SNMPv2-MIB::snmpTrapOID.0",
+		"IF-MIB::ifIndex.3",
+		"EXAMPLE-TRAP-MIB::exampleFanStatus.3",
+		nil,
+	}, names)
+	require.Equal(t, []any{nil, "IF-MIB::linkDown", nil, "failed", nil}, displays)
+
+	// The names don't get in the way of decoding the PDU
+	packet, err := DecodeLogRecord(logRecord)
+	require.NoError(t, err)
+	require.Len(t, packet.Variables, 5)
+}
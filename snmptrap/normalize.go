@@ -0,0 +1,112 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package snmptrapreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver"
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gosnmp/gosnmp"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+// OIDs added to v1 traps when they are translated to SNMPv2-Trap-PDUs
+const (
+	oidSysUpTime          = ".1.3.6.1.2.1.1.3.0"
+	oidSnmpTrapOID        = ".1.3.6.1.6.3.1.1.4.1.0"
+	oidSnmpTrapEnterprise = ".1.3.6.1.6.3.1.1.4.3.0"
+	oidSnmpTrapAddress    = ".1.3.6.1.6.3.18.1.3.0"
+	oidSnmpTrapCommunity  = ".1.3.6.1.6.3.18.1.4.0"
+
+	// oidSnmpTraps is the parent of the snmpTrapOID values of the generic traps
+	oidSnmpTraps = ".1.3.6.1.6.3.1.1.5"
+
+	genericTrapEnterpriseSpecific = 6
+)
+
+// Attributes holding the fields of a v1 trap that was normalized. Its agent-addr is recorded
+// with the other addresses of the agent, as attributeSNMPv1AgentAddress.
+const (
+	attributeSNMPv1Enterprise   = "snmp.v1.enterprise"
+	attributeSNMPv1GenericTrap  = "snmp.v1.generic_trap"
+	attributeSNMPv1SpecificTrap = "snmp.v1.specific_trap"
+	attributeSNMPv1Timestamp    = "snmp.v1.timestamp"
+)
+
+// normalizeV1Trap translates a v1 Trap-PDU into the SNMPv2-Trap-PDU a proxy would forward,
+// following RFC 3584 section 3.1. The varbinds start with sysUpTime.0 and snmpTrapOID.0,
+// and snmpTrapAddress.0, snmpTrapCommunity.0 and snmpTrapEnterprise.0 are appended unless
+// the trap already has them.
+func normalizeV1Trap(packet *gosnmp.SnmpPacket) *gosnmp.SnmpPacket {
+	enterprise := v1Enterprise(packet)
+
+	variables := make([]gosnmp.SnmpPDU, 0, len(packet.Variables)+5)
+	variables = append(variables,
+		gosnmp.SnmpPDU{Name: oidSysUpTime, Type: gosnmp.TimeTicks, Value: uint32(packet.Timestamp)},
+		gosnmp.SnmpPDU{Name: oidSnmpTrapOID, Type: gosnmp.ObjectIdentifier, Value: v1TrapOID(packet)},
+	)
+	variables = append(variables, packet.Variables...)
+
+	present := map[string]bool{}
+	for _, variable := range packet.Variables {
+		present[variable.Name] = true
+	}
+	if !present[oidSnmpTrapAddress] {
+		variables = append(variables, gosnmp.SnmpPDU{Name: oidSnmpTrapAddress, Type: gosnmp.IPAddress, Value: packet.AgentAddress})
+	}
+	if !present[oidSnmpTrapCommunity] {
+		variables = append(variables, gosnmp.SnmpPDU{Name: oidSnmpTrapCommunity, Type: gosnmp.OctetString, Value: []byte(packet.Community)})
+	}
+	if !present[oidSnmpTrapEnterprise] {
+		variables = append(variables, gosnmp.SnmpPDU{Name: oidSnmpTrapEnterprise, Type: gosnmp.ObjectIdentifier, Value: enterprise})
+	}
+
+	return &gosnmp.SnmpPacket{
+		Version:   gosnmp.Version2c,
+		Community: packet.Community,
+		PDUType:   gosnmp.SNMPv2Trap,
+		Variables: variables,
+	}
+}
+
+// trapOID returns the snmpTrapOID of a notification, with a leading dot. For v1 traps it
+// is computed as described by RFC 3584 section 3.1, for other versions it is taken from
+// the snmpTrapOID.0 varbind. An empty string is returned when there is none.
+func trapOID(packet *gosnmp.SnmpPacket) string {
+	if packet.Version == gosnmp.Version1 {
+		return v1TrapOID(packet)
+	}
+	for _, variable := range packet.Variables {
+		if variable.Name == oidSnmpTrapOID {
+			oid, _ := variable.Value.(string)
+			return oid
+		}
+	}
+	return ""
+}
+
+// v1TrapOID computes the snmpTrapOID of a v1 trap, RFC 3584 section 3.1 (2)
+func v1TrapOID(packet *gosnmp.SnmpPacket) string {
+	if packet.GenericTrap >= 0 && packet.GenericTrap < genericTrapEnterpriseSpecific {
+		return oidSnmpTraps + "." + strconv.Itoa(packet.GenericTrap+1)
+	}
+	return v1Enterprise(packet) + ".0." + strconv.Itoa(packet.SpecificTrap)
+}
+
+// v1Enterprise returns the enterprise of a v1 trap with a leading dot
+func v1Enterprise(packet *gosnmp.SnmpPacket) string {
+	if packet.Enterprise != "" && !strings.HasPrefix(packet.Enterprise, ".") {
+		return "." + packet.Enterprise
+	}
+	return packet.Enterprise
+}
+
+// putV1TrapAttributes records the fields of a v1 trap that normalizeV1Trap replaced, but for its
+// agent-addr which putAgentAddresses records
+func putV1TrapAttributes(attributes pcommon.Map, packet *gosnmp.SnmpPacket) {
+	attributes.PutStr(attributeSNMPv1Enterprise, strings.TrimPrefix(packet.Enterprise, "."))
+	attributes.PutInt(attributeSNMPv1GenericTrap, int64(packet.GenericTrap))
+	attributes.PutInt(attributeSNMPv1SpecificTrap, int64(packet.SpecificTrap))
+	attributes.PutInt(attributeSNMPv1Timestamp, int64(packet.Timestamp))
+}
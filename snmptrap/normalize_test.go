@@ -0,0 +1,192 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package snmptrapreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver"
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+)
+
+func TestNormalizeV1Trap(t *testing.T) {
+	ifIndex := gosnmp.SnmpPDU{Name: ".1.3.6.1.2.1.2.2.1.1.3", Type: gosnmp.Integer, Value: 3}
+
+	type testCase struct {
+		name              string
+		packet            *gosnmp.SnmpPacket
+		expectedVariables []gosnmp.SnmpPDU
+	}
+
+	testCases := []testCase{
+		{
+			name: "GenericTrap",
+			packet: &gosnmp.SnmpPacket{
+				Version:   gosnmp.Version1,
+				Community: "public",
+				PDUType:   gosnmp.Trap,
+				Variables: []gosnmp.SnmpPDU{ifIndex},
+				SnmpTrap: gosnmp.SnmpTrap{
+					Enterprise:   ".1.3.6.1.4.1.8072.3.2.10",
+					AgentAddress: "192.0.2.1",
+					GenericTrap:  2,
+					Timestamp:    4200,
+				},
+			},
+			expectedVariables: []gosnmp.SnmpPDU{
+				{Name: oidSysUpTime, Type: gosnmp.TimeTicks, Value: uint32(4200)},
+				{Name: oidSnmpTrapOID, Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.6.3.1.1.5.3"},
+				ifIndex,
+				{Name: oidSnmpTrapAddress, Type: gosnmp.IPAddress, Value: "192.0.2.1"},
+				{Name: oidSnmpTrapCommunity, Type: gosnmp.OctetString, Value: []byte("public")},
+				{Name: oidSnmpTrapEnterprise, Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.4.1.8072.3.2.10"},
+			},
+		},
+		{
+			name: "EnterpriseSpecificTrap",
+			packet: &gosnmp.SnmpPacket{
+				Version:   gosnmp.Version1,
+				Community: "public",
+				PDUType:   gosnmp.Trap,
+				SnmpTrap: gosnmp.SnmpTrap{
+					Enterprise:   "1.3.6.1.4.1.8072",
+					AgentAddress: "192.0.2.1",
+					GenericTrap:  genericTrapEnterpriseSpecific,
+					SpecificTrap: 17,
+					Timestamp:    100,
+				},
+			},
+			expectedVariables: []gosnmp.SnmpPDU{
+				{Name: oidSysUpTime, Type: gosnmp.TimeTicks, Value: uint32(100)},
+				{Name: oidSnmpTrapOID, Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.4.1.8072.0.17"},
+				{Name: oidSnmpTrapAddress, Type: gosnmp.IPAddress, Value: "192.0.2.1"},
+				{Name: oidSnmpTrapCommunity, Type: gosnmp.OctetString, Value: []byte("public")},
+				{Name: oidSnmpTrapEnterprise, Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.4.1.8072"},
+			},
+		},
+		{
+			// A trap that went through a proxy already has some of the varbinds
+			name: "ProxiedTrap",
+			packet: &gosnmp.SnmpPacket{
+				Version:   gosnmp.Version1,
+				Community: "public",
+				PDUType:   gosnmp.Trap,
+				Variables: []gosnmp.SnmpPDU{
+					{Name: oidSnmpTrapAddress, Type: gosnmp.IPAddress, Value: "198.51.100.1"},
+				},
+				SnmpTrap: gosnmp.SnmpTrap{
+					Enterprise:   ".1.3.6.1.4.1.8072",
+					AgentAddress: "192.0.2.1",
+					GenericTrap:  0,
+				},
+			},
+			expectedVariables: []gosnmp.SnmpPDU{
+				{Name: oidSysUpTime, Type: gosnmp.TimeTicks, Value: uint32(0)},
+				{Name: oidSnmpTrapOID, Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.6.3.1.1.5.1"},
+				{Name: oidSnmpTrapAddress, Type: gosnmp.IPAddress, Value: "198.51.100.1"},
+				{Name: oidSnmpTrapCommunity, Type: gosnmp.OctetString, Value: []byte("public")},
+				{Name: oidSnmpTrapEnterprise, Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.4.1.8072"},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			normalized := normalizeV1Trap(test.packet)
+			require.Equal(t, gosnmp.Version2c, normalized.Version)
+			require.Equal(t, gosnmp.SNMPv2Trap, normalized.PDUType)
+			require.Equal(t, test.packet.Community, normalized.Community)
+			require.Equal(t, test.expectedVariables, normalized.Variables)
+
+			// The normalized trap is a valid SNMPv2-Trap-PDU
+			_, err := normalized.MarshalMsg()
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestReceiveNormalizedV1Trap(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.ListenAddress = "udp://127.0.0.1:0"
+	cfg.Version = "v1"
+	cfg.NormalizeV1Traps = true
+
+	sink := new(consumertest.LogsSink)
+	rcvr, err := newSnmptrapReceiver(receivertest.NewNopCreateSettings(), cfg, sink)
+	require.NoError(t, err)
+	require.NoError(t, rcvr.Start(context.Background(), componenttest.NewNopHost()))
+	defer func() {
+		require.NoError(t, rcvr.Shutdown(context.Background()))
+	}()
+
+	_, port := splitAddr(rcvr.listeners[0].localAddr())
+	client := &gosnmp.GoSNMP{
+		Target:    "127.0.0.1",
+		Port:      uint16(port),
+		Transport: "udp",
+		Community: "public",
+		Version:   gosnmp.Version1,
+		Timeout:   time.Second,
+		MaxOids:   gosnmp.MaxOids,
+	}
+	require.NoError(t, client.Connect())
+	defer client.Conn.Close()
+
+	trap := gosnmp.SnmpTrap{
+		Variables:    []gosnmp.SnmpPDU{{Name: ".1.3.6.1.2.1.2.2.1.1.3", Type: gosnmp.Integer, Value: 3}},
+		Enterprise:   ".1.3.6.1.4.1.8072.3.2.10",
+		AgentAddress: "192.0.2.1",
+		GenericTrap:  3,
+		Timestamp:    300,
+	}
+	_, err = client.SendTrap(trap)
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return sink.LogRecordCount() == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	logRecord := sink.AllLogs()[0].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+	body := logRecord.Body().Map().AsRaw()
+	require.Equal(t, "v2c", body[bodyVersion])
+	require.Equal(t, "SNMPv2Trap", body[bodyPDUType])
+	require.NotContains(t, body, bodyEnterprise)
+
+	varbinds := body[bodyVarbinds].([]any)
+	require.Len(t, varbinds, 6)
+	require.Equal(t, "1.3.6.1.6.3.1.1.5.4", varbinds[1].(map[string]any)[bodyVarbindValue])
+
+	requireAttribute(t, logRecord, attributeSNMPv1Enterprise, "1.3.6.1.4.1.8072.3.2.10")
+	requireAttribute(t, logRecord, attributeSNMPv1AgentAddress, "192.0.2.1")
+	for key, expected := range map[string]int64{
+		attributeSNMPv1GenericTrap:  3,
+		attributeSNMPv1SpecificTrap: 0,
+		attributeSNMPv1Timestamp:    300,
+	} {
+		value, ok := logRecord.Attributes().Get(key)
+		require.True(t, ok, "missing attribute %s", key)
+		require.Equal(t, expected, value.Int())
+	}
+
+	// The record still decodes to the trap the agent sent
+	decoded, err := DecodeLogRecord(logRecord)
+	require.NoError(t, err)
+	require.Equal(t, &gosnmp.SnmpPacket{
+		Version:   gosnmp.Version1,
+		Community: "public",
+		PDUType:   gosnmp.Trap,
+		SnmpTrap: gosnmp.SnmpTrap{
+			Enterprise:   trap.Enterprise,
+			AgentAddress: trap.AgentAddress,
+			GenericTrap:  trap.GenericTrap,
+			Timestamp:    trap.Timestamp,
+		},
+		Variables: trap.Variables,
+	}, decoded)
+}
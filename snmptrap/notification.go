@@ -0,0 +1,121 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package snmptrapreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver"
+
+import (
+	"strings"
+
+	"github.com/gosnmp/gosnmp"
+	"go.opentelemetry.io/collector/pdata/plog"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver/internal/mib"
+)
+
+// Attributes describing the NOTIFICATION-TYPE or TRAP-TYPE of a trap, and how well the trap
+// matches it
+const (
+	attributeNotificationName              = "snmp.notification.name"
+	attributeNotificationModule            = "snmp.notification.module"
+	attributeNotificationDescription       = "snmp.notification.description"
+	attributeNotificationMissingObjects    = "snmp.notification.missing_objects"
+	attributeNotificationUnexpectedObjects = "snmp.notification.unexpected_objects"
+	attributeNotificationMismatchedObjects = "snmp.notification.mismatched_objects"
+)
+
+const (
+	// bodyVarbindObject holds the object of the notification a varbind of the body is at the
+	// position of, in the form MODULE::object
+	bodyVarbindObject = "object"
+	// bodyVarbindObjectMismatch is set to true on varbinds whose OID is not that of the object
+	// at their position
+	bodyVarbindObjectMismatch = "object_mismatch"
+)
+
+// frameworkVarbinds are added to notifications by the SNMPv2 framework and by proxies, RFC 3416
+// and RFC 3584, so they are expected whatever the notification declares
+var frameworkVarbinds = map[string]bool{
+	oidSysUpTime:          true,
+	oidSnmpTrapOID:        true,
+	oidSnmpTrapEnterprise: true,
+	oidSnmpTrapAddress:    true,
+	oidSnmpTrapCommunity:  true,
+}
+
+// putNotification describes a trap with the notification the MIBs define for its snmpTrapOID.
+// The varbinds other than those of the SNMPv2 framework are mapped by position to the objects
+// the notification declares, and their OIDs only confirm the mapping. Varbinds carrying another
+// object than the one at their position, declared objects left without a varbind and extra
+// varbinds are listed, since vendors often send traps which don't follow their own MIBs.
+func putNotification(logRecord plog.LogRecord, packet *gosnmp.SnmpPacket, mibs *mib.MIB) {
+	if mibs == nil {
+		return
+	}
+	notification := mibs.Notification(trapOID(packet))
+	if notification == nil {
+		return
+	}
+
+	attributes := logRecord.Attributes()
+	attributes.PutStr(attributeNotificationName, notification.Name)
+	attributes.PutStr(attributeNotificationModule, notification.Module)
+	if notification.Description != "" {
+		attributes.PutStr(attributeNotificationDescription, notification.Description)
+	}
+
+	varbinds, ok := logRecord.Body().Map().Get(bodyVarbinds)
+	annotate := ok && varbinds.Slice().Len() == len(packet.Variables)
+
+	position := 0
+	var mismatched, unexpected []any
+	for i, variable := range packet.Variables {
+		if frameworkVarbinds[variable.Name] {
+			continue
+		}
+		if position >= len(notification.Objects) {
+			unexpected = append(unexpected, varbindName(mibs, variable.Name))
+			continue
+		}
+		declared := notification.Objects[position]
+		position++
+		matches := isInstanceOf(variable.Name, declared.OID)
+		if !matches {
+			mismatched = append(mismatched, declared.Module+"::"+declared.Name)
+		}
+		if annotate {
+			varbind := varbinds.Slice().At(i).Map()
+			varbind.PutStr(bodyVarbindObject, declared.Module+"::"+declared.Name)
+			if !matches {
+				varbind.PutBool(bodyVarbindObjectMismatch, true)
+			}
+		}
+	}
+
+	var missing []any
+	for _, object := range notification.Objects[position:] {
+		missing = append(missing, object.Module+"::"+object.Name)
+	}
+	if len(missing) > 0 {
+		_ = attributes.PutEmptySlice(attributeNotificationMissingObjects).FromRaw(missing)
+	}
+	if len(unexpected) > 0 {
+		_ = attributes.PutEmptySlice(attributeNotificationUnexpectedObjects).FromRaw(unexpected)
+	}
+	if len(mismatched) > 0 {
+		_ = attributes.PutEmptySlice(attributeNotificationMismatchedObjects).FromRaw(mismatched)
+	}
+}
+
+// isInstanceOf tells whether an OID is that of an object or of one of its instances
+func isInstanceOf(oid string, object string) bool {
+	oid = strings.TrimPrefix(oid, ".")
+	return oid == object || strings.HasPrefix(oid, object+".")
+}
+
+// varbindName names the OID of a varbind with the MIBs, or returns it without its leading dot
+func varbindName(mibs *mib.MIB, oid string) string {
+	if name := mibs.Name(oid); name != "" {
+		return name
+	}
+	return strings.TrimPrefix(oid, ".")
+}
@@ -0,0 +1,174 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package snmptrapreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver"
+
+import (
+	"testing"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/plog"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver/internal/mib"
+)
+
+func TestPutNotification(t *testing.T) {
+	mibs, err := mib.Load(testMIBPath)
+	require.NoError(t, err)
+
+	linkDown := func(variables ...gosnmp.SnmpPDU) *gosnmp.SnmpPacket {
+		return &gosnmp.SnmpPacket{
+			Version: gosnmp.Version2c,
+			PDUType: gosnmp.SNMPv2Trap,
+			Variables: append([]gosnmp.SnmpPDU{
+				{Name: oidSysUpTime, Type: gosnmp.TimeTicks, Value: uint32(100)},
+				{Name: oidSnmpTrapOID, Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.6.3.1.1.5.3"},
+			}, variables...),
+		}
+	}
+	ifIndex := gosnmp.SnmpPDU{Name: oidIfIndex + ".3", Type: gosnmp.Integer, Value: 3}
+	ifAdminStatus := gosnmp.SnmpPDU{Name: oidIfAdminStatus + ".3", Type: gosnmp.Integer, Value: 1}
+	ifOperStatus := gosnmp.SnmpPDU{Name: oidIfOperStatus + ".3", Type: gosnmp.Integer, Value: 2}
+	linkDownDescription := mibs.Notification(".1.3.6.1.6.3.1.1.5.3").Description
+
+	type testCase struct {
+		name               string
+		packet             *gosnmp.SnmpPacket
+		mibs               *mib.MIB
+		expectedAttributes map[string]any
+		expectedObjects    []any
+		// expectedMismatches are the positions of the varbinds flagged with object_mismatch
+		expectedMismatches []int
+	}
+
+	testCases := []testCase{
+		{
+			name:   "Complete",
+			packet: linkDown(ifIndex, ifAdminStatus, ifOperStatus),
+			mibs:   mibs,
+			expectedAttributes: map[string]any{
+				attributeNotificationName:        "linkDown",
+				attributeNotificationModule:      "IF-MIB",
+				attributeNotificationDescription: linkDownDescription,
+			},
+			expectedObjects: []any{nil, nil, "IF-MIB::ifIndex", "IF-MIB::ifAdminStatus", "IF-MIB::ifOperStatus"},
+		},
+		{
+			name:   "Missing",
+			packet: linkDown(ifIndex),
+			mibs:   mibs,
+			expectedAttributes: map[string]any{
+				attributeNotificationName:           "linkDown",
+				attributeNotificationModule:         "IF-MIB",
+				attributeNotificationDescription:    linkDownDescription,
+				attributeNotificationMissingObjects: []any{"IF-MIB::ifAdminStatus", "IF-MIB::ifOperStatus"},
+			},
+			expectedObjects: []any{nil, nil, "IF-MIB::ifIndex"},
+		},
+		{
+			name: "Unexpected",
+			packet: linkDown(
+				ifIndex,
+				ifAdminStatus,
+				ifOperStatus,
+				gosnmp.SnmpPDU{Name: ".1.3.6.1.2.1.2.2.1.2.3", Type: gosnmp.OctetString, Value: []byte("eth0")},
+				gosnmp.SnmpPDU{Name: ".3.1", Type: gosnmp.Integer, Value: 1},
+				gosnmp.SnmpPDU{Name: oidSnmpTrapAddress, Type: gosnmp.IPAddress, Value: "192.0.2.1"},
+			),
+			mibs: mibs,
+			expectedAttributes: map[string]any{
+				attributeNotificationName:              "linkDown",
+				attributeNotificationModule:            "IF-MIB",
+				attributeNotificationDescription:       linkDownDescription,
+				attributeNotificationUnexpectedObjects: []any{"IF-MIB::ifDescr.3", "3.1"},
+			},
+			expectedObjects: []any{nil, nil, "IF-MIB::ifIndex", "IF-MIB::ifAdminStatus", "IF-MIB::ifOperStatus", nil, nil, nil},
+		},
+		{
+			// A vendor sending another OID at a position still gets it mapped, and flagged
+			name: "Mismatched",
+			packet: linkDown(
+				ifIndex,
+				gosnmp.SnmpPDU{Name: ".1.3.6.1.4.1.32473.7.3", Type: gosnmp.Integer, Value: 1},
+				ifOperStatus,
+			),
+			mibs: mibs,
+			expectedAttributes: map[string]any{
+				attributeNotificationName:              "linkDown",
+				attributeNotificationModule:            "IF-MIB",
+				attributeNotificationDescription:       linkDownDescription,
+				attributeNotificationMismatchedObjects: []any{"IF-MIB::ifAdminStatus"},
+			},
+			expectedObjects:    []any{nil, nil, "IF-MIB::ifIndex", "IF-MIB::ifAdminStatus", "IF-MIB::ifOperStatus"},
+			expectedMismatches: []int{3},
+		},
+		{
+			name: "ObjectWithoutInstance",
+			packet: linkDown(
+				gosnmp.SnmpPDU{Name: oidIfIndex, Type: gosnmp.Integer, Value: 3},
+				gosnmp.SnmpPDU{Name: oidIfAdminStatus, Type: gosnmp.Integer, Value: 1},
+				gosnmp.SnmpPDU{Name: oidIfOperStatus, Type: gosnmp.Integer, Value: 2},
+			),
+			mibs: mibs,
+			expectedAttributes: map[string]any{
+				attributeNotificationName:        "linkDown",
+				attributeNotificationModule:      "IF-MIB",
+				attributeNotificationDescription: linkDownDescription,
+			},
+			expectedObjects: []any{nil, nil, "IF-MIB::ifIndex", "IF-MIB::ifAdminStatus", "IF-MIB::ifOperStatus"},
+		},
+		{
+			name: "V1",
+			packet: &gosnmp.SnmpPacket{
+				Version:  gosnmp.Version1,
+				PDUType:  gosnmp.Trap,
+				SnmpTrap: gosnmp.SnmpTrap{Enterprise: ".1.3.6.1.4.1.32473.1.1", GenericTrap: genericTrapEnterpriseSpecific, SpecificTrap: 1},
+				Variables: []gosnmp.SnmpPDU{
+					{Name: ".1.3.6.1.4.1.32473.2.1.1.1.2", Type: gosnmp.Integer, Value: 2},
+				},
+			},
+			mibs: mibs,
+			expectedAttributes: map[string]any{
+				attributeNotificationName:           "exampleFanFailure",
+				attributeNotificationModule:         "EXAMPLE-TRAP-MIB",
+				attributeNotificationDescription:    "A fan failed.",
+				attributeNotificationMissingObjects: []any{"EXAMPLE-TRAP-MIB::exampleFanStatus"},
+			},
+			expectedObjects: []any{"EXAMPLE-TRAP-MIB::exampleFanIndex"},
+		},
+		{
+			name:               "UnknownNotification",
+			packet:             &gosnmp.SnmpPacket{Version: gosnmp.Version2c, PDUType: gosnmp.SNMPv2Trap, Variables: []gosnmp.SnmpPDU{{Name: oidSnmpTrapOID, Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.4.1.32473.99"}}},
+			mibs:               mibs,
+			expectedAttributes: map[string]any{},
+			expectedObjects:    []any{nil},
+		},
+		{
+			name:               "WithoutMIBs",
+			packet:             linkDown(ifIndex),
+			expectedAttributes: map[string]any{},
+			expectedObjects:    []any{nil, nil, nil},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			logRecord := plog.NewLogRecord()
+			EncodeLogRecord(test.packet, logRecord)
+			putNotification(logRecord, test.packet, test.mibs)
+			require.Equal(t, test.expectedAttributes, logRecord.Attributes().AsRaw())
+
+			var objects []any
+			var mismatches []int
+			for i, varbind := range logRecord.Body().Map().AsRaw()[bodyVarbinds].([]any) {
+				objects = append(objects, varbind.(map[string]any)[bodyVarbindObject])
+				if varbind.(map[string]any)[bodyVarbindObjectMismatch] == true {
+					mismatches = append(mismatches, i)
+				}
+			}
+			require.Equal(t, test.expectedObjects, objects)
+			require.Equal(t, test.expectedMismatches, mismatches)
+		})
+	}
+}
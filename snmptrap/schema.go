@@ -0,0 +1,468 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package snmptrapreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver"
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/gosnmp/gosnmp"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+// SchemaVersion is the version of the log record representation of an SNMP PDU
+// produced by EncodeLogRecord. It is bumped whenever a change to the schema
+// would prevent an older DecodeLogRecord from recreating the original packet.
+//
+// Schema version 2 stores the PDU as a map in the log record body:
+//
+//	schema_version          int     2, or 1 for records without normalized_v1_trap
+//	version                 string  "v1", "v2c" or "v3"
+//	pdu_type                string  "Trap", "SNMPv2Trap" or "InformRequest"
+//	community               string  v1 and v2c only
+//	request_id              int     v2c and v3 only
+//	error_status            int     only when non-zero
+//	error_index             int     only when non-zero
+//	enterprise              string  v1 only, dotted OID without a leading dot
+//	agent_address           string  v1 only
+//	generic_trap            int     v1 only
+//	specific_trap           int     v1 only
+//	timestamp               int     v1 only, sysUpTime in hundredths of a second
+//	msg_id                  int     v3 only
+//	msg_flags               int     v3 only
+//	security_model          int     v3 only
+//	user                    string  v3 only
+//	authoritative_engine_id bytes   v3 only
+//	engine_boots            int     v3 only
+//	engine_time             int     v3 only
+//	context_engine_id       bytes   v3 only
+//	context_name            string  v3 only
+//	varbinds                slice   one map per varbind, in PDU order
+//	normalized_v1_trap      map     only for v1 traps emitted as SNMPv2-Trap-PDUs, see below
+//
+// Each varbind map has an "oid" (dotted, without a leading dot), a "type"
+// (see varbindTypeNames) and a "value" whose representation depends on the type:
+//
+//	Integer                             int
+//	Counter32, Gauge32, TimeTicks       int
+//	Uinteger32                          int
+//	Counter64                           int, or a decimal string above math.MaxInt64
+//	OctetString, Opaque                 bytes, exactly as received
+//	IpAddress                           string
+//	ObjectIdentifier                    string, dotted without a leading dot
+//	OpaqueFloat, OpaqueDouble           double
+//	Null, NoSuchObject, NoSuchInstance,
+//	EndOfMibView                        empty
+//
+// USM secrets (passphrases and localized keys) are never written to the log record.
+//
+// A v1 trap normalized as described by RFC 3584 section 3.1 is written as the v2c
+// SNMPv2-Trap-PDU it was translated to, with a normalized_v1_trap map holding what that
+// translation lost: the enterprise, agent_address, generic_trap, specific_trap and timestamp
+// of the Trap-PDU, and added_varbinds, the number of varbinds appended after its own. The
+// translation always starts the varbinds with sysUpTime.0 and snmpTrapOID.0. DecodeLogRecord
+// returns the original v1 trap from these.
+//
+// When a message template renders the body of a log record, the map is moved to the
+// snmp.pdu attribute, where DecodeLogRecord finds it.
+const SchemaVersion = 2
+
+// attributeSNMPPDU holds the map of the PDU when the body of the log record is a rendered message
+const attributeSNMPPDU = "snmp.pdu"
+
+// Log record body keys
+const (
+	bodySchemaVersion         = "schema_version"
+	bodyPDUType               = "pdu_type"
+	bodyVersion               = "version"
+	bodyCommunity             = "community"
+	bodyUser                  = "user"
+	bodyRequestID             = "request_id"
+	bodyErrorStatus           = "error_status"
+	bodyErrorIndex            = "error_index"
+	bodyEnterprise            = "enterprise"
+	bodyAgentAddress          = "agent_address"
+	bodyGenericTrap           = "generic_trap"
+	bodySpecificTrap          = "specific_trap"
+	bodyTimestamp             = "timestamp"
+	bodyMsgID                 = "msg_id"
+	bodyMsgFlags              = "msg_flags"
+	bodySecurityModel         = "security_model"
+	bodyAuthoritativeEngineID = "authoritative_engine_id"
+	bodyEngineBoots           = "engine_boots"
+	bodyEngineTime            = "engine_time"
+	bodyContextEngineID       = "context_engine_id"
+	bodyContextName           = "context_name"
+	bodyVarbinds              = "varbinds"
+	bodyVarbindOID            = "oid"
+	bodyVarbindType           = "type"
+	bodyVarbindValue          = "value"
+	bodyNormalizedV1Trap      = "normalized_v1_trap"
+	bodyAddedVarbinds         = "added_varbinds"
+)
+
+// varbindTypeNames are the names used for the "type" of a varbind. They follow
+// the SMI spelling of each type rather than the gosnmp constant names.
+var varbindTypeNames = map[gosnmp.Asn1BER]string{
+	gosnmp.UnknownType:       "Unknown",
+	gosnmp.Boolean:           "Boolean",
+	gosnmp.Integer:           "Integer",
+	gosnmp.BitString:         "BitString",
+	gosnmp.OctetString:       "OctetString",
+	gosnmp.Null:              "Null",
+	gosnmp.ObjectIdentifier:  "ObjectIdentifier",
+	gosnmp.ObjectDescription: "ObjectDescription",
+	gosnmp.IPAddress:         "IpAddress",
+	gosnmp.Counter32:         "Counter32",
+	gosnmp.Gauge32:           "Gauge32",
+	gosnmp.TimeTicks:         "TimeTicks",
+	gosnmp.Opaque:            "Opaque",
+	gosnmp.NsapAddress:       "NsapAddress",
+	gosnmp.Counter64:         "Counter64",
+	gosnmp.Uinteger32:        "Uinteger32",
+	gosnmp.OpaqueFloat:       "OpaqueFloat",
+	gosnmp.OpaqueDouble:      "OpaqueDouble",
+	gosnmp.NoSuchObject:      "NoSuchObject",
+	gosnmp.NoSuchInstance:    "NoSuchInstance",
+	gosnmp.EndOfMibView:      "EndOfMibView",
+}
+
+// varbindTypes is the reverse lookup of varbindTypeNames
+var varbindTypes = func() map[string]gosnmp.Asn1BER {
+	types := make(map[string]gosnmp.Asn1BER, len(varbindTypeNames))
+	for asn1Type, name := range varbindTypeNames {
+		types[name] = asn1Type
+	}
+	return types
+}()
+
+// pduTypes are the PDU types which can be represented by the schema
+var pduTypes = map[string]gosnmp.PDUType{
+	gosnmp.Trap.String():          gosnmp.Trap,
+	gosnmp.SNMPv2Trap.String():    gosnmp.SNMPv2Trap,
+	gosnmp.InformRequest.String(): gosnmp.InformRequest,
+}
+
+var (
+	errNoPDU          = errors.New("log record does not contain an SNMP PDU")
+	errSchemaVersion  = errors.New("unsupported schema_version")
+	errBadPDUType     = errors.New("pdu_type must be either Trap, SNMPv2Trap, or InformRequest")
+	errBadVarbindType = errors.New("unknown varbind type")
+
+	errBadNormalizedV1Trap = errors.New("normalized_v1_trap doesn't match the varbinds")
+)
+
+// EncodeLogRecord writes the schema representation of the packet into the
+// body of the log record. See SchemaVersion for a description of the schema.
+func EncodeLogRecord(packet *gosnmp.SnmpPacket, logRecord plog.LogRecord) {
+	encodePacket(packet, logRecord.Body().SetEmptyMap())
+}
+
+// DecodeLogRecord recreates the SNMP packet which was written to the log record
+// by EncodeLogRecord. The returned packet can be marshalled and sent on as is,
+// once any USM secrets it requires have been added.
+func DecodeLogRecord(logRecord plog.LogRecord) (*gosnmp.SnmpPacket, error) {
+	if logRecord.Body().Type() == pcommon.ValueTypeMap {
+		return decodePacket(logRecord.Body().Map())
+	}
+	if pdu, ok := logRecord.Attributes().Get(attributeSNMPPDU); ok && pdu.Type() == pcommon.ValueTypeMap {
+		return decodePacket(pdu.Map())
+	}
+	return nil, errNoPDU
+}
+
+// encodeNormalizedV1Trap records in the body of a log record, which holds the normalized
+// form of a v1 trap, what is needed to recreate the original trap
+func encodeNormalizedV1Trap(original *gosnmp.SnmpPacket, normalized *gosnmp.SnmpPacket, body pcommon.Map) {
+	trap := body.PutEmptyMap(bodyNormalizedV1Trap)
+	trap.PutStr(bodyEnterprise, strings.TrimPrefix(original.Enterprise, "."))
+	trap.PutStr(bodyAgentAddress, original.AgentAddress)
+	trap.PutInt(bodyGenericTrap, int64(original.GenericTrap))
+	trap.PutInt(bodySpecificTrap, int64(original.SpecificTrap))
+	trap.PutInt(bodyTimestamp, int64(original.Timestamp))
+	// sysUpTime.0 and snmpTrapOID.0 come before the varbinds of the original trap
+	trap.PutInt(bodyAddedVarbinds, int64(len(normalized.Variables)-len(original.Variables)-2))
+}
+
+// encodePacket writes the schema representation of the packet into the given map
+func encodePacket(packet *gosnmp.SnmpPacket, body pcommon.Map) {
+	body.PutInt(bodySchemaVersion, SchemaVersion)
+	body.PutStr(bodyVersion, versionToString(packet.Version))
+	body.PutStr(bodyPDUType, packet.PDUType.String())
+
+	switch packet.Version {
+	case gosnmp.Version3:
+		body.PutInt(bodyMsgID, int64(packet.MsgID))
+		body.PutInt(bodyMsgFlags, int64(packet.MsgFlags))
+		body.PutInt(bodySecurityModel, int64(packet.SecurityModel))
+		if usm, ok := packet.SecurityParameters.(*gosnmp.UsmSecurityParameters); ok {
+			body.PutStr(bodyUser, usm.UserName)
+			body.PutEmptyBytes(bodyAuthoritativeEngineID).FromRaw([]byte(usm.AuthoritativeEngineID))
+			body.PutInt(bodyEngineBoots, int64(usm.AuthoritativeEngineBoots))
+			body.PutInt(bodyEngineTime, int64(usm.AuthoritativeEngineTime))
+		}
+		body.PutEmptyBytes(bodyContextEngineID).FromRaw([]byte(packet.ContextEngineID))
+		body.PutStr(bodyContextName, packet.ContextName)
+	default:
+		body.PutStr(bodyCommunity, packet.Community)
+	}
+
+	if packet.PDUType == gosnmp.Trap {
+		// SNMPv1 traps have their own header instead of a request-id
+		body.PutStr(bodyEnterprise, strings.TrimPrefix(packet.Enterprise, "."))
+		body.PutStr(bodyAgentAddress, packet.AgentAddress)
+		body.PutInt(bodyGenericTrap, int64(packet.GenericTrap))
+		body.PutInt(bodySpecificTrap, int64(packet.SpecificTrap))
+		body.PutInt(bodyTimestamp, int64(packet.Timestamp))
+	} else {
+		body.PutInt(bodyRequestID, int64(packet.RequestID))
+		if packet.Error != gosnmp.NoError {
+			body.PutInt(bodyErrorStatus, int64(packet.Error))
+		}
+		if packet.ErrorIndex != 0 {
+			body.PutInt(bodyErrorIndex, int64(packet.ErrorIndex))
+		}
+	}
+
+	varbinds := body.PutEmptySlice(bodyVarbinds)
+	varbinds.EnsureCapacity(len(packet.Variables))
+	for _, variable := range packet.Variables {
+		encodeVarbind(variable, varbinds.AppendEmpty().SetEmptyMap())
+	}
+}
+
+// encodeVarbind writes the schema representation of a varbind into the given map
+func encodeVarbind(variable gosnmp.SnmpPDU, varbind pcommon.Map) {
+	varbind.PutStr(bodyVarbindOID, strings.TrimPrefix(variable.Name, "."))
+	typeName, ok := varbindTypeNames[variable.Type]
+	if !ok {
+		typeName = varbindTypeNames[gosnmp.UnknownType]
+	}
+	varbind.PutStr(bodyVarbindType, typeName)
+
+	dest := varbind.PutEmpty(bodyVarbindValue)
+	switch value := variable.Value.(type) {
+	case nil:
+		// Null, NoSuchObject, NoSuchInstance and EndOfMibView carry no value
+	case []byte:
+		dest.SetEmptyBytes().FromRaw(value)
+	case string:
+		if variable.Type == gosnmp.ObjectIdentifier {
+			value = strings.TrimPrefix(value, ".")
+		}
+		dest.SetStr(value)
+	case int:
+		dest.SetInt(int64(value))
+	case uint:
+		dest.SetInt(int64(value))
+	case uint32:
+		dest.SetInt(int64(value))
+	case uint64:
+		if value > math.MaxInt64 {
+			dest.SetStr(strconv.FormatUint(value, 10))
+			return
+		}
+		dest.SetInt(int64(value))
+	case float32:
+		dest.SetDouble(float64(value))
+	case float64:
+		dest.SetDouble(value)
+	default:
+		dest.SetStr(toString(value))
+	}
+}
+
+// decodePacket recreates an SNMP packet from its schema representation
+func decodePacket(body pcommon.Map) (*gosnmp.SnmpPacket, error) {
+	schemaVersion, ok := body.Get(bodySchemaVersion)
+	if !ok {
+		return nil, errNoPDU
+	}
+	if schemaVersion.Int() < 1 || schemaVersion.Int() > SchemaVersion {
+		return nil, fmt.Errorf("%w: %d", errSchemaVersion, schemaVersion.Int())
+	}
+
+	packet := &gosnmp.SnmpPacket{}
+
+	switch getStr(body, bodyVersion) {
+	case "v1":
+		packet.Version = gosnmp.Version1
+	case "v2c":
+		packet.Version = gosnmp.Version2c
+	case "v3":
+		packet.Version = gosnmp.Version3
+	default:
+		return nil, errBadVersion
+	}
+
+	if packet.PDUType, ok = pduTypes[getStr(body, bodyPDUType)]; !ok {
+		return nil, errBadPDUType
+	}
+
+	if packet.Version == gosnmp.Version3 {
+		packet.MsgID = uint32(getInt(body, bodyMsgID))
+		packet.MsgFlags = gosnmp.SnmpV3MsgFlags(getInt(body, bodyMsgFlags))
+		packet.SecurityModel = gosnmp.SnmpV3SecurityModel(getInt(body, bodySecurityModel))
+		if packet.SecurityModel == gosnmp.UserSecurityModel {
+			packet.SecurityParameters = &gosnmp.UsmSecurityParameters{
+				UserName:                 getStr(body, bodyUser),
+				AuthoritativeEngineID:    string(getBytes(body, bodyAuthoritativeEngineID)),
+				AuthoritativeEngineBoots: uint32(getInt(body, bodyEngineBoots)),
+				AuthoritativeEngineTime:  uint32(getInt(body, bodyEngineTime)),
+			}
+		}
+		packet.ContextEngineID = string(getBytes(body, bodyContextEngineID))
+		packet.ContextName = getStr(body, bodyContextName)
+	} else {
+		packet.Community = getStr(body, bodyCommunity)
+	}
+
+	if packet.PDUType == gosnmp.Trap {
+		packet.Enterprise = addOIDPrefix(getStr(body, bodyEnterprise))
+		packet.AgentAddress = getStr(body, bodyAgentAddress)
+		packet.GenericTrap = int(getInt(body, bodyGenericTrap))
+		packet.SpecificTrap = int(getInt(body, bodySpecificTrap))
+		packet.Timestamp = uint(getInt(body, bodyTimestamp))
+	} else {
+		packet.RequestID = uint32(getInt(body, bodyRequestID))
+		packet.Error = gosnmp.SNMPError(getInt(body, bodyErrorStatus))
+		packet.ErrorIndex = uint8(getInt(body, bodyErrorIndex))
+	}
+
+	varbinds, ok := body.Get(bodyVarbinds)
+	if !ok || varbinds.Type() != pcommon.ValueTypeSlice {
+		return packet, nil
+	}
+	packet.Variables = make([]gosnmp.SnmpPDU, 0, varbinds.Slice().Len())
+	for i := 0; i < varbinds.Slice().Len(); i++ {
+		varbind := varbinds.Slice().At(i)
+		if varbind.Type() != pcommon.ValueTypeMap {
+			return nil, fmt.Errorf("varbind %d is not a map", i)
+		}
+		variable, err := decodeVarbind(varbind.Map())
+		if err != nil {
+			return nil, fmt.Errorf("varbind %d: %w", i, err)
+		}
+		packet.Variables = append(packet.Variables, variable)
+	}
+
+	if trap, ok := body.Get(bodyNormalizedV1Trap); ok && trap.Type() == pcommon.ValueTypeMap {
+		return decodeNormalizedV1Trap(packet, trap.Map())
+	}
+	return packet, nil
+}
+
+// decodeNormalizedV1Trap recreates the v1 trap a packet was normalized from
+func decodeNormalizedV1Trap(normalized *gosnmp.SnmpPacket, trap pcommon.Map) (*gosnmp.SnmpPacket, error) {
+	added := int(getInt(trap, bodyAddedVarbinds))
+	if added < 0 || len(normalized.Variables) < added+2 {
+		return nil, errBadNormalizedV1Trap
+	}
+	return &gosnmp.SnmpPacket{
+		Version:   gosnmp.Version1,
+		Community: normalized.Community,
+		PDUType:   gosnmp.Trap,
+		SnmpTrap: gosnmp.SnmpTrap{
+			Enterprise:   addOIDPrefix(getStr(trap, bodyEnterprise)),
+			AgentAddress: getStr(trap, bodyAgentAddress),
+			GenericTrap:  int(getInt(trap, bodyGenericTrap)),
+			SpecificTrap: int(getInt(trap, bodySpecificTrap)),
+			Timestamp:    uint(getInt(trap, bodyTimestamp)),
+		},
+		Variables: normalized.Variables[2 : len(normalized.Variables)-added],
+	}, nil
+}
+
+// decodeVarbind recreates a varbind from its schema representation. Values are
+// returned with the same Go types that gosnmp uses when unmarshalling a packet.
+func decodeVarbind(varbind pcommon.Map) (gosnmp.SnmpPDU, error) {
+	typeName := getStr(varbind, bodyVarbindType)
+	asn1Type, ok := varbindTypes[typeName]
+	if !ok {
+		return gosnmp.SnmpPDU{}, fmt.Errorf("%w '%s'", errBadVarbindType, typeName)
+	}
+
+	variable := gosnmp.SnmpPDU{
+		Name: addOIDPrefix(getStr(varbind, bodyVarbindOID)),
+		Type: asn1Type,
+	}
+
+	value, ok := varbind.Get(bodyVarbindValue)
+	if !ok || value.Type() == pcommon.ValueTypeEmpty {
+		return variable, nil
+	}
+
+	switch asn1Type { // nolint:exhaustive
+	case gosnmp.Integer:
+		variable.Value = int(value.Int())
+	case gosnmp.Counter32, gosnmp.Gauge32:
+		variable.Value = uint(value.Int())
+	case gosnmp.TimeTicks, gosnmp.Uinteger32:
+		variable.Value = uint32(value.Int())
+	case gosnmp.Counter64:
+		if value.Type() == pcommon.ValueTypeStr {
+			counter, err := strconv.ParseUint(value.Str(), 10, 64)
+			if err != nil {
+				return variable, fmt.Errorf("invalid Counter64 value: %w", err)
+			}
+			variable.Value = counter
+		} else {
+			variable.Value = uint64(value.Int())
+		}
+	case gosnmp.ObjectIdentifier:
+		variable.Value = addOIDPrefix(value.Str())
+	case gosnmp.OpaqueFloat:
+		variable.Value = float32(value.Double())
+	case gosnmp.OpaqueDouble:
+		variable.Value = value.Double()
+	default:
+		switch value.Type() { // nolint:exhaustive
+		case pcommon.ValueTypeBytes:
+			// Like gosnmp, never return a nil slice for a zero length string
+			variable.Value = append([]byte{}, value.Bytes().AsRaw()...)
+		case pcommon.ValueTypeStr:
+			variable.Value = value.Str()
+		default:
+			return variable, fmt.Errorf("unexpected %s value for %s varbind", value.Type(), typeName)
+		}
+	}
+
+	return variable, nil
+}
+
+// addOIDPrefix adds the leading dot used by gosnmp to a dotted OID
+func addOIDPrefix(oid string) string {
+	if oid == "" || strings.HasPrefix(oid, ".") {
+		return oid
+	}
+	return "." + oid
+}
+
+// getStr returns the string value of key, or "" if the key is not present
+func getStr(m pcommon.Map, key string) string {
+	if value, ok := m.Get(key); ok {
+		return value.Str()
+	}
+	return ""
+}
+
+// getInt returns the int value of key, or 0 if the key is not present
+func getInt(m pcommon.Map, key string) int64 {
+	if value, ok := m.Get(key); ok {
+		return value.Int()
+	}
+	return 0
+}
+
+// getBytes returns the bytes value of key, or nil if the key is not present
+func getBytes(m pcommon.Map, key string) []byte {
+	if value, ok := m.Get(key); ok && value.Type() == pcommon.ValueTypeBytes {
+		return value.Bytes().AsRaw()
+	}
+	return nil
+}
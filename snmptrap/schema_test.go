@@ -0,0 +1,315 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package snmptrapreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver"
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/quick"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+// randomPacket is a trap or inform PDU shaped the way gosnmp returns it from UnmarshalTrap
+type randomPacket struct {
+	*gosnmp.SnmpPacket
+}
+
+// Generate implements quick.Generator
+func (randomPacket) Generate(r *rand.Rand, size int) reflect.Value {
+	return reflect.ValueOf(randomPacket{generatePacket(r, size, false)})
+}
+
+// wirePacket is a randomPacket limited to what gosnmp is able to marshal
+type wirePacket struct {
+	*gosnmp.SnmpPacket
+}
+
+// Generate implements quick.Generator
+func (wirePacket) Generate(r *rand.Rand, size int) reflect.Value {
+	return reflect.ValueOf(wirePacket{generatePacket(r, size, true)})
+}
+
+func generatePacket(r *rand.Rand, size int, wire bool) *gosnmp.SnmpPacket {
+	versions := []gosnmp.SnmpVersion{gosnmp.Version1, gosnmp.Version2c, gosnmp.Version3}
+	packet := &gosnmp.SnmpPacket{
+		Version: versions[r.Intn(len(versions))],
+	}
+
+	switch packet.Version {
+	case gosnmp.Version1:
+		packet.PDUType = gosnmp.Trap
+		packet.Enterprise = "." + randomOID(r)
+		packet.AgentAddress = net.IPv4(byte(r.Intn(256)), byte(r.Intn(256)), byte(r.Intn(256)), byte(r.Intn(256))).String()
+		packet.GenericTrap = r.Intn(7)
+		packet.SpecificTrap = r.Intn(math.MaxInt32)
+		packet.Timestamp = uint(r.Uint32())
+	default:
+		packet.PDUType = []gosnmp.PDUType{gosnmp.SNMPv2Trap, gosnmp.InformRequest}[r.Intn(2)]
+		packet.RequestID = r.Uint32()
+	}
+
+	if packet.Version == gosnmp.Version3 {
+		packet.MsgID = r.Uint32()
+		packet.MsgFlags = gosnmp.Reportable
+		packet.SecurityModel = gosnmp.UserSecurityModel
+		packet.SecurityParameters = &gosnmp.UsmSecurityParameters{
+			UserName:                 randomString(r, 1+r.Intn(32)),
+			AuthoritativeEngineID:    string(randomBytes(r, 5+r.Intn(28))),
+			AuthoritativeEngineBoots: uint32(r.Int31()),
+			AuthoritativeEngineTime:  uint32(r.Int31()),
+		}
+		packet.ContextEngineID = string(randomBytes(r, r.Intn(32)))
+		packet.ContextName = randomString(r, r.Intn(32))
+	} else {
+		packet.Community = randomString(r, r.Intn(32))
+	}
+
+	count := 1 + r.Intn(1+size%16)
+	for i := 0; i < count; i++ {
+		packet.Variables = append(packet.Variables, randomVarbind(r, wire))
+	}
+
+	return packet
+}
+
+func randomVarbind(r *rand.Rand, wire bool) gosnmp.SnmpPDU {
+	types := []gosnmp.Asn1BER{
+		gosnmp.Integer, gosnmp.OctetString, gosnmp.Null, gosnmp.ObjectIdentifier, gosnmp.IPAddress,
+		gosnmp.Counter32, gosnmp.Gauge32, gosnmp.TimeTicks, gosnmp.Counter64, gosnmp.Uinteger32,
+		gosnmp.OpaqueFloat, gosnmp.OpaqueDouble, gosnmp.NoSuchObject, gosnmp.NoSuchInstance, gosnmp.EndOfMibView,
+	}
+	if !wire {
+		// gosnmp can decode but not encode raw Opaque values
+		types = append(types, gosnmp.Opaque)
+	}
+
+	variable := gosnmp.SnmpPDU{
+		Name: "." + randomOID(r),
+		Type: types[r.Intn(len(types))],
+	}
+
+	switch variable.Type { // nolint:exhaustive
+	case gosnmp.Integer:
+		variable.Value = int(r.Int31()) - r.Intn(math.MaxInt32)
+	case gosnmp.OctetString, gosnmp.Opaque:
+		variable.Value = randomBytes(r, r.Intn(64))
+	case gosnmp.ObjectIdentifier:
+		variable.Value = "." + randomOID(r)
+	case gosnmp.IPAddress:
+		variable.Value = net.IPv4(byte(r.Intn(256)), byte(r.Intn(256)), byte(r.Intn(256)), byte(r.Intn(256))).String()
+	case gosnmp.Counter32, gosnmp.Gauge32:
+		variable.Value = uint(r.Uint32())
+	case gosnmp.TimeTicks, gosnmp.Uinteger32:
+		variable.Value = r.Uint32()
+	case gosnmp.Counter64:
+		variable.Value = r.Uint64()
+	case gosnmp.OpaqueFloat:
+		variable.Value = r.Float32()
+	case gosnmp.OpaqueDouble:
+		variable.Value = r.NormFloat64()
+	}
+
+	return variable
+}
+
+func randomOID(r *rand.Rand) string {
+	arcs := []string{"1", "3", "6", "1"}
+	for i := 2 + r.Intn(12); i > 0; i-- {
+		arcs = append(arcs, fmt.Sprint(r.Uint32()>>uint(r.Intn(32))))
+	}
+	return strings.Join(arcs, ".")
+}
+
+func randomBytes(r *rand.Rand, n int) []byte {
+	b := make([]byte, n)
+	_, _ = r.Read(b)
+	return b
+}
+
+func randomString(r *rand.Rand, n int) string {
+	const letters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_"
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = letters[r.Intn(len(letters))]
+	}
+	return string(b)
+}
+
+// Encoding any packet and decoding it again must give back an identical packet
+func TestSchemaRoundTrip(t *testing.T) {
+	roundTrip := func(packet randomPacket) bool {
+		logRecord := plog.NewLogRecord()
+		EncodeLogRecord(packet.SnmpPacket, logRecord)
+
+		decoded, err := DecodeLogRecord(logRecord)
+		return err == nil && reflect.DeepEqual(packet.SnmpPacket, decoded)
+	}
+
+	require.NoError(t, quick.Check(roundTrip, &quick.Config{MaxCount: 2000}))
+}
+
+// A normalized v1 trap must decode back to the original trap rather than to its normalized form
+func TestSchemaNormalizedV1TrapRoundTrip(t *testing.T) {
+	roundTrip := func(packet randomPacket) bool {
+		if packet.Version != gosnmp.Version1 {
+			return true
+		}
+		normalized := normalizeV1Trap(packet.SnmpPacket)
+		logRecord := plog.NewLogRecord()
+		EncodeLogRecord(normalized, logRecord)
+		encodeNormalizedV1Trap(packet.SnmpPacket, normalized, logRecord.Body().Map())
+
+		decoded, err := DecodeLogRecord(logRecord)
+		return err == nil && reflect.DeepEqual(packet.SnmpPacket, decoded)
+	}
+
+	require.NoError(t, quick.Check(roundTrip, &quick.Config{MaxCount: 2000}))
+
+	// Varbinds of RFC 3584 the trap already carries are not added again
+	packet := &gosnmp.SnmpPacket{
+		Version:   gosnmp.Version1,
+		Community: "public",
+		PDUType:   gosnmp.Trap,
+		SnmpTrap: gosnmp.SnmpTrap{
+			Enterprise:   ".1.3.6.1.4.1.8072.3.2.10",
+			AgentAddress: "192.0.2.1",
+			GenericTrap:  6,
+			SpecificTrap: 2,
+			Timestamp:    300,
+		},
+		Variables: []gosnmp.SnmpPDU{
+			{Name: oidSnmpTrapAddress, Type: gosnmp.IPAddress, Value: "198.51.100.7"},
+			{Name: ".1.3.6.1.2.1.2.2.1.1.3", Type: gosnmp.Integer, Value: 3},
+		},
+	}
+	normalized := normalizeV1Trap(packet)
+	logRecord := plog.NewLogRecord()
+	EncodeLogRecord(normalized, logRecord)
+	encodeNormalizedV1Trap(packet, normalized, logRecord.Body().Map())
+	decoded, err := DecodeLogRecord(logRecord)
+	require.NoError(t, err)
+	require.Equal(t, packet, decoded)
+}
+
+// A packet received off the wire, encoded and decoded again must marshal back to the same bytes
+func TestSchemaWireRoundTrip(t *testing.T) {
+	roundTrip := func(packet wirePacket) bool {
+		sent, err := packet.MarshalMsg()
+		if err != nil {
+			t.Logf("marshalling generated packet: %v", err)
+			return false
+		}
+
+		unmarshaller := &gosnmp.GoSNMP{Version: packet.Version}
+		received, err := unmarshaller.UnmarshalTrap(sent, true)
+		if err != nil {
+			t.Logf("unmarshalling generated packet: %v", err)
+			return false
+		}
+
+		logRecord := plog.NewLogRecord()
+		EncodeLogRecord(received, logRecord)
+		decoded, err := DecodeLogRecord(logRecord)
+		if err != nil {
+			t.Logf("decoding log record: %v", err)
+			return false
+		}
+
+		resent, err := decoded.MarshalMsg()
+		if err != nil {
+			t.Logf("marshalling decoded packet: %v", err)
+			return false
+		}
+
+		return reflect.DeepEqual(sent, resent)
+	}
+
+	require.NoError(t, quick.Check(roundTrip, &quick.Config{MaxCount: 500}))
+}
+
+func TestDecodeLogRecordErrors(t *testing.T) {
+	type testCase struct {
+		name        string
+		body        map[string]any
+		expectedErr string
+	}
+
+	testCases := []testCase{
+		{
+			name:        "NotAPDU",
+			body:        map[string]any{"message": "hello"},
+			expectedErr: errNoPDU.Error(),
+		},
+		{
+			name:        "FutureSchemaVersion",
+			body:        map[string]any{bodySchemaVersion: SchemaVersion + 1},
+			expectedErr: errSchemaVersion.Error(),
+		},
+		{
+			name:        "BadVersion",
+			body:        map[string]any{bodySchemaVersion: SchemaVersion, bodyVersion: "v4"},
+			expectedErr: errBadVersion.Error(),
+		},
+		{
+			name:        "BadPDUType",
+			body:        map[string]any{bodySchemaVersion: SchemaVersion, bodyVersion: "v2c", bodyPDUType: "GetRequest"},
+			expectedErr: errBadPDUType.Error(),
+		},
+		{
+			name: "BadVarbindType",
+			body: map[string]any{
+				bodySchemaVersion: SchemaVersion,
+				bodyVersion:       "v2c",
+				bodyPDUType:       "SNMPv2Trap",
+				bodyVarbinds:      []any{map[string]any{bodyVarbindOID: "1.3.6.1", bodyVarbindType: "Float128"}},
+			},
+			expectedErr: errBadVarbindType.Error(),
+		},
+		{
+			name: "BadNormalizedV1Trap",
+			body: map[string]any{
+				bodySchemaVersion:    SchemaVersion,
+				bodyVersion:          "v2c",
+				bodyPDUType:          "SNMPv2Trap",
+				bodyVarbinds:         []any{map[string]any{bodyVarbindOID: "1.3.6.1.2.1.1.3.0", bodyVarbindType: "TimeTicks", bodyVarbindValue: 1}},
+				bodyNormalizedV1Trap: map[string]any{bodyAddedVarbinds: 3},
+			},
+			expectedErr: errBadNormalizedV1Trap.Error(),
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			logRecord := plog.NewLogRecord()
+			require.NoError(t, logRecord.Body().SetEmptyMap().FromRaw(test.body))
+
+			_, err := DecodeLogRecord(logRecord)
+			require.ErrorContains(t, err, test.expectedErr)
+		})
+	}
+
+	t.Run("SchemaVersion1", func(t *testing.T) {
+		logRecord := plog.NewLogRecord()
+		require.NoError(t, logRecord.Body().SetEmptyMap().FromRaw(map[string]any{bodySchemaVersion: 1, bodyVersion: "v2c", bodyPDUType: "SNMPv2Trap"}))
+
+		_, err := DecodeLogRecord(logRecord)
+		require.NoError(t, err)
+	})
+
+	t.Run("StringBody", func(t *testing.T) {
+		logRecord := plog.NewLogRecord()
+		logRecord.Body().SetStr("hello")
+
+		_, err := DecodeLogRecord(logRecord)
+		require.ErrorIs(t, err, errNoPDU)
+	})
+}
@@ -0,0 +1,337 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package snmptrapreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver"
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"net/netip"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gosnmp/gosnmp"
+	"go.opentelemetry.io/collector/pdata/plog"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver/internal/mib"
+)
+
+// Operators comparing the value of a varbind of a severity rule
+const (
+	operatorEquals      = "equals"
+	operatorNotEquals   = "not_equals"
+	operatorMatches     = "matches"
+	operatorGreaterThan = "greater_than"
+	operatorLessThan    = "less_than"
+)
+
+var (
+	errMsgInvalidRuleOID = `invalid %s '%s': must be a dotted OID`
+	errMsgInvalidGlob    = `invalid trap_oid_glob '%s': %w`
+	errMsgBadSeverity    = `invalid severity '%s': must be TRACE, DEBUG, INFO, WARN, ERROR or FATAL, optionally followed by 2, 3 or 4`
+	errMsgBadOperator    = `invalid operator '%s': must be either equals, not_equals, matches, greater_than, or less_than`
+	errMsgInvalidRegexp  = `invalid value '%s' for matches: %w`
+	errMsgNotANumber     = `invalid value '%s' for %s: must be an integer`
+
+	errSeverityRuleTrapOIDs = errors.New("only one of trap_oid, trap_oid_prefix and trap_oid_glob may be set")
+	errEmptySeverity        = errors.New("severity must be specified")
+	errEmptyVarbindOID      = errors.New("oid must be specified")
+)
+
+// defaultSeverityRules give the generic traps a severity. They are applied after the rules of
+// the configuration, which can override them.
+var defaultSeverityRules = []SeverityRuleConfig{
+	{TrapOID: oidSnmpTraps + ".1", Severity: "INFO"},  // coldStart
+	{TrapOID: oidSnmpTraps + ".2", Severity: "INFO"},  // warmStart
+	{TrapOID: oidSnmpTraps + ".3", Severity: "WARN"},  // linkDown
+	{TrapOID: oidSnmpTraps + ".4", Severity: "INFO"},  // linkUp
+	{TrapOID: oidSnmpTraps + ".5", Severity: "ERROR"}, // authenticationFailure
+	{TrapOID: oidSnmpTraps + ".6", Severity: "WARN"},  // egpNeighborLoss
+}
+
+// severityRule is a compiled entry of severity_rules
+type severityRule struct {
+	trapOID       string
+	trapOIDPrefix string
+	trapOIDGlob   string
+	sources       []netip.Prefix
+	communities   map[string]bool
+	varbinds      []varbindPredicate
+
+	severity     plog.SeverityNumber
+	severityText string
+}
+
+// varbindPredicate holds when a notification carries a varbind of an OID, or of an instance
+// of it, whose value satisfies the operator
+type varbindPredicate struct {
+	oid      string
+	operator string
+	value    string
+	pattern  *regexp.Regexp
+	number   *big.Int
+}
+
+// severityRules sets the severity of notifications with the first rule matching them
+type severityRules []*severityRule
+
+// newSeverityRules compiles the rules of the configuration, followed by the default ones
+func newSeverityRules(cfg *Config) (severityRules, error) {
+	var rules severityRules
+	for i, ruleCfg := range append(append([]SeverityRuleConfig{}, cfg.SeverityRules...), defaultSeverityRules...) {
+		rule, err := newSeverityRule(ruleCfg)
+		if err != nil {
+			return nil, fmt.Errorf("severity_rules[%d]: %w", i, err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// newSeverityRule compiles a rule, returning all of its problems at once
+func newSeverityRule(cfg SeverityRuleConfig) (*severityRule, error) {
+	var combinedErr error
+	rule := &severityRule{
+		trapOID:       strings.TrimPrefix(cfg.TrapOID, "."),
+		trapOIDPrefix: strings.TrimPrefix(cfg.TrapOIDPrefix, "."),
+		trapOIDGlob:   strings.TrimPrefix(cfg.TrapOIDGlob, "."),
+		severityText:  cfg.SeverityText,
+	}
+
+	set := 0
+	for _, value := range []string{cfg.TrapOID, cfg.TrapOIDPrefix, cfg.TrapOIDGlob} {
+		if value != "" {
+			set++
+		}
+	}
+	if set > 1 {
+		combinedErr = errors.Join(combinedErr, errSeverityRuleTrapOIDs)
+	}
+	if cfg.TrapOID != "" && !isDottedOID(rule.trapOID) {
+		combinedErr = errors.Join(combinedErr, fmt.Errorf(errMsgInvalidRuleOID, "trap_oid", cfg.TrapOID))
+	}
+	if cfg.TrapOIDPrefix != "" && !isDottedOID(rule.trapOIDPrefix) {
+		combinedErr = errors.Join(combinedErr, fmt.Errorf(errMsgInvalidRuleOID, "trap_oid_prefix", cfg.TrapOIDPrefix))
+	}
+	if cfg.TrapOIDGlob != "" {
+		if _, err := path.Match(rule.trapOIDGlob, ""); err != nil {
+			combinedErr = errors.Join(combinedErr, fmt.Errorf(errMsgInvalidGlob, cfg.TrapOIDGlob, err))
+		}
+	}
+
+	for _, source := range cfg.Sources {
+		prefix, err := parsePrefix(source)
+		if err != nil {
+			combinedErr = errors.Join(combinedErr, err)
+			continue
+		}
+		rule.sources = append(rule.sources, prefix)
+	}
+	if len(cfg.Communities) > 0 {
+		rule.communities = map[string]bool{}
+		for _, community := range cfg.Communities {
+			rule.communities[community] = true
+		}
+	}
+
+	for i, predicateCfg := range cfg.Varbinds {
+		predicate, err := newVarbindPredicate(predicateCfg)
+		if err != nil {
+			combinedErr = errors.Join(combinedErr, fmt.Errorf("varbinds[%d]: %w", i, err))
+			continue
+		}
+		rule.varbinds = append(rule.varbinds, predicate)
+	}
+
+	if cfg.Severity == "" {
+		combinedErr = errors.Join(combinedErr, errEmptySeverity)
+	} else if severity, ok := parseSeverity(cfg.Severity); ok {
+		rule.severity = severity
+		if rule.severityText == "" {
+			rule.severityText = strings.ToUpper(cfg.Severity)
+		}
+	} else {
+		combinedErr = errors.Join(combinedErr, fmt.Errorf(errMsgBadSeverity, cfg.Severity))
+	}
+
+	if combinedErr != nil {
+		return nil, combinedErr
+	}
+	return rule, nil
+}
+
+// newVarbindPredicate compiles a predicate on the value of a varbind
+func newVarbindPredicate(cfg VarbindPredicateConfig) (varbindPredicate, error) {
+	var combinedErr error
+	predicate := varbindPredicate{
+		oid:      strings.TrimPrefix(cfg.OID, "."),
+		operator: strings.ToLower(cfg.Operator),
+		value:    cfg.Value,
+	}
+	if predicate.operator == "" {
+		predicate.operator = operatorEquals
+	}
+
+	if cfg.OID == "" {
+		combinedErr = errors.Join(combinedErr, errEmptyVarbindOID)
+	} else if !isDottedOID(predicate.oid) {
+		combinedErr = errors.Join(combinedErr, fmt.Errorf(errMsgInvalidRuleOID, "oid", cfg.OID))
+	}
+
+	switch predicate.operator {
+	case operatorEquals, operatorNotEquals:
+	case operatorMatches:
+		pattern, err := regexp.Compile(cfg.Value)
+		if err != nil {
+			combinedErr = errors.Join(combinedErr, fmt.Errorf(errMsgInvalidRegexp, cfg.Value, err))
+		}
+		predicate.pattern = pattern
+	case operatorGreaterThan, operatorLessThan:
+		number, ok := new(big.Int).SetString(cfg.Value, 10)
+		if !ok {
+			combinedErr = errors.Join(combinedErr, fmt.Errorf(errMsgNotANumber, cfg.Value, predicate.operator))
+		}
+		predicate.number = number
+	default:
+		combinedErr = errors.Join(combinedErr, fmt.Errorf(errMsgBadOperator, cfg.Operator))
+	}
+
+	return predicate, combinedErr
+}
+
+// parseSeverity parses the short name of a severity number, such as WARN or ERROR2
+func parseSeverity(name string) (plog.SeverityNumber, bool) {
+	for severity := plog.SeverityNumberTrace; severity <= plog.SeverityNumberFatal4; severity++ {
+		if strings.EqualFold(severity.String(), name) {
+			return severity, true
+		}
+	}
+	return plog.SeverityNumberUnspecified, false
+}
+
+// isDottedOID tells whether s is a dotted OID without a leading dot
+func isDottedOID(s string) bool {
+	for _, arc := range strings.Split(s, ".") {
+		if _, err := strconv.ParseUint(arc, 10, 32); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// apply sets the severity of a log record with the first rule matching its notification from an
+// agent. The severity is left unspecified when no rule matches.
+func (rules severityRules) apply(logRecord plog.LogRecord, packet *gosnmp.SnmpPacket, agent netip.Addr, mibs *mib.MIB) {
+	oid := strings.TrimPrefix(trapOID(packet), ".")
+	for _, rule := range rules {
+		if rule.matches(oid, packet, agent.Unmap(), mibs) {
+			logRecord.SetSeverityNumber(rule.severity)
+			logRecord.SetSeverityText(rule.severityText)
+			return
+		}
+	}
+}
+
+// matches tells whether a notification matches every criterion of the rule
+func (rule *severityRule) matches(oid string, packet *gosnmp.SnmpPacket, agent netip.Addr, mibs *mib.MIB) bool {
+	switch {
+	case rule.trapOID != "" && oid != rule.trapOID:
+		return false
+	case rule.trapOIDPrefix != "" && !isUnderOID(oid, rule.trapOIDPrefix):
+		return false
+	case rule.trapOIDGlob != "":
+		if matched, _ := path.Match(rule.trapOIDGlob, oid); !matched {
+			return false
+		}
+	}
+	if len(rule.sources) > 0 && !prefixesContain(rule.sources, agent) {
+		return false
+	}
+	// SNMPv3 notifications have no community
+	if rule.communities != nil && (packet.Version == gosnmp.Version3 || !rule.communities[packet.Community]) {
+		return false
+	}
+	for _, predicate := range rule.varbinds {
+		if !predicate.holds(packet, mibs) {
+			return false
+		}
+	}
+	return true
+}
+
+// holds tells whether any varbind of the OID of the predicate, or of an instance of it, has a
+// value which satisfies the operator. Values are compared as text, or as numbers by greater_than
+// and less_than. They are also compared as rendered with the MIBs, so that an enumeration can be
+// compared by its label.
+func (predicate varbindPredicate) holds(packet *gosnmp.SnmpPacket, mibs *mib.MIB) bool {
+	for _, variable := range packet.Variables {
+		if !isUnderOID(strings.TrimPrefix(variable.Name, "."), predicate.oid) {
+			continue
+		}
+		if predicate.number != nil {
+			if compareNumber(variable, predicate.number, predicate.operator) {
+				return true
+			}
+			continue
+		}
+
+		texts := []string{varbindText(variable)}
+		if mibs != nil {
+			node, _ := mibs.Lookup(variable.Name)
+			if display, ok := displayValue(mibs, node, variable); ok {
+				texts = append(texts, display)
+			}
+		}
+		if predicate.operator == operatorNotEquals {
+			if !contains(texts, predicate.value) {
+				return true
+			}
+			continue
+		}
+		for _, text := range texts {
+			if predicate.operator == operatorEquals && text == predicate.value ||
+				predicate.operator == operatorMatches && predicate.pattern.MatchString(text) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// compareNumber compares the value of a numeric varbind with a number
+func compareNumber(variable gosnmp.SnmpPDU, number *big.Int, operator string) bool {
+	switch variable.Type { // nolint:exhaustive
+	case gosnmp.Integer, gosnmp.Counter32, gosnmp.Gauge32, gosnmp.TimeTicks, gosnmp.Counter64, gosnmp.Uinteger32:
+	default:
+		return false
+	}
+	comparison := gosnmp.ToBigInt(variable.Value).Cmp(number)
+	if operator == operatorGreaterThan {
+		return comparison > 0
+	}
+	return comparison < 0
+}
+
+// varbindText returns the value of a varbind as text: numbers in decimal, strings as they are
+// and OIDs without a leading dot
+func varbindText(variable gosnmp.SnmpPDU) string {
+	switch variable.Type { // nolint:exhaustive
+	case gosnmp.Integer, gosnmp.Counter32, gosnmp.Gauge32, gosnmp.TimeTicks, gosnmp.Counter64, gosnmp.Uinteger32:
+		return gosnmp.ToBigInt(variable.Value).String()
+	case gosnmp.ObjectIdentifier:
+		oid, _ := variable.Value.(string)
+		return strings.TrimPrefix(oid, ".")
+	}
+	if variable.Value == nil {
+		return ""
+	}
+	return toString(variable.Value)
+}
+
+// isUnderOID tells whether an OID is the given one or is under it, without leading dots
+func isUnderOID(oid, parent string) bool {
+	return oid == parent || strings.HasPrefix(oid, parent+".")
+}
@@ -0,0 +1,177 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package snmptrapreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver"
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/plog"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver/internal/mib"
+)
+
+func TestSeverityRules(t *testing.T) {
+	mibs, err := mib.LoadStandard()
+	require.NoError(t, err)
+
+	v2 := func(community string, trapOID string, variables ...gosnmp.SnmpPDU) *gosnmp.SnmpPacket {
+		return &gosnmp.SnmpPacket{
+			Version:   gosnmp.Version2c,
+			Community: community,
+			PDUType:   gosnmp.SNMPv2Trap,
+			Variables: append([]gosnmp.SnmpPDU{
+				{Name: oidSysUpTime, Type: gosnmp.TimeTicks, Value: uint32(100)},
+				{Name: oidSnmpTrapOID, Type: gosnmp.ObjectIdentifier, Value: trapOID},
+			}, variables...),
+		}
+	}
+	operStatus := func(status int) gosnmp.SnmpPDU {
+		return gosnmp.SnmpPDU{Name: oidIfOperStatus + ".3", Type: gosnmp.Integer, Value: status}
+	}
+	temperature := func(degrees uint) gosnmp.SnmpPDU {
+		return gosnmp.SnmpPDU{Name: ".1.3.6.1.4.1.32473.2.2.1", Type: gosnmp.Gauge32, Value: degrees}
+	}
+
+	cfg := &Config{SeverityRules: []SeverityRuleConfig{
+		{TrapOID: "1.3.6.1.4.1.32473.1.1.0.1", Severity: "FATAL"},
+		{TrapOIDPrefix: ".1.3.6.1.4.1.32473.1.2", Sources: []string{"10.0.0.0/8"}, Severity: "ERROR2", SeverityText: "major"},
+		{TrapOIDPrefix: "1.3.6.1.4.1.32473.1.2", Communities: []string{"lab"}, Severity: "debug"},
+		{TrapOIDGlob: "1.3.6.1.4.1.*.0.7", Severity: "WARN3"},
+		{
+			TrapOID:  "1.3.6.1.4.1.32473.1.3",
+			Varbinds: []VarbindPredicateConfig{{OID: "1.3.6.1.4.1.32473.2.2.1", Operator: "greater_than", Value: "80"}},
+			Severity: "ERROR",
+		},
+		{
+			TrapOID:  "1.3.6.1.4.1.32473.1.3",
+			Varbinds: []VarbindPredicateConfig{{OID: "1.3.6.1.4.1.32473.2.2.1", Operator: "less_than", Value: "5"}},
+			Severity: "WARN",
+		},
+		{
+			// Enumerations are compared by their label as well as their number
+			TrapOID:  oidSnmpTraps + ".3",
+			Varbinds: []VarbindPredicateConfig{{OID: oidIfOperStatus, Value: "lowerLayerDown"}},
+			Severity: "INFO",
+		},
+		{
+			TrapOID:  "1.3.6.1.4.1.32473.1.4",
+			Varbinds: []VarbindPredicateConfig{{OID: "1.3.6.1.4.1.32473.2.3", Operator: "matches", Value: "^disk[0-9]+$"}},
+			Severity: "ERROR",
+		},
+		{
+			TrapOID:  "1.3.6.1.4.1.32473.1.4",
+			Varbinds: []VarbindPredicateConfig{{OID: "1.3.6.1.4.1.32473.2.3", Operator: "not_equals", Value: "fan"}},
+			Severity: "WARN",
+		},
+	}}
+	rules, err := newSeverityRules(cfg)
+	require.NoError(t, err)
+
+	type testCase struct {
+		name         string
+		packet       *gosnmp.SnmpPacket
+		source       string
+		expected     plog.SeverityNumber
+		expectedText string
+	}
+
+	testCases := []testCase{
+		{name: "TrapOID", packet: v2("public", ".1.3.6.1.4.1.32473.1.1.0.1"), expected: plog.SeverityNumberFatal, expectedText: "FATAL"},
+		{name: "OtherTrapOID", packet: v2("public", ".1.3.6.1.4.1.32473.1.1.0.2"), expected: plog.SeverityNumberUnspecified},
+		{name: "PrefixAndSource", packet: v2("public", ".1.3.6.1.4.1.32473.1.2.0.1"), source: "10.1.2.3", expected: plog.SeverityNumberError2, expectedText: "major"},
+		{name: "PrefixAndCommunity", packet: v2("lab", ".1.3.6.1.4.1.32473.1.2.0.1"), expected: plog.SeverityNumberDebug, expectedText: "DEBUG"},
+		{name: "PrefixIsWholeArcs", packet: v2("lab", ".1.3.6.1.4.1.32473.1.20.0.1"), expected: plog.SeverityNumberUnspecified},
+		{name: "PrefixOtherSourceAndCommunity", packet: v2("public", ".1.3.6.1.4.1.32473.1.2.0.1"), source: "192.0.2.1", expected: plog.SeverityNumberUnspecified},
+		{name: "Glob", packet: v2("public", ".1.3.6.1.4.1.9.9.41.2.0.7"), expected: plog.SeverityNumberWarn3, expectedText: "WARN3"},
+		{name: "GreaterThan", packet: v2("public", ".1.3.6.1.4.1.32473.1.3", temperature(95)), expected: plog.SeverityNumberError},
+		{name: "LessThan", packet: v2("public", ".1.3.6.1.4.1.32473.1.3", temperature(2)), expected: plog.SeverityNumberWarn},
+		{name: "NoNumberMatches", packet: v2("public", ".1.3.6.1.4.1.32473.1.3", temperature(40)), expected: plog.SeverityNumberUnspecified},
+		{name: "VarbindMissing", packet: v2("public", ".1.3.6.1.4.1.32473.1.3"), expected: plog.SeverityNumberUnspecified},
+		{name: "EnumerationLabel", packet: v2("public", oidSnmpTraps+".3", operStatus(7)), expected: plog.SeverityNumberInfo},
+		{name: "DefaultLinkDown", packet: v2("public", oidSnmpTraps+".3", operStatus(2)), expected: plog.SeverityNumberWarn, expectedText: "WARN"},
+		{name: "DefaultColdStart", packet: v2("public", oidSnmpTraps+".1"), expected: plog.SeverityNumberInfo, expectedText: "INFO"},
+		{
+			name:         "DefaultV1AuthenticationFailure",
+			packet:       &gosnmp.SnmpPacket{Version: gosnmp.Version1, PDUType: gosnmp.Trap, SnmpTrap: gosnmp.SnmpTrap{Enterprise: ".1.3.6.1.4.1.8072.4", GenericTrap: 4}},
+			expected:     plog.SeverityNumberError,
+			expectedText: "ERROR",
+		},
+		{
+			name:     "Matches",
+			packet:   v2("public", ".1.3.6.1.4.1.32473.1.4", gosnmp.SnmpPDU{Name: ".1.3.6.1.4.1.32473.2.3.0", Type: gosnmp.OctetString, Value: []byte("disk12")}),
+			expected: plog.SeverityNumberError,
+		},
+		{
+			name:     "NotEquals",
+			packet:   v2("public", ".1.3.6.1.4.1.32473.1.4", gosnmp.SnmpPDU{Name: ".1.3.6.1.4.1.32473.2.3.0", Type: gosnmp.OctetString, Value: []byte("psu")}),
+			expected: plog.SeverityNumberWarn,
+		},
+		{
+			name:     "NotEqualsIsEqual",
+			packet:   v2("public", ".1.3.6.1.4.1.32473.1.4", gosnmp.SnmpPDU{Name: ".1.3.6.1.4.1.32473.2.3.0", Type: gosnmp.OctetString, Value: []byte("fan")}),
+			expected: plog.SeverityNumberUnspecified,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			source := test.source
+			if source == "" {
+				source = "192.0.2.1"
+			}
+			logRecord := plog.NewLogRecord()
+			rules.apply(logRecord, test.packet, netip.MustParseAddr(source), mibs)
+			require.Equal(t, test.expected, logRecord.SeverityNumber())
+			if test.expectedText != "" {
+				require.Equal(t, test.expectedText, logRecord.SeverityText())
+			}
+		})
+	}
+
+	// SNMPv3 notifications have no community, so rules with communities never match them
+	logRecord := plog.NewLogRecord()
+	packet := v2("lab", ".1.3.6.1.4.1.32473.1.2.0.1")
+	packet.Version = gosnmp.Version3
+	rules.apply(logRecord, packet, netip.MustParseAddr("192.0.2.1"), mibs)
+	require.Equal(t, plog.SeverityNumberUnspecified, logRecord.SeverityNumber())
+}
+
+func TestNewSeverityRuleErrors(t *testing.T) {
+	type testCase struct {
+		name        string
+		rule        SeverityRuleConfig
+		expectedErr string
+	}
+
+	testCases := []testCase{
+		{name: "MissingSeverity", rule: SeverityRuleConfig{TrapOID: "1.3.6.1"}, expectedErr: "severity must be specified"},
+		{name: "BadSeverity", rule: SeverityRuleConfig{Severity: "WARNING"}, expectedErr: "invalid severity 'WARNING': must be TRACE, DEBUG, INFO, WARN, ERROR or FATAL, optionally followed by 2, 3 or 4"},
+		{name: "SeveralTrapOIDs", rule: SeverityRuleConfig{TrapOID: "1.3.6.1", TrapOIDPrefix: "1.3.6", Severity: "INFO"}, expectedErr: "only one of trap_oid, trap_oid_prefix and trap_oid_glob may be set"},
+		{name: "BadTrapOID", rule: SeverityRuleConfig{TrapOID: "IF-MIB::linkDown", Severity: "INFO"}, expectedErr: "invalid trap_oid 'IF-MIB::linkDown': must be a dotted OID"},
+		{name: "BadTrapOIDPrefix", rule: SeverityRuleConfig{TrapOIDPrefix: "1.3.", Severity: "INFO"}, expectedErr: "invalid trap_oid_prefix '1.3.': must be a dotted OID"},
+		{name: "BadTrapOIDGlob", rule: SeverityRuleConfig{TrapOIDGlob: "1.3.[", Severity: "INFO"}, expectedErr: "invalid trap_oid_glob '1.3.[': syntax error in pattern"},
+		{name: "BadSource", rule: SeverityRuleConfig{Sources: []string{"10.0.0.0/33"}, Severity: "INFO"}, expectedErr: "invalid source '10.0.0.0/33': must be an IP address or a CIDR block"},
+		{name: "MissingVarbindOID", rule: SeverityRuleConfig{Varbinds: []VarbindPredicateConfig{{Value: "1"}}, Severity: "INFO"}, expectedErr: "varbinds[0]: oid must be specified"},
+		{name: "BadVarbindOID", rule: SeverityRuleConfig{Varbinds: []VarbindPredicateConfig{{OID: "ifOperStatus", Value: "1"}}, Severity: "INFO"}, expectedErr: "varbinds[0]: invalid oid 'ifOperStatus': must be a dotted OID"},
+		{name: "BadOperator", rule: SeverityRuleConfig{Varbinds: []VarbindPredicateConfig{{OID: "1.3.6.1", Operator: "contains"}}, Severity: "INFO"}, expectedErr: "varbinds[0]: invalid operator 'contains': must be either equals, not_equals, matches, greater_than, or less_than"},
+		{name: "BadRegexp", rule: SeverityRuleConfig{Varbinds: []VarbindPredicateConfig{{OID: "1.3.6.1", Operator: "matches", Value: "("}}, Severity: "INFO"}, expectedErr: "varbinds[0]: invalid value '(' for matches: error parsing regexp: missing closing ): `(`"},
+		{name: "NotANumber", rule: SeverityRuleConfig{Varbinds: []VarbindPredicateConfig{{OID: "1.3.6.1", Operator: "greater_than", Value: "high"}}, Severity: "INFO"}, expectedErr: "varbinds[0]: invalid value 'high' for greater_than: must be an integer"},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := newSeverityRule(test.rule)
+			require.EqualError(t, err, test.expectedErr)
+		})
+	}
+
+	// Every problem of a rule is reported
+	_, err := newSeverityRule(SeverityRuleConfig{TrapOID: "x", TrapOIDGlob: "*"})
+	require.ErrorIs(t, err, errSeverityRuleTrapOIDs)
+	require.ErrorIs(t, err, errEmptySeverity)
+	require.ErrorContains(t, err, "invalid trap_oid 'x'")
+}
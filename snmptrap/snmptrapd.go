@@ -0,0 +1,143 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package snmptrapreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver"
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"go.opentelemetry.io/collector/config/configopaque"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver/internal/snmptrapd"
+)
+
+var (
+	errMsgSnmptrapdCommunity = `community '%s' is accepted from other sources by communities`
+	errMsgSnmptrapdUser      = `user '%s' has other settings in users`
+	errMsgSnmptrapdUserWErr  = `user '%s': %w`
+	errMsgSnmptrapdEngineID  = `engineID '%s' is not the engine_id '%s'`
+
+	errSnmptrapdAuthorization = errors.New("disableAuthorization accepts any community, while communities restricts them")
+)
+
+// securityLevels map the security levels of snmptrapd to those of the configuration
+var securityLevels = map[string]string{
+	snmptrapd.LevelNoAuth: "no_auth_no_priv",
+	snmptrapd.LevelAuth:   "auth_no_priv",
+	snmptrapd.LevelPriv:   "auth_priv",
+}
+
+// withSnmptrapdConf returns a copy of the configuration with the communities, users and engine
+// ID of its snmptrapd_conf file added, or the configuration itself when it has none. Settings of
+// the file which conflict with those of the configuration are errors, while those it repeats are
+// only added once.
+func (cfg *Config) withSnmptrapdConf() (*Config, error) {
+	if cfg.SnmptrapdConf == "" {
+		return cfg, nil
+	}
+	file, err := snmptrapd.Load(cfg.SnmptrapdConf)
+	if err != nil {
+		return nil, err
+	}
+
+	var combinedErr error
+	merged := *cfg
+	merged.Communities = slices.Clone(cfg.Communities)
+	merged.Users = slices.Clone(cfg.Users)
+
+	// Without authorization, snmptrapd accepts any community like an empty allowlist does
+	if file.DisableAuthorization && len(cfg.Communities) > 0 {
+		combinedErr = errors.Join(combinedErr, errSnmptrapdAuthorization)
+	}
+	for _, community := range file.Communities {
+		if file.DisableAuthorization {
+			break
+		}
+		i := slices.IndexFunc(cfg.Communities, func(communityCfg CommunityConfig) bool {
+			return communityCfg.Community == community.Community
+		})
+		switch {
+		case i < 0:
+			merged.Communities = append(merged.Communities, CommunityConfig{Community: community.Community, Sources: community.Sources})
+		case !sameSources(cfg.Communities[i].Sources, community.Sources):
+			combinedErr = errors.Join(combinedErr, fmt.Errorf(errMsgSnmptrapdCommunity, community.Community))
+		}
+	}
+
+	for _, user := range file.Users {
+		userCfg := UserConfig{
+			User:            user.Name,
+			EngineID:        user.EngineID,
+			SecurityLevel:   securityLevels[user.Level],
+			AuthType:        user.AuthProtocol,
+			AuthPassword:    configopaque.String(user.AuthPassphrase),
+			PrivacyType:     user.PrivProtocol,
+			PrivacyPassword: configopaque.String(user.PrivPassphrase),
+		}
+		engineID, err := parseEngineID(userCfg.EngineID)
+		if err != nil {
+			combinedErr = errors.Join(combinedErr, fmt.Errorf(errMsgSnmptrapdUserWErr, user.Name, err))
+			continue
+		}
+		i := slices.IndexFunc(cfg.Users, func(other UserConfig) bool {
+			otherEngineID, err := parseEngineID(other.EngineID)
+			return other.User == user.Name && err == nil && otherEngineID == engineID
+		})
+		switch {
+		case i < 0:
+			merged.Users = append(merged.Users, userCfg)
+		case !sameUser(cfg.Users[i], userCfg):
+			combinedErr = errors.Join(combinedErr, fmt.Errorf(errMsgSnmptrapdUser, user.Name))
+		}
+	}
+
+	if file.EngineID != "" {
+		if _, err := parseEngineID(file.EngineID); err != nil {
+			combinedErr = errors.Join(combinedErr, fmt.Errorf("engineID: %w", err))
+		} else if cfg.EngineID != "" && !strings.EqualFold(cfg.EngineID, file.EngineID) {
+			combinedErr = errors.Join(combinedErr, fmt.Errorf(errMsgSnmptrapdEngineID, file.EngineID, cfg.EngineID))
+		}
+		merged.EngineID = file.EngineID
+	}
+
+	if combinedErr != nil {
+		return nil, combinedErr
+	}
+	return &merged, nil
+}
+
+// sameSources tells whether two lists of sources hold the same networks, whatever their order
+func sameSources(a, b []string) bool {
+	normalize := func(sources []string) []string {
+		normalized := make([]string, 0, len(sources))
+		for _, source := range sources {
+			if prefix, err := parsePrefix(source); err == nil {
+				source = prefix.String()
+			}
+			normalized = append(normalized, source)
+		}
+		slices.Sort(normalized)
+		return slices.Compact(normalized)
+	}
+	return slices.Equal(normalize(a), normalize(b))
+}
+
+// sameUser tells whether two users have the same security level, and the same protocols and
+// passwords for that level
+func sameUser(a, b UserConfig) bool {
+	x, y := a.config(), b.config()
+	level := strings.ToLower(x.SecurityLevel)
+	if level != strings.ToLower(y.SecurityLevel) {
+		return false
+	}
+	if level != "no_auth_no_priv" && (!strings.EqualFold(x.AuthType, y.AuthType) || x.AuthPassword != y.AuthPassword) {
+		return false
+	}
+	if level == "auth_priv" && (!strings.EqualFold(x.PrivacyType, y.PrivacyType) || x.PrivacyPassword != y.PrivacyPassword) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,119 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package snmptrapreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver"
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+)
+
+func TestWithSnmptrapdConf(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "snmptrapd.conf")
+	require.NoError(t, os.WriteFile(file, []byte(`
+authCommunity log public 10.0.0.0/255.0.0.0
+authCommunity log public 192.0.2.10
+authCommunity log private
+createUser -e 0x8000000001020304 alice SHA alice-secret AES
+createUser bob MD5 bob-secret
+authUser log alice priv
+authUser log bob
+engineID trap-host-01
+traphandle default /usr/local/bin/handler
+`), 0o600))
+
+	// Without a file the configuration is used as is
+	cfg := &Config{Communities: []CommunityConfig{{Community: "public"}}}
+	merged, err := cfg.withSnmptrapdConf()
+	require.NoError(t, err)
+	require.Same(t, cfg, merged)
+
+	cfg = &Config{
+		SnmptrapdConf: file,
+		Communities:   []CommunityConfig{{Community: "public", Sources: []string{"192.0.2.10", "10.0.0.0/8"}}},
+		Users: []UserConfig{
+			{User: "alice", EngineID: "8000000001020304", SecurityLevel: "AUTH_PRIV", AuthType: "sha", AuthPassword: "alice-secret", PrivacyType: "aes", PrivacyPassword: "alice-secret"},
+		},
+	}
+	merged, err = cfg.withSnmptrapdConf()
+	require.NoError(t, err)
+	require.Equal(t, []CommunityConfig{
+		{Community: "public", Sources: []string{"192.0.2.10", "10.0.0.0/8"}},
+		{Community: "private"},
+	}, merged.Communities)
+	require.Equal(t, []UserConfig{
+		cfg.Users[0],
+		{User: "bob", SecurityLevel: "auth_no_priv", AuthType: "MD5", AuthPassword: "bob-secret"},
+	}, merged.Users)
+	require.Equal(t, "80001f8804747261702d686f73742d3031", merged.EngineID)
+	// The configuration itself is left as is
+	require.Len(t, cfg.Communities, 1)
+	require.Len(t, cfg.Users, 1)
+	require.Empty(t, cfg.EngineID)
+
+	// The merged settings are those of the receiver
+	rcvr, err := newSnmptrapReceiver(receivertest.NewNopCreateSettings(), cfg, nil)
+	require.NoError(t, err)
+	require.Len(t, rcvr.config.Users, 2)
+	require.True(t, rcvr.communities.authorized("private", nil))
+
+	type testCase struct {
+		name        string
+		data        string
+		cfg         *Config
+		expectedErr string
+	}
+
+	testCases := []testCase{
+		{
+			name:        "CommunitySources",
+			data:        "authCommunity log public 10.0.0.0/8\n",
+			cfg:         &Config{Communities: []CommunityConfig{{Community: "public"}}},
+			expectedErr: "community 'public' is accepted from other sources by communities",
+		},
+		{
+			name:        "DisableAuthorization",
+			data:        "disableAuthorization yes\n",
+			cfg:         &Config{Communities: []CommunityConfig{{Community: "public"}}},
+			expectedErr: "disableAuthorization accepts any community, while communities restricts them",
+		},
+		{
+			name:        "UserLevel",
+			data:        "createUser bob MD5 bob-secret\nauthUser log bob\n",
+			cfg:         &Config{Users: []UserConfig{{User: "bob", SecurityLevel: "auth_priv", AuthPassword: "bob-secret", PrivacyPassword: "bob-secret"}}},
+			expectedErr: "user 'bob' has other settings in users",
+		},
+		{
+			name:        "UserEngineID",
+			data:        "createUser -e 0x80 bob MD5 bob-secret\nauthUser log bob\n",
+			cfg:         &Config{},
+			expectedErr: "user 'bob': engine_id must be a hex string of 5 to 32 bytes",
+		},
+		{
+			name:        "EngineID",
+			data:        "engineID trap-host-01\n",
+			cfg:         &Config{EngineID: "8000000001020304"},
+			expectedErr: "engineID '80001f8804747261702d686f73742d3031' is not the engine_id '8000000001020304'",
+		},
+		{
+			name:        "File",
+			data:        "authUser log carol noauth\n",
+			cfg:         &Config{},
+			expectedErr: `:1: user "carol" has no createUser line`,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "snmptrapd.conf")
+			require.NoError(t, os.WriteFile(file, []byte(test.data), 0o600))
+			test.cfg.SnmptrapdConf = file
+			_, err := test.cfg.withSnmptrapdConf()
+			require.ErrorContains(t, err, test.expectedErr)
+		})
+	}
+}
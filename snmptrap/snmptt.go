@@ -0,0 +1,297 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package snmptrapreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver"
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"go.opentelemetry.io/collector/pdata/plog"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver/internal/mib"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver/internal/snmptt"
+)
+
+// Attributes of the SNMPTT event matching a notification
+const (
+	attributeSNMPTTEvent    = "snmptt.event"
+	attributeSNMPTTCategory = "snmptt.category"
+)
+
+const (
+	// hostNameTTL is how long the names of agents, or the failures to resolve them, are cached
+	hostNameTTL = 5 * time.Minute
+	// hostNameTimeout bounds the reverse lookup of the name of an agent
+	hostNameTimeout = time.Second
+	// maxHostNames bounds the number of agents whose names are cached
+	maxHostNames = 4096
+)
+
+var errEmptySNMPTTFile = errors.New("snmptt_files must not contain empty paths")
+
+// snmpttSeverities map the usual severities of SNMPTT events, in lower case, to severity
+// numbers. Other severities only set the severity text.
+var snmpttSeverities = map[string]plog.SeverityNumber{
+	"debug":         plog.SeverityNumberDebug,
+	"normal":        plog.SeverityNumberInfo,
+	"informational": plog.SeverityNumberInfo,
+	"info":          plog.SeverityNumberInfo,
+	"warning":       plog.SeverityNumberWarn,
+	"minor":         plog.SeverityNumberWarn,
+	"major":         plog.SeverityNumberError,
+	"severe":        plog.SeverityNumberError,
+	"critical":      plog.SeverityNumberFatal,
+	"fatal":         plog.SeverityNumberFatal,
+}
+
+// snmpttEvents are the events of the SNMPTT configuration files. As with SNMPTT, the events of
+// the exact snmpTrapOID of a notification are tried first, in the order of the files, and then
+// the wildcard events from the most specific one.
+type snmpttEvents struct {
+	exact     map[string][]*snmptt.Event
+	wildcards []*snmptt.Event
+	names     *hostNames
+}
+
+// newSNMPTTEvents loads the events of the snmptt_files of the configuration. It returns nil when
+// there are none.
+func newSNMPTTEvents(cfg *Config) (*snmpttEvents, error) {
+	if len(cfg.SNMPTTFiles) == 0 {
+		return nil, nil
+	}
+	loaded, err := snmptt.Load(cfg.SNMPTTFiles...)
+	if err != nil {
+		return nil, err
+	}
+
+	events := &snmpttEvents{exact: map[string][]*snmptt.Event{}, names: newHostNames(net.DefaultResolver.LookupAddr)}
+	for _, event := range loaded {
+		if event.Wildcard {
+			events.wildcards = append(events.wildcards, event)
+			continue
+		}
+		events.exact[event.OID] = append(events.exact[event.OID], event)
+	}
+	slices.SortStableFunc(events.wildcards, func(a, b *snmptt.Event) int {
+		return strings.Count(b.OID, ".") - strings.Count(a.OID, ".")
+	})
+	return events, nil
+}
+
+// apply gives a notification the category and severity of the first event which matches it and
+// whose NODES and MATCH lines accept its agent, and its FORMAT as the body of its log record. The
+// log record is left as is when no event matches. NODES lines with host names match the name of
+// the agent, which is resolved with a reverse lookup.
+func (events *snmpttEvents) apply(logRecord plog.LogRecord, packet *gosnmp.SnmpPacket, peer net.Addr, agent netip.Addr, mibs *mib.MIB) {
+	if events == nil {
+		return
+	}
+	oid := strings.TrimPrefix(trapOID(packet), ".")
+	if oid == "" {
+		return
+	}
+
+	variables := newSNMPTTVariables(packet, peer, agent, mibs)
+	for _, event := range events.matching(oid) {
+		lookup := variables.lookup(event)
+		// The name of the agent is only looked up for the NODES lines which need it
+		var name string
+		if event.HasHostNames() {
+			name = events.names.resolve(variables.agent)
+		}
+		if !event.Accepts(variables.agent, name, lookup) {
+			continue
+		}
+
+		attributes := logRecord.Attributes()
+		attributes.PutStr(attributeSNMPTTEvent, event.Name)
+		attributes.PutStr(attributeSNMPTTCategory, event.Category)
+		logRecord.SetSeverityText(event.Severity)
+		if severity, ok := snmpttSeverities[strings.ToLower(event.Severity)]; ok {
+			logRecord.SetSeverityNumber(severity)
+		}
+		if event.Format != "" {
+			setMessage(logRecord, event.Message(lookup))
+		}
+		return
+	}
+}
+
+// matching returns the events of an snmpTrapOID in the order they are tried
+func (events *snmpttEvents) matching(oid string) []*snmptt.Event {
+	matching := slices.Clone(events.exact[oid])
+	for _, event := range events.wildcards {
+		if event.Matches(oid) {
+			matching = append(matching, event)
+		}
+	}
+	return matching
+}
+
+// hostNames resolve the names of agents with reverse lookups. Names are cached, and so are
+// failures to resolve them, so that notifications don't each wait for a lookup.
+type hostNames struct {
+	lookupAddr func(ctx context.Context, addr string) ([]string, error)
+	mu         sync.Mutex
+	entries    map[netip.Addr]hostName
+}
+
+// hostName is the name of an agent, empty when it has none, and when it is to be looked up again
+type hostName struct {
+	name    string
+	expires time.Time
+}
+
+func newHostNames(lookupAddr func(ctx context.Context, addr string) ([]string, error)) *hostNames {
+	return &hostNames{lookupAddr: lookupAddr, entries: map[netip.Addr]hostName{}}
+}
+
+// resolve returns the name of an agent, or an empty name when it has none or the lookup fails
+func (names *hostNames) resolve(agent netip.Addr) string {
+	if !agent.IsValid() {
+		return ""
+	}
+	now := time.Now()
+	names.mu.Lock()
+	entry, ok := names.entries[agent]
+	names.mu.Unlock()
+	if ok && now.Before(entry.expires) {
+		return entry.name
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), hostNameTimeout)
+	defer cancel()
+	entry = hostName{expires: now.Add(hostNameTTL)}
+	if resolved, err := names.lookupAddr(ctx, agent.String()); err == nil && len(resolved) > 0 {
+		entry.name = strings.TrimSuffix(resolved[0], ".")
+	}
+
+	names.mu.Lock()
+	defer names.mu.Unlock()
+	// Rather than tracking which names are least used, the cache starts over when it is full
+	if len(names.entries) >= maxHostNames {
+		clear(names.entries)
+	}
+	names.entries[agent] = entry
+	return entry.name
+}
+
+// snmpttVariables hold what the variables of FORMAT and MATCH lines expand to for a notification
+type snmpttVariables struct {
+	packet *gosnmp.SnmpPacket
+	mibs   *mib.MIB
+	agent  netip.Addr
+	source netip.Addr
+	// varbinds are those the numbered variables refer to, without sysUpTime.0 and snmpTrapOID.0
+	varbinds []gosnmp.SnmpPDU
+}
+
+func newSNMPTTVariables(packet *gosnmp.SnmpPacket, peer net.Addr, agent netip.Addr, mibs *mib.MIB) *snmpttVariables {
+	variables := &snmpttVariables{
+		packet: packet,
+		mibs:   mibs,
+		agent:  agent,
+	}
+	if ip, _ := splitAddr(peer); ip != nil {
+		variables.source, _ = netip.AddrFromSlice(ip)
+		variables.source = variables.source.Unmap()
+	}
+	for _, variable := range packet.Variables {
+		if variable.Name != oidSysUpTime && variable.Name != oidSnmpTrapOID {
+			variables.varbinds = append(variables.varbinds, variable)
+		}
+	}
+	return variables
+}
+
+// lookup returns the variables of an event. The variables are those of SNMPTT:
+//   - $n is the value of the nth varbind, $+n is its name and value, and $-n also has its type
+//   - $* and $+* are the values, or the names and values, of all of the varbinds, and $# their number
+//   - $A and $aA are the address of the agent, and $R and $aR that of the sender of the packet
+//   - $N, $c, $s and $i are the name, category, severity and OID of the event
+//   - $o and $O are the snmpTrapOID, and $e and $E the enterprise, by their OID or name
+//   - $C is the community
+func (variables *snmpttVariables) lookup(event *snmptt.Event) snmptt.Lookup {
+	return func(variable string) (string, bool) {
+		switch variable {
+		case "A", "aA":
+			return variables.agent.String(), variables.agent.IsValid()
+		case "R", "aR":
+			return variables.source.String(), variables.source.IsValid()
+		case "N":
+			return event.Name, true
+		case "c":
+			return event.Category, true
+		case "s":
+			return event.Severity, true
+		case "i":
+			if event.Wildcard {
+				return event.OID + ".*", true
+			}
+			return event.OID, true
+		case "o":
+			return strings.TrimPrefix(trapOID(variables.packet), "."), true
+		case "O":
+			return variables.name(trapOID(variables.packet)), true
+		case "e":
+			return strings.TrimPrefix(trapEnterprise(variables.packet), "."), true
+		case "E":
+			return variables.name(trapEnterprise(variables.packet)), true
+		case "C":
+			return variables.packet.Community, true
+		case "#":
+			return strconv.Itoa(len(variables.varbinds)), true
+		case "*", "+*":
+			texts := make([]string, 0, len(variables.varbinds))
+			for i := range variables.varbinds {
+				texts = append(texts, variables.varbind(i, variable[0] == '+', false))
+			}
+			return strings.Join(texts, " "), true
+		}
+
+		named, typed := strings.HasPrefix(variable, "+"), strings.HasPrefix(variable, "-")
+		n, err := strconv.Atoi(strings.TrimLeft(variable, "+-"))
+		if err != nil || n < 1 || n > len(variables.varbinds) {
+			return "", false
+		}
+		return variables.varbind(n-1, named || typed, typed), true
+	}
+}
+
+// varbind renders a varbind by its value, rendered with the MIBs when they tell how, optionally
+// preceded by its name and its type as in name (type):value
+func (variables *snmpttVariables) varbind(i int, named, typed bool) string {
+	variable := variables.varbinds[i]
+	value := varbindText(variable)
+	if variables.mibs != nil {
+		node, _ := variables.mibs.Lookup(variable.Name)
+		if display, ok := displayValue(variables.mibs, node, variable); ok {
+			value = display
+		}
+	}
+	if !named {
+		return value
+	}
+	name := variables.name(variable.Name)
+	if typed {
+		name += " (" + varbindTypeNames[variable.Type] + ")"
+	}
+	return name + ":" + value
+}
+
+// name names an OID with the MIBs, or returns it without its leading dot
+func (variables *snmpttVariables) name(oid string) string {
+	if variables.mibs == nil {
+		return strings.TrimPrefix(oid, ".")
+	}
+	return varbindName(variables.mibs, oid)
+}
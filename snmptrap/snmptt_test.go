@@ -0,0 +1,189 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package snmptrapreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver"
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver/internal/mib"
+)
+
+func TestSNMPTTEvents(t *testing.T) {
+	mibs, err := mib.LoadStandard()
+	require.NoError(t, err)
+
+	file := filepath.Join(t.TempDir(), "snmptt.conf")
+	require.NoError(t, os.WriteFile(file, []byte(`
+EVENT linkDownCore .1.3.6.1.6.3.1.1.5.3 "Core Links" Critical
+FORMAT Core link $1 down on $A ($+3)
+NODES 10.0.0.0/8
+EVENT linkDown .1.3.6.1.6.3.1.1.5.3 "Status Events" Minor
+FORMAT Link down on interface $1 of $A, sent by $aR: $-2
+MATCH $3: !(up)
+EVENT exampleTrap .1.3.6.1.4.1.32473.1.1.0.* Example Indeterminate
+EVENT exampleTraps .1.3.6.1.4.1.32473.* Example Warning
+FORMAT $N $o $O from $e with $# varbinds: $*
+`), 0o600))
+	events, err := newSNMPTTEvents(&Config{SNMPTTFiles: []string{file}})
+	require.NoError(t, err)
+
+	peer := &net.UDPAddr{IP: net.ParseIP("192.0.2.1"), Port: 1620}
+	local := &net.UDPAddr{IP: net.ParseIP("192.0.2.2"), Port: 162}
+	newLogRecord := func(packet *gosnmp.SnmpPacket) plog.LogRecord {
+		logs := trapToLogs(packet, peer, local, time.Now())
+		return logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+	}
+	agent := func(packet *gosnmp.SnmpPacket) netip.Addr {
+		addr, _ := newAgentAddresses(packet, peer).resolve(nil)
+		return addr
+	}
+
+	linkDown := &gosnmp.SnmpPacket{
+		Version:   gosnmp.Version2c,
+		Community: "public",
+		PDUType:   gosnmp.SNMPv2Trap,
+		Variables: []gosnmp.SnmpPDU{
+			{Name: oidSysUpTime, Type: gosnmp.TimeTicks, Value: uint32(100)},
+			{Name: oidSnmpTrapOID, Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.6.3.1.1.5.3"},
+			{Name: ".1.3.6.1.2.1.2.2.1.2.12", Type: gosnmp.OctetString, Value: []byte("Gi0/1")},
+			{Name: ".1.3.6.1.2.1.2.2.1.7.12", Type: gosnmp.Integer, Value: 1},
+			{Name: ".1.3.6.1.2.1.2.2.1.8.12", Type: gosnmp.Integer, Value: 2},
+		},
+	}
+	logRecord := newLogRecord(linkDown)
+	events.apply(logRecord, linkDown, peer, agent(linkDown), mibs)
+	requireAttribute(t, logRecord, attributeSNMPTTEvent, "linkDown")
+	requireAttribute(t, logRecord, attributeSNMPTTCategory, "Status Events")
+	require.Equal(t, plog.SeverityNumberWarn, logRecord.SeverityNumber())
+	require.Equal(t, "Minor", logRecord.SeverityText())
+	require.Equal(t, "Link down on interface Gi0/1 of 192.0.2.1, sent by 192.0.2.1: IF-MIB::ifAdminStatus.12 (Integer):up", logRecord.Body().Str())
+	_, ok := logRecord.Attributes().Get(attributeSNMPPDU)
+	require.True(t, ok)
+
+	// NODES are matched with the agent, which a proxy gives in snmpTrapAddress.0
+	proxied := *linkDown
+	proxied.Variables = append(append([]gosnmp.SnmpPDU{}, linkDown.Variables...),
+		gosnmp.SnmpPDU{Name: oidSnmpTrapAddress, Type: gosnmp.IPAddress, Value: "10.1.2.3"})
+	logRecord = newLogRecord(&proxied)
+	events.apply(logRecord, &proxied, peer, agent(&proxied), mibs)
+	requireAttribute(t, logRecord, attributeSNMPTTEvent, "linkDownCore")
+	require.Equal(t, plog.SeverityNumberFatal, logRecord.SeverityNumber())
+	require.Equal(t, "Core link Gi0/1 down on 10.1.2.3 (IF-MIB::ifOperStatus.12:down)", logRecord.Body().Str())
+
+	// A notification which no event accepts is left as is
+	linkDown.Variables[4].Value = 1
+	logRecord = newLogRecord(linkDown)
+	events.apply(logRecord, linkDown, peer, agent(linkDown), mibs)
+	_, ok = logRecord.Attributes().Get(attributeSNMPTTEvent)
+	require.False(t, ok)
+	require.Equal(t, plog.SeverityNumberUnspecified, logRecord.SeverityNumber())
+	require.Equal(t, pcommon.ValueTypeMap, logRecord.Body().Type())
+
+	// The most specific wildcard event is used, and events without FORMAT leave the body as is
+	fanFailure := &gosnmp.SnmpPacket{
+		Version:   gosnmp.Version1,
+		Community: "public",
+		PDUType:   gosnmp.Trap,
+		Variables: []gosnmp.SnmpPDU{{Name: ".1.3.6.1.4.1.32473.2.1.1.1.0", Type: gosnmp.Integer, Value: 3}},
+		SnmpTrap:  gosnmp.SnmpTrap{Enterprise: ".1.3.6.1.4.1.32473.1.1", AgentAddress: "0.0.0.0", GenericTrap: 6, SpecificTrap: 1},
+	}
+	logRecord = newLogRecord(fanFailure)
+	events.apply(logRecord, fanFailure, peer, agent(fanFailure), nil)
+	requireAttribute(t, logRecord, attributeSNMPTTEvent, "exampleTrap")
+	require.Equal(t, "Indeterminate", logRecord.SeverityText())
+	require.Equal(t, plog.SeverityNumberUnspecified, logRecord.SeverityNumber())
+	require.Equal(t, pcommon.ValueTypeMap, logRecord.Body().Type())
+
+	fanFailure.Enterprise = ".1.3.6.1.4.1.32473.2"
+	logRecord = newLogRecord(fanFailure)
+	events.apply(logRecord, fanFailure, peer, agent(fanFailure), nil)
+	requireAttribute(t, logRecord, attributeSNMPTTEvent, "exampleTraps")
+	require.Equal(t, "exampleTraps 1.3.6.1.4.1.32473.2.0.1 1.3.6.1.4.1.32473.2.0.1 from 1.3.6.1.4.1.32473.2 with 1 varbinds: 3", logRecord.Body().Str())
+
+	// Notifications of other OIDs are left as is
+	fanFailure.Enterprise = ".1.3.6.1.4.1.9"
+	logRecord = newLogRecord(fanFailure)
+	events.apply(logRecord, fanFailure, peer, agent(fanFailure), nil)
+	_, ok = logRecord.Attributes().Get(attributeSNMPTTEvent)
+	require.False(t, ok)
+
+	// Without files there are no events
+	events, err = newSNMPTTEvents(&Config{})
+	require.NoError(t, err)
+	require.Nil(t, events)
+	logRecord = newLogRecord(fanFailure)
+	events.apply(logRecord, fanFailure, peer, agent(fanFailure), nil)
+}
+
+func TestSNMPTTHostNameNodes(t *testing.T) {
+	type testCase struct {
+		name     string
+		nodes    string
+		agent    string
+		expected bool
+	}
+
+	testCases := []testCase{
+		{name: "PositiveMatching", nodes: "NODES core-sw-01", agent: "192.0.2.10", expected: true},
+		{name: "PositiveOther", nodes: "NODES core-sw-01", agent: "192.0.2.11", expected: false},
+		{name: "PositiveUnresolved", nodes: "NODES core-sw-01", agent: "192.0.2.12", expected: false},
+		{name: "NegativeMatching", nodes: "NODES MODE=NEG\nNODES core-sw-01", agent: "192.0.2.10", expected: false},
+		{name: "NegativeOther", nodes: "NODES MODE=NEG\nNODES core-sw-01", agent: "192.0.2.11", expected: true},
+		{name: "NegativeUnresolved", nodes: "NODES MODE=NEG\nNODES core-sw-01", agent: "192.0.2.12", expected: true},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "snmptt.conf")
+			require.NoError(t, os.WriteFile(file, []byte("EVENT linkDown .1.3.6.1.6.3.1.1.5.3 Status Minor\n"+test.nodes+"\n"), 0o600))
+			events, err := newSNMPTTEvents(&Config{SNMPTTFiles: []string{file}})
+			require.NoError(t, err)
+
+			lookups := 0
+			events.names = newHostNames(func(_ context.Context, addr string) ([]string, error) {
+				lookups++
+				switch addr {
+				case "192.0.2.10":
+					return []string{"core-sw-01.example.net."}, nil
+				case "192.0.2.11":
+					return []string{"edge-rtr-01.example.net."}, nil
+				}
+				return nil, errors.New("no such host")
+			})
+
+			linkDown := &gosnmp.SnmpPacket{
+				Version:   gosnmp.Version2c,
+				Community: "public",
+				PDUType:   gosnmp.SNMPv2Trap,
+				Variables: []gosnmp.SnmpPDU{
+					{Name: oidSysUpTime, Type: gosnmp.TimeTicks, Value: uint32(100)},
+					{Name: oidSnmpTrapOID, Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.6.3.1.1.5.3"},
+				},
+			}
+			peer := &net.UDPAddr{IP: net.ParseIP(test.agent), Port: 1620}
+			local := &net.UDPAddr{IP: net.ParseIP("192.0.2.2"), Port: 162}
+
+			// The name of the agent is looked up once, whether or not it resolves
+			for i := 0; i < 2; i++ {
+				logRecord := trapToLogs(linkDown, peer, local, time.Now()).ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+				events.apply(logRecord, linkDown, peer, netip.MustParseAddr(test.agent), nil)
+				_, ok := logRecord.Attributes().Get(attributeSNMPTTEvent)
+				require.Equal(t, test.expected, ok)
+			}
+			require.Equal(t, 1, lookups)
+		})
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package snmptrapreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver"
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/receiver"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver/internal/metadata"
+)
+
+// Self-metrics of the receiver, on top of those of receiverhelper.ObsReport
+const (
+	metricRejectedNotifications = "snmptrap_rejected_notifications"
+	metricDeniedMessages        = "snmptrap_denied_messages"
+	metricMIBReloads            = "snmptrap_mib_reloads"
+	metricTemplateFailures      = "snmptrap_template_failures"
+
+	attributeReceiver = "receiver"
+	attributeReason   = "reason"
+	attributeResult   = "result"
+	attributeTrapOID  = "trap_oid"
+
+	// Reasons for rejecting a notification
+	reasonCommunity    = "community"
+	reasonAgentAddress = "agent_address"
+
+	// Results of reloading the MIBs
+	reloadSuccess = "success"
+	reloadFailure = "failure"
+)
+
+// receiverTelemetry records the self-metrics of a receiver
+type receiverTelemetry struct {
+	receiverAttribute     attribute.KeyValue
+	rejectedNotifications metric.Int64Counter
+	deniedMessages        metric.Int64Counter
+	mibReloads            metric.Int64Counter
+	templateFailures      metric.Int64Counter
+}
+
+// newReceiverTelemetry creates the self-metrics of a receiver
+func newReceiverTelemetry(settings receiver.CreateSettings) (*receiverTelemetry, error) {
+	meter := metadata.Meter(settings.TelemetrySettings)
+
+	rejectedNotifications, err := meter.Int64Counter(
+		metricRejectedNotifications,
+		metric.WithDescription("Number of traps and informs received and then rejected, by reason"),
+		metric.WithUnit("{notifications}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	deniedMessages, err := meter.Int64Counter(
+		metricDeniedMessages,
+		metric.WithDescription("Number of messages dropped before being decoded because their source address is denied"),
+		metric.WithUnit("{messages}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	mibReloads, err := meter.Int64Counter(
+		metricMIBReloads,
+		metric.WithDescription("Number of times the MIBs were reloaded after their files changed, by result"),
+		metric.WithUnit("{reloads}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	templateFailures, err := meter.Int64Counter(
+		metricTemplateFailures,
+		metric.WithDescription("Number of notifications whose message template failed to render, by trap OID"),
+		metric.WithUnit("{notifications}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &receiverTelemetry{
+		receiverAttribute:     attribute.String(attributeReceiver, settings.ID.String()),
+		rejectedNotifications: rejectedNotifications,
+		deniedMessages:        deniedMessages,
+		mibReloads:            mibReloads,
+		templateFailures:      templateFailures,
+	}, nil
+}
+
+// recordRejected counts a notification rejected for reason
+func (t *receiverTelemetry) recordRejected(ctx context.Context, reason string) {
+	t.rejectedNotifications.Add(ctx, 1, metric.WithAttributes(t.receiverAttribute, attribute.String(attributeReason, reason)))
+}
+
+// recordDenied counts a message dropped because of its source address
+func (t *receiverTelemetry) recordDenied(ctx context.Context) {
+	t.deniedMessages.Add(ctx, 1, metric.WithAttributes(t.receiverAttribute))
+}
+
+// recordMIBReload counts a reload of the MIBs with its result
+func (t *receiverTelemetry) recordMIBReload(ctx context.Context, result string) {
+	t.mibReloads.Add(ctx, 1, metric.WithAttributes(t.receiverAttribute, attribute.String(attributeResult, result)))
+}
+
+// recordTemplateFailure counts a notification whose message template, for trapOID, failed to render
+func (t *receiverTelemetry) recordTemplateFailure(ctx context.Context, trapOID string) {
+	t.templateFailures.Add(ctx, 1, metric.WithAttributes(t.receiverAttribute, attribute.String(attributeTrapOID, trapOID)))
+}
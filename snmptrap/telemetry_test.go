@@ -0,0 +1,70 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package snmptrapreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver"
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestReceiverTelemetry(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	settings := receivertest.NewNopCreateSettings()
+	settings.MeterProvider = sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	telemetry, err := newReceiverTelemetry(settings)
+	require.NoError(t, err)
+
+	telemetry.recordRejected(context.Background(), reasonCommunity)
+	telemetry.recordRejected(context.Background(), reasonCommunity)
+	telemetry.recordRejected(context.Background(), reasonAgentAddress)
+	telemetry.recordDenied(context.Background())
+	telemetry.recordMIBReload(context.Background(), reloadSuccess)
+	telemetry.recordMIBReload(context.Background(), reloadFailure)
+	telemetry.recordMIBReload(context.Background(), reloadFailure)
+	telemetry.recordTemplateFailure(context.Background(), "1.3.6.1.6.3.1.1.5.3")
+
+	require.EqualValues(t, 2, counterValue(t, reader, metricRejectedNotifications, reasonCommunity))
+	require.EqualValues(t, 1, counterValue(t, reader, metricRejectedNotifications, reasonAgentAddress))
+	require.EqualValues(t, 1, counterValue(t, reader, metricDeniedMessages, ""))
+	require.EqualValues(t, 1, counterValueWith(t, reader, metricMIBReloads, attributeResult, reloadSuccess))
+	require.EqualValues(t, 2, counterValueWith(t, reader, metricMIBReloads, attributeResult, reloadFailure))
+	require.EqualValues(t, 1, counterValueWith(t, reader, metricTemplateFailures, attributeTrapOID, "1.3.6.1.6.3.1.1.5.3"))
+}
+
+// counterValue returns the value of a counter recorded by the receiver, for the given
+// reason or, when it is empty, summed over all reasons
+func counterValue(t *testing.T, reader sdkmetric.Reader, name string, reason string) int64 {
+	return counterValueWith(t, reader, name, attributeReason, reason)
+}
+
+// counterValueWith returns the value of a counter recorded by the receiver, for the given
+// value of an attribute or, when it is empty, summed over all values
+func counterValueWith(t *testing.T, reader sdkmetric.Reader, name string, key string, value string) int64 {
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+
+	var total int64
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != name {
+				continue
+			}
+			for _, dp := range m.Data.(metricdata.Sum[int64]).DataPoints {
+				receiver, _ := dp.Attributes.Value(attributeReceiver)
+				require.Equal(t, receivertest.NewNopCreateSettings().ID.String(), receiver.AsString())
+				if actual, _ := dp.Attributes.Value(attribute.Key(key)); value == "" || actual.AsString() == value {
+					total += dp.Value
+				}
+			}
+		}
+	}
+	return total
+}
@@ -0,0 +1,424 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package snmptrapreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver"
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+const (
+	// maxUDPMessageSize is the largest payload of a UDP datagram
+	maxUDPMessageSize = 65507
+	// maxTCPMessageSize limits how much a single message on a TCP stream may claim
+	// to be, so that a bad length can't make us allocate without bound
+	maxTCPMessageSize = 1 << 20
+
+	transportTCP = "tcp"
+)
+
+var errMessageTooLarge = errors.New("SNMP message exceeds the maximum message size")
+
+// messageHandler is invoked for every SNMP message received by a trapListener.
+// The message is not reused by the listener once the handler returns. local is
+// the address of this host the message was sent to, and reply sends a message
+// back to the peer over the transport it arrived on.
+type messageHandler func(message []byte, peer net.Addr, local net.Addr, reply func([]byte) error)
+
+// trapListener receives SNMP messages on a single socket
+type trapListener interface {
+	// serve reads messages and passes them to the handler until close is called
+	serve()
+	// close stops the listener and closes any open connections
+	close() error
+	// localAddr returns the address the listener is bound to
+	localAddr() net.Addr
+}
+
+// tcpLimits bound the resources TCP peers can hold. A zero idleTimeout and a nil conns
+// disable them.
+type tcpLimits struct {
+	// idleTimeout is how long a connection may take to deliver its next message before it is closed
+	idleTimeout time.Duration
+	// conns caps the connections held open, which may be shared by several listeners
+	conns *connLimiter
+}
+
+// connLimiter caps the number of open connections, in total and per peer address. A limit of 0
+// disables it.
+type connLimiter struct {
+	maxConns   int
+	maxPerPeer int
+
+	mu    sync.Mutex
+	conns int
+	peers map[string]int
+}
+
+func newConnLimiter(maxConns int, maxPerPeer int) *connLimiter {
+	return &connLimiter{maxConns: maxConns, maxPerPeer: maxPerPeer, peers: map[string]int{}}
+}
+
+// acquire counts a connection from the peer, unless that would exceed a limit
+func (limiter *connLimiter) acquire(peer string) bool {
+	if limiter == nil {
+		return true
+	}
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+	if limiter.maxConns > 0 && limiter.conns >= limiter.maxConns {
+		return false
+	}
+	if limiter.maxPerPeer > 0 && limiter.peers[peer] >= limiter.maxPerPeer {
+		return false
+	}
+	limiter.conns++
+	limiter.peers[peer]++
+	return true
+}
+
+// release forgets a connection counted by acquire
+func (limiter *connLimiter) release(peer string) {
+	if limiter == nil {
+		return
+	}
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+	limiter.conns--
+	if limiter.peers[peer]--; limiter.peers[peer] <= 0 {
+		delete(limiter.peers, peer)
+	}
+}
+
+// newTrapListener binds a listener for the given listen address, which is expected
+// to have been validated by validateListenAddress. The limits only apply to TCP.
+func newTrapListener(listenAddress string, handler messageHandler, limits tcpLimits, logger *zap.Logger) (trapListener, error) {
+	u, err := url.Parse(listenAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	network := strings.ToLower(u.Scheme)
+	switch network {
+	case "udp", "udp4", "udp6":
+		conn, err := net.ListenPacket(network, u.Host)
+		if err != nil {
+			return nil, err
+		}
+		return newUDPListener(conn, handler, logger), nil
+	case "tcp", "tcp4", "tcp6":
+		ln, err := net.Listen(network, u.Host)
+		if err != nil {
+			return nil, err
+		}
+		return &tcpListener{ln: ln, handler: handler, limits: limits, logger: logger, conns: map[net.Conn]struct{}{}}, nil
+	default:
+		return nil, errListenAddressBadScheme
+	}
+}
+
+// udpListener receives one SNMP message per datagram
+type udpListener struct {
+	conn    net.PacketConn
+	handler messageHandler
+	logger  *zap.Logger
+
+	// When the platform supports it, ipv4 or ipv6 report the destination address
+	// of each datagram, which tells which interface a wildcard socket received it on
+	ipv4 *ipv4.PacketConn
+	ipv6 *ipv6.PacketConn
+}
+
+func newUDPListener(conn net.PacketConn, handler messageHandler, logger *zap.Logger) *udpListener {
+	l := &udpListener{conn: conn, handler: handler, logger: logger}
+
+	var err error
+	if ip, _ := splitAddr(conn.LocalAddr()); ip.To4() != nil {
+		l.ipv4 = ipv4.NewPacketConn(conn)
+		if err = l.ipv4.SetControlMessage(ipv4.FlagDst, true); err != nil {
+			l.ipv4 = nil
+		}
+	} else {
+		l.ipv6 = ipv6.NewPacketConn(conn)
+		if err = l.ipv6.SetControlMessage(ipv6.FlagDst, true); err != nil {
+			l.ipv6 = nil
+		}
+	}
+	if err != nil {
+		logger.Debug("Destination addresses are not available, using the listen address as the local address", zap.Error(err))
+	}
+
+	return l
+}
+
+func (l *udpListener) serve() {
+	buf := make([]byte, maxUDPMessageSize)
+	for {
+		n, peer, dst, err := l.readFrom(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			l.logger.Debug("Failed to read UDP datagram", zap.Error(err))
+			continue
+		}
+
+		// The decoded packet may reference the message, so it can't share the read buffer
+		message := make([]byte, n)
+		copy(message, buf[:n])
+
+		local := l.conn.LocalAddr()
+		if dst != nil && !dst.IsUnspecified() {
+			_, port := splitAddr(local)
+			local = &net.UDPAddr{IP: dst, Port: port}
+		}
+
+		l.handler(message, normalizeAddr(peer), normalizeAddr(local), func(response []byte) error {
+			_, err := l.conn.WriteTo(response, peer)
+			return err
+		})
+	}
+}
+
+// readFrom reads a datagram along with its destination address, if known
+func (l *udpListener) readFrom(buf []byte) (int, net.Addr, net.IP, error) {
+	switch {
+	case l.ipv4 != nil:
+		n, cm, peer, err := l.ipv4.ReadFrom(buf)
+		if cm == nil {
+			return n, peer, nil, err
+		}
+		return n, peer, cm.Dst, err
+	case l.ipv6 != nil:
+		n, cm, peer, err := l.ipv6.ReadFrom(buf)
+		if cm == nil {
+			return n, peer, nil, err
+		}
+		return n, peer, cm.Dst, err
+	default:
+		n, peer, err := l.conn.ReadFrom(buf)
+		return n, peer, nil, err
+	}
+}
+
+func (l *udpListener) close() error {
+	return l.conn.Close()
+}
+
+func (l *udpListener) localAddr() net.Addr {
+	return l.conn.LocalAddr()
+}
+
+// tcpListener receives SNMP messages over TCP as described by RFC 3430. Each
+// connection carries a stream of BER encoded messages without any extra framing.
+type tcpListener struct {
+	ln      net.Listener
+	handler messageHandler
+	limits  tcpLimits
+	logger  *zap.Logger
+
+	mu     sync.Mutex
+	conns  map[net.Conn]struct{}
+	closed bool
+	wg     sync.WaitGroup
+}
+
+func (l *tcpListener) serve() {
+	for {
+		conn, err := l.ln.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				break
+			}
+			l.logger.Debug("Failed to accept TCP connection", zap.Error(err))
+			continue
+		}
+
+		peer := peerKey(conn.RemoteAddr())
+		if !l.limits.conns.acquire(peer) {
+			l.logger.Debug("Refusing TCP connection over the connection limits", zap.Stringer("peer", conn.RemoteAddr()))
+			conn.Close()
+			continue
+		}
+
+		l.mu.Lock()
+		if l.closed {
+			l.mu.Unlock()
+			l.limits.conns.release(peer)
+			conn.Close()
+			break
+		}
+		l.conns[conn] = struct{}{}
+		l.wg.Add(1)
+		l.mu.Unlock()
+
+		go l.serveConn(conn, peer)
+	}
+	l.wg.Wait()
+}
+
+// serveConn reads messages from a single connection until the peer closes it, or takes longer
+// than the idle timeout to send the next one
+func (l *tcpListener) serveConn(conn net.Conn, key string) {
+	defer func() {
+		l.mu.Lock()
+		delete(l.conns, conn)
+		l.mu.Unlock()
+		conn.Close()
+		l.limits.conns.release(key)
+		l.wg.Done()
+	}()
+
+	peer := normalizeAddr(conn.RemoteAddr())
+	local := normalizeAddr(conn.LocalAddr())
+	reader := bufio.NewReader(conn)
+	var writeMu sync.Mutex
+	reply := func(response []byte) error {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		_, err := conn.Write(response)
+		return err
+	}
+
+	for {
+		// The whole message must arrive in time, so a peer can't hold the connection by trickling it
+		if l.limits.idleTimeout > 0 {
+			if err := conn.SetReadDeadline(time.Now().Add(l.limits.idleTimeout)); err != nil {
+				return
+			}
+		}
+		message, err := readBERMessage(reader, maxTCPMessageSize)
+		if err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				l.logger.Debug("Closing TCP connection", zap.Stringer("peer", peer), zap.Error(err))
+			}
+			return
+		}
+		l.handler(message, peer, local, reply)
+	}
+}
+
+func (l *tcpListener) close() error {
+	l.mu.Lock()
+	l.closed = true
+	for conn := range l.conns {
+		conn.Close()
+	}
+	l.mu.Unlock()
+	return l.ln.Close()
+}
+
+func (l *tcpListener) localAddr() net.Addr {
+	return l.ln.Addr()
+}
+
+// readBERMessage reads one complete BER encoded SNMP message, a SEQUENCE with a
+// definite length, from the stream
+func readBERMessage(reader *bufio.Reader, maxSize int) ([]byte, error) {
+	tag, err := reader.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	if tag != 0x30 {
+		return nil, fmt.Errorf("expected a SEQUENCE at the start of an SNMP message, got tag 0x%02x", tag)
+	}
+
+	header := []byte{tag}
+	first, err := reader.ReadByte()
+	if err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	header = append(header, first)
+
+	length := int(first)
+	if first&0x80 != 0 {
+		// Long form, the low bits give the number of length octets that follow
+		octets := int(first & 0x7f)
+		if octets == 0 || octets > 4 {
+			return nil, fmt.Errorf("unsupported BER length encoding 0x%02x", first)
+		}
+		length = 0
+		for i := 0; i < octets; i++ {
+			b, err := reader.ReadByte()
+			if err != nil {
+				return nil, unexpectedEOF(err)
+			}
+			header = append(header, b)
+			length = length<<8 | int(b)
+		}
+	}
+	if length > maxSize {
+		return nil, errMessageTooLarge
+	}
+
+	message := make([]byte, len(header)+length)
+	copy(message, header)
+	if _, err := io.ReadFull(reader, message[len(header):]); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	return message, nil
+}
+
+// peerKey returns the address connections from a peer are counted by
+func peerKey(addr net.Addr) string {
+	if ip, _ := splitAddr(normalizeAddr(addr)); ip != nil {
+		return ip.String()
+	}
+	return addr.String()
+}
+
+// unexpectedEOF turns an EOF in the middle of a message into io.ErrUnexpectedEOF
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// normalizeAddr unmaps IPv4 addresses received on a dual-stack IPv6 socket so
+// that a peer is recorded the same way whichever socket it used
+func normalizeAddr(addr net.Addr) net.Addr {
+	switch addr := addr.(type) {
+	case *net.UDPAddr:
+		if ip4 := addr.IP.To4(); ip4 != nil {
+			return &net.UDPAddr{IP: ip4, Port: addr.Port}
+		}
+	case *net.TCPAddr:
+		if ip4 := addr.IP.To4(); ip4 != nil {
+			return &net.TCPAddr{IP: ip4, Port: addr.Port}
+		}
+	}
+	return addr
+}
+
+// addrTransport returns the name of the transport of a UDP or TCP address
+func addrTransport(addr net.Addr) string {
+	if _, ok := addr.(*net.TCPAddr); ok {
+		return transportTCP
+	}
+	return transportUDP
+}
+
+// splitAddr returns the IP and port of a UDP or TCP address
+func splitAddr(addr net.Addr) (net.IP, int) {
+	switch addr := addr.(type) {
+	case *net.UDPAddr:
+		return addr.IP, addr.Port
+	case *net.TCPAddr:
+		return addr.IP, addr.Port
+	default:
+		return nil, 0
+	}
+}
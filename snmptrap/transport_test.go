@@ -0,0 +1,307 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package snmptrapreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver"
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+	"go.uber.org/zap"
+)
+
+func TestReadBERMessage(t *testing.T) {
+	long := append([]byte{0x30, 0x82, 0x01, 0x00}, bytes.Repeat([]byte{0x04}, 256)...)
+
+	type testCase struct {
+		name        string
+		stream      []byte
+		maxSize     int
+		expected    [][]byte
+		expectedErr error
+	}
+
+	testCases := []testCase{
+		{
+			name:     "ShortForm",
+			stream:   []byte{0x30, 0x03, 0x02, 0x01, 0x01},
+			maxSize:  maxTCPMessageSize,
+			expected: [][]byte{{0x30, 0x03, 0x02, 0x01, 0x01}},
+		},
+		{
+			name:     "LongForm",
+			stream:   long,
+			maxSize:  maxTCPMessageSize,
+			expected: [][]byte{long},
+		},
+		{
+			name:     "BackToBack",
+			stream:   []byte{0x30, 0x01, 0x05, 0x30, 0x02, 0x05, 0x00},
+			maxSize:  maxTCPMessageSize,
+			expected: [][]byte{{0x30, 0x01, 0x05}, {0x30, 0x02, 0x05, 0x00}},
+		},
+		{
+			name:        "Truncated",
+			stream:      []byte{0x30, 0x05, 0x02, 0x01},
+			maxSize:     maxTCPMessageSize,
+			expectedErr: io.ErrUnexpectedEOF,
+		},
+		{
+			name:        "TooLarge",
+			stream:      long,
+			maxSize:     255,
+			expectedErr: errMessageTooLarge,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			reader := bufio.NewReader(bytes.NewReader(test.stream))
+			for _, expected := range test.expected {
+				message, err := readBERMessage(reader, test.maxSize)
+				require.NoError(t, err)
+				require.Equal(t, expected, message)
+			}
+
+			_, err := readBERMessage(reader, test.maxSize)
+			if test.expectedErr == nil {
+				require.ErrorIs(t, err, io.EOF)
+			} else {
+				require.ErrorIs(t, err, test.expectedErr)
+			}
+		})
+	}
+
+	t.Run("BadTag", func(t *testing.T) {
+		_, err := readBERMessage(bufio.NewReader(bytes.NewReader([]byte{0x04, 0x00})), maxTCPMessageSize)
+		require.ErrorContains(t, err, "expected a SEQUENCE")
+	})
+}
+
+// A message split over several writes must be reassembled before it is handled
+func TestTCPListenerSplitWrites(t *testing.T) {
+	received := make(chan []byte, 2)
+	listener, err := newTrapListener("tcp://127.0.0.1:0", func(message []byte, _ net.Addr, _ net.Addr, _ func([]byte) error) {
+		received <- message
+	}, tcpLimits{}, zap.NewNop())
+	require.NoError(t, err)
+	go listener.serve()
+	defer func() {
+		require.NoError(t, listener.close())
+	}()
+
+	conn, err := net.Dial("tcp", listener.localAddr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	for _, chunk := range [][]byte{{0x30}, {0x03, 0x02}, {0x01, 0x01, 0x30, 0x00}} {
+		_, err = conn.Write(chunk)
+		require.NoError(t, err)
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	for _, expected := range [][]byte{{0x30, 0x03, 0x02, 0x01, 0x01}, {0x30, 0x00}} {
+		select {
+		case message := <-received:
+			require.Equal(t, expected, message)
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for message")
+		}
+	}
+}
+
+// A connection which doesn't send a message within the idle timeout must be closed
+func TestTCPListenerIdleTimeout(t *testing.T) {
+	listener, err := newTrapListener("tcp://127.0.0.1:0", func([]byte, net.Addr, net.Addr, func([]byte) error) {}, tcpLimits{idleTimeout: 100 * time.Millisecond}, zap.NewNop())
+	require.NoError(t, err)
+	go listener.serve()
+	defer func() {
+		require.NoError(t, listener.close())
+	}()
+
+	for _, stream := range [][]byte{nil, {0x30, 0x05, 0x02}} {
+		conn, err := net.Dial("tcp", listener.localAddr().String())
+		require.NoError(t, err)
+		defer conn.Close()
+		// A message trickled in more slowly than the timeout doesn't keep the connection open either
+		_, err = conn.Write(stream)
+		require.NoError(t, err)
+
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+		_, err = conn.Read(make([]byte, 1))
+		require.ErrorIs(t, err, io.EOF)
+	}
+}
+
+// Connections over the limits must be closed as soon as they are accepted
+func TestTCPListenerConnectionLimits(t *testing.T) {
+	type testCase struct {
+		name       string
+		maxConns   int
+		maxPerPeer int
+		accepted   int
+	}
+
+	testCases := []testCase{
+		{name: "Total", maxConns: 2, accepted: 2},
+		{name: "PerPeer", maxConns: 10, maxPerPeer: 1, accepted: 1},
+		{name: "Unlimited", accepted: 3},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			received := make(chan []byte, 3)
+			limits := tcpLimits{conns: newConnLimiter(test.maxConns, test.maxPerPeer)}
+			listener, err := newTrapListener("tcp://127.0.0.1:0", func(message []byte, _ net.Addr, _ net.Addr, _ func([]byte) error) {
+				received <- message
+			}, limits, zap.NewNop())
+			require.NoError(t, err)
+			go listener.serve()
+			defer func() {
+				require.NoError(t, listener.close())
+			}()
+
+			// Each connection sends a message once all of them are open
+			var conns []net.Conn
+			for i := 0; i < 3; i++ {
+				conn, err := net.Dial("tcp", listener.localAddr().String())
+				require.NoError(t, err)
+				defer conn.Close()
+				conns = append(conns, conn)
+			}
+			time.Sleep(100 * time.Millisecond)
+			for _, conn := range conns {
+				_, _ = conn.Write([]byte{0x30, 0x00})
+			}
+
+			for i := 0; i < test.accepted; i++ {
+				select {
+				case <-received:
+				case <-time.After(5 * time.Second):
+					t.Fatalf("timed out waiting for message %d", i+1)
+				}
+			}
+			select {
+			case <-received:
+				t.Fatal("received a message over a connection beyond the limits")
+			case <-time.After(200 * time.Millisecond):
+			}
+		})
+	}
+}
+
+func TestNormalizeAddr(t *testing.T) {
+	mapped := &net.UDPAddr{IP: net.ParseIP("::ffff:192.0.2.1"), Port: 162}
+	require.Equal(t, &net.UDPAddr{IP: net.IP{192, 0, 2, 1}, Port: 162}, normalizeAddr(mapped))
+
+	mappedTCP := &net.TCPAddr{IP: net.ParseIP("::ffff:192.0.2.1"), Port: 162}
+	require.Equal(t, &net.TCPAddr{IP: net.IP{192, 0, 2, 1}, Port: 162}, normalizeAddr(mappedTCP))
+
+	v6 := &net.UDPAddr{IP: net.ParseIP("2001:db8::1"), Port: 162}
+	require.Equal(t, v6, normalizeAddr(v6))
+}
+
+// Traps and informs must be received the same way over every transport scheme
+func TestReceiveTrapTransports(t *testing.T) {
+	type testCase struct {
+		name          string
+		scheme        string
+		listenHost    string
+		targetHost    string
+		transport     string
+		expectedPeer  string
+		requiresIPv6  bool
+		sendAsInform  bool
+		clientNetwork string
+	}
+
+	testCases := []testCase{
+		{name: "UDP4", scheme: "udp4", listenHost: "127.0.0.1", targetHost: "127.0.0.1", transport: "ip_udp", expectedPeer: "127.0.0.1", clientNetwork: "udp"},
+		{name: "UDP6", scheme: "udp6", listenHost: "[::1]", targetHost: "::1", transport: "ip_udp", expectedPeer: "::1", requiresIPv6: true, clientNetwork: "udp"},
+		{name: "UDPDualStack", scheme: "udp", listenHost: "[::]", targetHost: "127.0.0.1", transport: "ip_udp", expectedPeer: "127.0.0.1", requiresIPv6: true, clientNetwork: "udp"},
+		{name: "UDPInform", scheme: "udp", listenHost: "127.0.0.1", targetHost: "127.0.0.1", transport: "ip_udp", expectedPeer: "127.0.0.1", sendAsInform: true, clientNetwork: "udp"},
+		{name: "TCP", scheme: "tcp", listenHost: "127.0.0.1", targetHost: "127.0.0.1", transport: "ip_tcp", expectedPeer: "127.0.0.1", clientNetwork: "tcp"},
+		{name: "TCP4", scheme: "tcp4", listenHost: "127.0.0.1", targetHost: "127.0.0.1", transport: "ip_tcp", expectedPeer: "127.0.0.1", clientNetwork: "tcp"},
+		{name: "TCP6", scheme: "tcp6", listenHost: "[::1]", targetHost: "::1", transport: "ip_tcp", expectedPeer: "::1", requiresIPv6: true, clientNetwork: "tcp"},
+		{name: "TCPDualStack", scheme: "tcp", listenHost: "[::]", targetHost: "127.0.0.1", transport: "ip_tcp", expectedPeer: "127.0.0.1", requiresIPv6: true, clientNetwork: "tcp"},
+		{name: "TCPInform", scheme: "tcp", listenHost: "127.0.0.1", targetHost: "127.0.0.1", transport: "ip_tcp", expectedPeer: "127.0.0.1", sendAsInform: true, clientNetwork: "tcp"},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			if test.requiresIPv6 {
+				conn, err := net.ListenPacket("udp6", "[::1]:0")
+				if err != nil {
+					t.Skip("IPv6 is not available")
+				}
+				conn.Close()
+			}
+
+			cfg := createDefaultConfig().(*Config)
+			cfg.ListenAddress = test.scheme + "://" + test.listenHost + ":0"
+
+			sink := new(consumertest.LogsSink)
+			rcvr, err := newSnmptrapReceiver(receivertest.NewNopCreateSettings(), cfg, sink)
+			require.NoError(t, err)
+			require.NoError(t, rcvr.Start(context.Background(), componenttest.NewNopHost()))
+			defer func() {
+				require.NoError(t, rcvr.Shutdown(context.Background()))
+			}()
+
+			_, port := splitAddr(rcvr.listeners[0].localAddr())
+			client := &gosnmp.GoSNMP{
+				Target:    test.targetHost,
+				Port:      uint16(port),
+				Transport: test.clientNetwork,
+				Community: "public",
+				Version:   gosnmp.Version2c,
+				Timeout:   2 * time.Second,
+				MaxOids:   gosnmp.MaxOids,
+			}
+			require.NoError(t, client.Connect())
+			defer client.Conn.Close()
+
+			_, err = client.SendTrap(gosnmp.SnmpTrap{
+				Variables: []gosnmp.SnmpPDU{
+					{Name: ".1.3.6.1.2.1.1.3.0", Type: gosnmp.TimeTicks, Value: uint32(100)},
+					{Name: ".1.3.6.1.6.3.1.1.4.1.0", Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.6.3.1.1.5.1"},
+				},
+				IsInform: test.sendAsInform,
+			})
+			// For informs, a nil error means the receiver acknowledged it
+			require.NoError(t, err)
+
+			require.Eventually(t, func() bool {
+				return sink.LogRecordCount() == 1
+			}, 5*time.Second, 10*time.Millisecond)
+
+			logRecord := sink.AllLogs()[0].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+			requireAttribute(t, logRecord, attributeNetTransport, test.transport)
+			requireAttribute(t, logRecord, attributeEventName, "coldStart")
+			requireAttribute(t, logRecord, attributeNetSockPeerAddr, test.expectedPeer)
+			// The local address is the one the trap was sent to, even on a wildcard socket
+			requireAttribute(t, logRecord, attributeNetSockHostAddr, test.expectedPeer)
+			_, localPort := splitAddr(client.Conn.LocalAddr())
+			peerPort, ok := logRecord.Attributes().Get(attributeNetSockPeerPort)
+			require.True(t, ok)
+			require.Equal(t, int64(localPort), peerPort.Int())
+		})
+	}
+}
+
+func requireAttribute(t *testing.T, logRecord plog.LogRecord, key string, expected string) {
+	value, ok := logRecord.Attributes().Get(key)
+	require.True(t, ok, "missing attribute %s", key)
+	require.Equal(t, expected, value.Str())
+}
@@ -0,0 +1,126 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package snmptrapreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver"
+
+import (
+	"sync"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// maxLocalizedKeys bounds the number of localized keys kept by a user table. Users
+// without an engine ID accept any engine, so the engine IDs come from the network.
+const maxLocalizedKeys = 1024
+
+// usmUser is an entry of the USM user table
+type usmUser struct {
+	// engineID is the authoritative engine ID the user is restricted to, or empty for any engine
+	engineID string
+	msgFlags gosnmp.SnmpV3MsgFlags
+	// securityParameters holds the protocols and passphrases, without localized keys
+	securityParameters *gosnmp.UsmSecurityParameters
+}
+
+// localizedKeyID identifies the keys of a user localized for an engine
+type localizedKeyID struct {
+	user     *usmUser
+	engineID string
+}
+
+// usmUsers is the table of SNMPv3 users of a listener. Keys are localized with the
+// authoritative engine ID of each message (RFC 3414 section 2.6), which is expensive,
+// so they are cached per engine.
+type usmUsers struct {
+	users map[string][]*usmUser
+
+	mu   sync.Mutex
+	keys map[localizedKeyID]*gosnmp.UsmSecurityParameters
+}
+
+// newUSMUsers creates the user table of a v3 listener from its user and the users table
+func newUSMUsers(cfg *Config) (*usmUsers, error) {
+	table := &usmUsers{
+		users: map[string][]*usmUser{},
+		keys:  map[localizedKeyID]*gosnmp.UsmSecurityParameters{},
+	}
+
+	if cfg.User != "" {
+		table.add(cfg, "")
+	}
+	for _, userCfg := range cfg.Users {
+		engineID, err := parseEngineID(userCfg.EngineID)
+		if err != nil {
+			return nil, err
+		}
+		table.add(userCfg.config(), engineID)
+	}
+
+	return table, nil
+}
+
+// add adds a user, taking its security level and protocols from cfg the same way the polling client does
+func (t *usmUsers) add(cfg *Config, engineID string) {
+	unmarshaller := newUnmarshaller(cfg)
+	securityParameters := unmarshaller.SecurityParameters.(*gosnmp.UsmSecurityParameters)
+	t.users[cfg.User] = append(t.users[cfg.User], &usmUser{
+		engineID:           engineID,
+		msgFlags:           unmarshaller.MsgFlags,
+		securityParameters: securityParameters,
+	})
+}
+
+// lookup returns the user a message from userName with the given authoritative engine ID
+// is checked against. Users restricted to the engine take precedence over those that
+// aren't, and nil is returned when there is no such user.
+func (t *usmUsers) lookup(userName string, engineID string) *usmUser {
+	var anyEngine *usmUser
+	for _, user := range t.users[userName] {
+		if user.engineID == engineID {
+			return user
+		}
+		if user.engineID == "" && anyEngine == nil {
+			anyEngine = user
+		}
+	}
+	return anyEngine
+}
+
+// localize returns the security parameters of user with its keys localized for engineID
+func (t *usmUsers) localize(user *usmUser, engineID string) (*gosnmp.UsmSecurityParameters, error) {
+	id := localizedKeyID{user: user, engineID: engineID}
+
+	t.mu.Lock()
+	securityParameters, ok := t.keys[id]
+	t.mu.Unlock()
+
+	if !ok {
+		securityParameters = user.securityParameters.Copy().(*gosnmp.UsmSecurityParameters)
+		securityParameters.AuthoritativeEngineID = engineID
+		if err := securityParameters.InitSecurityKeys(); err != nil {
+			return nil, err
+		}
+
+		t.mu.Lock()
+		if len(t.keys) >= maxLocalizedKeys {
+			t.keys = map[localizedKeyID]*gosnmp.UsmSecurityParameters{}
+		}
+		t.keys[id] = securityParameters
+		t.mu.Unlock()
+	}
+
+	// gosnmp stores the fields of the message in its security parameters
+	return securityParameters.Copy().(*gosnmp.UsmSecurityParameters), nil
+}
+
+// checkSecurity checks the security level of a message against that of the user.
+// gosnmp accepts any digest that the expected digest starts with, so its length is checked too.
+func (u *usmUser) checkSecurity(header *v3Header) error {
+	if header.msgFlags&gosnmp.AuthPriv < u.msgFlags&gosnmp.AuthPriv {
+		return errUnsupportedSecurityLevel
+	}
+	if header.msgFlags&gosnmp.AuthNoPriv != 0 && len(header.authParameters) != digestLengths[u.securityParameters.AuthenticationProtocol] {
+		return errWrongDigest
+	}
+	return nil
+}
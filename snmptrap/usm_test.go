@@ -0,0 +1,172 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package snmptrapreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver"
+
+import (
+	"context"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+)
+
+const (
+	testVendorAEngineID = "8000000001020304"
+	testVendorBEngineID = "80000009030000c0ffee01"
+)
+
+func TestUSMUsersLookup(t *testing.T) {
+	users, err := newUSMUsers(&Config{
+		Version:       "v3",
+		User:          "otel",
+		SecurityLevel: "no_auth_no_priv",
+		Users: []UserConfig{
+			{User: "otel", EngineID: testVendorAEngineID, SecurityLevel: "auth_no_priv", AuthType: "SHA", AuthPassword: "authpassword"},
+			{User: "noc", EngineID: testVendorBEngineID, SecurityLevel: "auth_no_priv", AuthPassword: "authpassword"},
+		},
+	})
+	require.NoError(t, err)
+
+	vendorA := mustDecodeEngineID(t, testVendorAEngineID)
+	vendorB := mustDecodeEngineID(t, testVendorBEngineID)
+
+	// The user restricted to the engine is used before the one accepted from any engine
+	user := users.lookup("otel", vendorA)
+	require.NotNil(t, user)
+	require.Equal(t, vendorA, user.engineID)
+	require.Equal(t, gosnmp.AuthNoPriv, user.msgFlags)
+	require.Equal(t, gosnmp.SHA, user.securityParameters.AuthenticationProtocol)
+
+	user = users.lookup("otel", vendorB)
+	require.NotNil(t, user)
+	require.Empty(t, user.engineID)
+	require.Equal(t, gosnmp.NoAuthNoPriv, user.msgFlags)
+
+	// Protocols left empty take the defaults
+	user = users.lookup("noc", vendorB)
+	require.NotNil(t, user)
+	require.Equal(t, gosnmp.MD5, user.securityParameters.AuthenticationProtocol)
+
+	require.Nil(t, users.lookup("noc", vendorA))
+	require.Nil(t, users.lookup("other", vendorA))
+}
+
+func TestUSMUsersLocalize(t *testing.T) {
+	users, err := newUSMUsers(&Config{
+		Version: "v3",
+		Users: []UserConfig{
+			{User: "otel", SecurityLevel: "auth_priv", AuthType: "SHA", AuthPassword: "authpassword", PrivacyType: "AES", PrivacyPassword: "privpassword"},
+		},
+	})
+	require.NoError(t, err)
+	user := users.lookup("otel", "")
+	require.NotNil(t, user)
+
+	vendorA := mustDecodeEngineID(t, testVendorAEngineID)
+	vendorB := mustDecodeEngineID(t, testVendorBEngineID)
+
+	first, err := users.localize(user, vendorA)
+	require.NoError(t, err)
+	require.Equal(t, vendorA, first.AuthoritativeEngineID)
+	require.NotEmpty(t, first.SecretKey)
+	require.NotEmpty(t, first.PrivacyKey)
+
+	// The keys of an engine are only localized once
+	again, err := users.localize(user, vendorA)
+	require.NoError(t, err)
+	require.Same(t, &first.SecretKey[0], &again.SecretKey[0])
+	require.Len(t, users.keys, 1)
+
+	other, err := users.localize(user, vendorB)
+	require.NoError(t, err)
+	require.NotEqual(t, first.SecretKey, other.SecretKey)
+	require.Len(t, users.keys, 2)
+
+	// The user itself is left without keys
+	require.Empty(t, user.securityParameters.SecretKey)
+}
+
+// Devices of several vendors send v3 traps to one listener, each with its own users
+func TestReceiveV3TrapsFromSeveralUsers(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.ListenAddress = "udp://127.0.0.1:0"
+	cfg.Version = "v3"
+	cfg.Users = []UserConfig{
+		{User: "vendor-a", EngineID: testVendorAEngineID, SecurityLevel: "auth_priv", AuthType: "SHA256", AuthPassword: "authpassword-a", PrivacyType: "AES", PrivacyPassword: "privpassword-a"},
+		{User: "vendor-b", EngineID: testVendorBEngineID, SecurityLevel: "auth_no_priv", AuthType: "SHA", AuthPassword: "authpassword-b"},
+		{User: "shared", SecurityLevel: "auth_no_priv", AuthType: "MD5", AuthPassword: "authpassword-shared"},
+	}
+	require.NoError(t, cfg.Validate())
+
+	sink := new(consumertest.LogsSink)
+	rcvr, err := newSnmptrapReceiver(receivertest.NewNopCreateSettings(), cfg, sink)
+	require.NoError(t, err)
+	require.NoError(t, rcvr.Start(context.Background(), componenttest.NewNopHost()))
+	t.Cleanup(func() {
+		require.NoError(t, rcvr.Shutdown(context.Background()))
+	})
+
+	type testCase struct {
+		name           string
+		engineID       string
+		msgFlags       gosnmp.SnmpV3MsgFlags
+		userName       string
+		authProtocol   gosnmp.SnmpV3AuthProtocol
+		authPassphrase string
+		privProtocol   gosnmp.SnmpV3PrivProtocol
+		privPassphrase string
+		accepted       bool
+	}
+
+	testCases := []testCase{
+		{name: "VendorA", engineID: testVendorAEngineID, msgFlags: gosnmp.AuthPriv, userName: "vendor-a", authProtocol: gosnmp.SHA256, authPassphrase: "authpassword-a", privProtocol: gosnmp.AES, privPassphrase: "privpassword-a", accepted: true},
+		{name: "VendorB", engineID: testVendorBEngineID, msgFlags: gosnmp.AuthNoPriv, userName: "vendor-b", authProtocol: gosnmp.SHA, authPassphrase: "authpassword-b", privProtocol: gosnmp.NoPriv, accepted: true},
+		{name: "SharedFromVendorA", engineID: testVendorAEngineID, msgFlags: gosnmp.AuthNoPriv, userName: "shared", authProtocol: gosnmp.MD5, authPassphrase: "authpassword-shared", privProtocol: gosnmp.NoPriv, accepted: true},
+		{name: "SharedFromVendorB", engineID: testVendorBEngineID, msgFlags: gosnmp.AuthNoPriv, userName: "shared", authProtocol: gosnmp.MD5, authPassphrase: "authpassword-shared", privProtocol: gosnmp.NoPriv, accepted: true},
+		{name: "VendorBUserFromVendorA", engineID: testVendorAEngineID, msgFlags: gosnmp.AuthNoPriv, userName: "vendor-b", authProtocol: gosnmp.SHA, authPassphrase: "authpassword-b", privProtocol: gosnmp.NoPriv},
+		{name: "WrongPassword", engineID: testVendorAEngineID, msgFlags: gosnmp.AuthNoPriv, userName: "shared", authProtocol: gosnmp.MD5, authPassphrase: "authpassword-a", privProtocol: gosnmp.NoPriv},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			sink.Reset()
+
+			client := newV3Client(t, rcvr, test.msgFlags, &gosnmp.UsmSecurityParameters{
+				UserName:                 test.userName,
+				AuthoritativeEngineID:    mustDecodeEngineID(t, test.engineID),
+				AuthenticationProtocol:   test.authProtocol,
+				AuthenticationPassphrase: test.authPassphrase,
+				PrivacyProtocol:          test.privProtocol,
+				PrivacyPassphrase:        test.privPassphrase,
+			})
+			_, err := client.SendTrap(testNotification)
+			require.NoError(t, err)
+
+			if !test.accepted {
+				time.Sleep(100 * time.Millisecond)
+				require.Zero(t, sink.LogRecordCount())
+				return
+			}
+			require.Eventually(t, func() bool {
+				return sink.LogRecordCount() == 1
+			}, 5*time.Second, 10*time.Millisecond)
+
+			body := sink.AllLogs()[0].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Body().Map()
+			user, ok := body.Get("user")
+			require.True(t, ok)
+			require.Equal(t, test.userName, user.Str())
+		})
+	}
+}
+
+func mustDecodeEngineID(t *testing.T, engineID string) string {
+	id, err := hex.DecodeString(engineID)
+	require.NoError(t, err)
+	return string(id)
+}
@@ -0,0 +1,59 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package snmptrapreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver"
+
+import (
+	"github.com/gosnmp/gosnmp"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver/internal/pen"
+)
+
+// attributeVendor holds the organization which the Private Enterprise Number of a notification
+// is assigned to, so that traps can be routed even when no MIB describes them
+const attributeVendor = "vendor"
+
+// loadEnterpriseNumbers loads the registry of the enterprise_numbers file, or returns the
+// embedded one when there is none. A file which can't be loaded is logged, and the embedded
+// registry is used instead.
+func loadEnterpriseNumbers(cfg *Config, logger *zap.Logger) *pen.Registry {
+	if cfg.EnterpriseNumbers == "" {
+		return pen.Embedded()
+	}
+	registry, err := pen.Load(cfg.EnterpriseNumbers)
+	if err != nil {
+		logger.Warn("The enterprise numbers could not be loaded, the embedded ones are used", zap.Error(err))
+		return pen.Embedded()
+	}
+	return registry
+}
+
+// putVendor records the vendor of a notification from the enterprise number of the OID which
+// identifies it: the enterprise of v1 traps, or snmpTrapEnterprise.0 when an SNMPv2 notification
+// carries it, and otherwise the snmpTrapOID. The enterprise comes first since the snmpTrapOID
+// of the generic traps isn't under any enterprise.
+func putVendor(attributes pcommon.Map, packet *gosnmp.SnmpPacket, registry *pen.Registry) {
+	for _, oid := range []string{trapEnterprise(packet), trapOID(packet)} {
+		if vendor, ok := registry.Vendor(oid); ok {
+			attributes.PutStr(attributeVendor, vendor)
+			return
+		}
+	}
+}
+
+// trapEnterprise returns the enterprise of a v1 trap, or the snmpTrapEnterprise.0 varbind of
+// other notifications. An empty string is returned when there is none.
+func trapEnterprise(packet *gosnmp.SnmpPacket) string {
+	if packet.Version == gosnmp.Version1 {
+		return packet.Enterprise
+	}
+	for _, variable := range packet.Variables {
+		if variable.Name == oidSnmpTrapEnterprise {
+			oid, _ := variable.Value.(string)
+			return oid
+		}
+	}
+	return ""
+}
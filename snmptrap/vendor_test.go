@@ -0,0 +1,90 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package snmptrapreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver"
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/snmptrapreceiver/internal/pen"
+)
+
+func TestPutVendor(t *testing.T) {
+	v2 := func(trapOID string, variables ...gosnmp.SnmpPDU) *gosnmp.SnmpPacket {
+		return &gosnmp.SnmpPacket{
+			Version: gosnmp.Version2c,
+			PDUType: gosnmp.SNMPv2Trap,
+			Variables: append([]gosnmp.SnmpPDU{
+				{Name: oidSysUpTime, Type: gosnmp.TimeTicks, Value: uint32(100)},
+				{Name: oidSnmpTrapOID, Type: gosnmp.ObjectIdentifier, Value: trapOID},
+			}, variables...),
+		}
+	}
+	v1 := func(enterprise string, generic int) *gosnmp.SnmpPacket {
+		return &gosnmp.SnmpPacket{
+			Version:  gosnmp.Version1,
+			PDUType:  gosnmp.Trap,
+			SnmpTrap: gosnmp.SnmpTrap{Enterprise: enterprise, GenericTrap: generic, SpecificTrap: 1},
+		}
+	}
+
+	type testCase struct {
+		name     string
+		packet   *gosnmp.SnmpPacket
+		expected string
+	}
+
+	testCases := []testCase{
+		{name: "V2TrapOID", packet: v2(".1.3.6.1.4.1.9.9.41.2.0.1"), expected: "ciscoSystems"},
+		{
+			// The enterprise of a generic trap tells who sent it
+			name:     "V2GenericTrapEnterprise",
+			packet:   v2(".1.3.6.1.6.3.1.1.5.3", gosnmp.SnmpPDU{Name: oidSnmpTrapEnterprise, Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.4.1.2636.1.1.1.2.29"}),
+			expected: "Juniper Networks, Inc.",
+		},
+		{name: "V2GenericTrap", packet: v2(".1.3.6.1.6.3.1.1.5.3")},
+		{name: "V2UnassignedNumber", packet: v2(".1.3.6.1.4.1.4294967295.0.1")},
+		{name: "V2NoTrapOID", packet: &gosnmp.SnmpPacket{Version: gosnmp.Version2c, PDUType: gosnmp.SNMPv2Trap}},
+		{name: "V1EnterpriseSpecific", packet: v1(".1.3.6.1.4.1.8072.4", 6), expected: "net-snmp"},
+		{name: "V1GenericTrap", packet: v1("1.3.6.1.4.1.2636.1.1.1.2.29", 2), expected: "Juniper Networks, Inc."},
+		{name: "V1NotAnEnterprise", packet: v1(".1.3.6.1.2.1.11", 0)},
+		{name: "V1Normalized", packet: normalizeV1Trap(v1(".1.3.6.1.4.1.8072.4", 3)), expected: "net-snmp"},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			attributes := pcommon.NewMap()
+			putVendor(attributes, test.packet, pen.Embedded())
+			vendor, ok := attributes.Get(attributeVendor)
+			if test.expected == "" {
+				require.False(t, ok)
+				return
+			}
+			require.True(t, ok)
+			require.Equal(t, test.expected, vendor.Str())
+		})
+	}
+}
+
+func TestLoadEnterpriseNumbers(t *testing.T) {
+	require.Same(t, pen.Embedded(), loadEnterpriseNumbers(&Config{}, zap.NewNop()))
+
+	file := filepath.Join(t.TempDir(), "enterprise-numbers.txt")
+	require.NoError(t, os.WriteFile(file, []byte("Decimal\n| Organization\n|\n9\n  Cisco Systems, Inc.\n    Contact\n      email&example.com\n"), 0o600))
+	registry := loadEnterpriseNumbers(&Config{EnterpriseNumbers: file}, zap.NewNop())
+	vendor, ok := registry.Vendor(".1.3.6.1.4.1.9.1.1")
+	require.True(t, ok)
+	require.Equal(t, "Cisco Systems, Inc.", vendor)
+	_, ok = registry.Vendor(".1.3.6.1.4.1.8072.4")
+	require.False(t, ok)
+
+	// A file which can't be loaded falls back to the embedded registry
+	require.Same(t, pen.Embedded(), loadEnterpriseNumbers(&Config{EnterpriseNumbers: filepath.Join(t.TempDir(), "missing")}, zap.NewNop()))
+}